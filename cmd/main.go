@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -12,10 +13,20 @@ import (
 	"os/signal"
 	"rcoi/config"
 	_ "rcoi/docs"
+	"rcoi/internal/denylist"
 	"rcoi/internal/handlers"
+	"rcoi/internal/jwtkeys"
+	"rcoi/internal/loginguard"
+	"rcoi/internal/mailer"
 	"rcoi/internal/middleware"
+	"rcoi/internal/models"
+	"rcoi/internal/passwordpolicy"
 	"rcoi/internal/repositories"
+	"rcoi/internal/scheduler"
 	"rcoi/internal/services"
+	"rcoi/internal/storage"
+	"rcoi/internal/validation"
+	"rcoi/migrations"
 	"syscall"
 	"time"
 )
@@ -33,74 +44,240 @@ func main() {
 	}
 	defer logger.Sync()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		code := runMigrate(cfg, logger, os.Args[2:])
+		logger.Sync()
+		cfg.Close()
+		os.Exit(code)
+	}
+
+	if cfg.AutoMigrate {
+		if err := applyMigrations(cfg, logger); err != nil {
+			logger.Fatal("Ошибка автоматического применения миграций", zap.Error(err))
+		}
+	}
+
 	logger.Info("Сервис запущен")
 
 	userRepo := repositories.NewUserRepository(cfg.DB)
-	authService := services.NewAuthService(userRepo, logger)
-	authHandler := handlers.NewAuthHandler(authService, logger)
+	roleRepo := repositories.NewRoleRepository(cfg.DB)
+
+	mail, err := mailer.New(cfg.Mail, logger)
+	if err != nil {
+		logger.Fatal("Ошибка инициализации почты", zap.Error(err))
+	}
+
+	signingKeys, err := jwtkeys.Load(cfg.Auth)
+	if err != nil {
+		logger.Fatal("Ошибка загрузки ключей подписи JWT", zap.Error(err))
+	}
+	jwksHandler := handlers.NewJWKSHandler(signingKeys)
+
+	revokedTokens, err := denylist.New(cfg.Auth.DenylistDriver, cfg.DB, cfg.Auth.AccessTokenTTL)
+	if err != nil {
+		logger.Fatal("Ошибка инициализации denylist", zap.Error(err))
+	}
+
+	attemptStore, err := loginguard.NewStore(cfg.Lockout.Driver, cfg.DB)
+	if err != nil {
+		logger.Fatal("Ошибка инициализации счётчиков входа", zap.Error(err))
+	}
+	loginGuard := loginguard.NewGuard(attemptStore, cfg.Lockout, logger)
+	// Письма со ссылками запрашиваются без входа: ограничиваем их, чтобы не засыпать ящики и не исчерпать квоту SMTP
+	mailThrottle := loginguard.NewThrottle(attemptStore, "mail", cfg.Mail.LinkLimitPerEmail, cfg.Mail.LinkLimitPerIP, cfg.Mail.LinkLimitWindow)
+
+	verificationRepo := repositories.NewEmailVerificationRepository(cfg.DB)
+	verificationService := services.NewEmailVerificationService(userRepo, verificationRepo, mail, mailThrottle, cfg.AppBaseURL, logger)
+	verificationHandler := handlers.NewEmailVerificationHandler(verificationService, logger)
+
+	apiKeyRepo := repositories.NewAPIKeyRepository(cfg.DB)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, logger)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService, logger)
+
+	passwordPolicy, err := passwordpolicy.New(cfg.Password)
+	if err != nil {
+		logger.Fatal("Ошибка загрузки политики паролей", zap.Error(err))
+	}
+
+	mfaRepo := repositories.NewMFARepository(cfg.DB)
+	mfaService := services.NewMFAService(mfaRepo, userRepo, cfg.Auth.MFAIssuer, logger)
+	mfaHandler := handlers.NewMFAHandler(mfaService, logger)
+
+	sessionRepo := repositories.NewSessionRepository(cfg.DB)
+	sessionService := services.NewSessionService(sessionRepo, revokedTokens, logger)
+	sessionHandler := handlers.NewSessionHandler(sessionService, logger)
+
+	roleService := services.NewRoleService(roleRepo, sessionRepo, revokedTokens, logger)
+	roleHandler := handlers.NewRoleHandler(roleService, logger)
+
+	authService := services.NewAuthService(userRepo, roleRepo, sessionRepo, revokedTokens, signingKeys, loginGuard, verificationService, mfaService, passwordPolicy, cfg.Auth, logger)
+	authHandler := handlers.NewAuthHandler(authService, cfg.Auth.RefreshTokenTTL, logger)
+	userService := services.NewUserService(userRepo, roleRepo, sessionRepo, revokedTokens, loginGuard, passwordPolicy, logger)
+	userHandler := handlers.NewUserHandler(userService, logger)
+
+	resetRepo := repositories.NewPasswordResetRepository(cfg.DB)
+	resetService := services.NewPasswordResetService(userRepo, sessionRepo, resetRepo, revokedTokens, mail, mailThrottle, passwordPolicy, cfg.AppBaseURL, logger)
+	resetHandler := handlers.NewPasswordResetHandler(resetService, logger)
+
+	var oidcHandler *handlers.OIDCHandler
+	if cfg.OIDC.IssuerURL != "" {
+		identityRepo := repositories.NewIdentityRepository(cfg.DB)
+		ssoService := services.NewSSOService(cfg.OIDC, userRepo, identityRepo, userService, authService, signingKeys, logger)
+		oidcHandler = handlers.NewOIDCHandler(ssoService, cfg.Auth.RefreshTokenTTL, cfg.OIDC.PostLoginRedirect, logger)
+	}
+
+	validator := validation.New(cfg.Validation)
 
 	newsRepo := repositories.NewNewsRepository(cfg.DB)
 	newsService := services.NewNewsService(newsRepo, logger)
-	newsHandler := handlers.NewNewsHandler(newsService, logger)
+	newsHandler := handlers.NewNewsHandler(newsService, validator, logger)
+	publicNewsService := services.NewPublicNewsService(newsRepo)
+	publicNewsHandler := handlers.NewPublicNewsHandler(publicNewsService, cfg.News.PublicCacheMaxAge, logger)
+	feedService := services.NewFeedService(newsRepo, cfg.Feed, cfg.AppBaseURL)
+	feedHandler := handlers.NewFeedHandler(feedService, cfg.Feed.CacheTTL, logger)
+
+	jobs := scheduler.New(logger)
+	defer jobs.Stop()
+	jobs.Every("news_publish", cfg.News.SchedulerInterval, newsService.PublishDue)
+
+	fileStorage, err := storage.New(context.Background(), cfg.Storage)
+	if err != nil {
+		logger.Fatal("Ошибка инициализации файлового хранилища", zap.Error(err))
+	}
+
+	profileService := services.NewProfileService(userRepo, sessionRepo, revokedTokens, loginGuard, passwordPolicy, fileStorage, logger)
+	profileHandler := handlers.NewProfileHandler(profileService, logger)
 
 	docRepo := repositories.NewDocumentRepository(cfg.DB)
-	docService := services.NewDocumentService(docRepo)
-	docHandler := handlers.NewDocumentHandler(docService, logger)
+	docService := services.NewDocumentService(docRepo, fileStorage)
+	docHandler := handlers.NewDocumentHandler(docService, validator, logger)
 
 	appRepo := repositories.NewApplicationRepository(cfg.DB)
-	appService := services.NewApplicationService(appRepo)
-	appHandler := handlers.NewApplicationHandler(appService, logger)
+	appService := services.NewApplicationService(appRepo, fileStorage)
+	appHandler := handlers.NewApplicationHandler(appService, validator, logger)
 
 	r := mux.NewRouter()
+	// Адрес клиента за прокси нужен счётчикам входа по IP и журналу сессий
+	r.Use(middleware.RealIP(cfg.Lockout.TrustedProxies))
 
 	// Открытые маршруты (без middleware)
 	r.HandleFunc("/register", authHandler.Register).Methods("POST")
 	r.HandleFunc("/login", authHandler.Login).Methods("POST")
+	r.HandleFunc("/login/mfa", authHandler.LoginMFA).Methods("POST")
 	r.HandleFunc("/refresh", authHandler.Refresh).Methods("POST")
+	r.HandleFunc("/forgot-password", resetHandler.ForgotPassword).Methods("POST")
+	r.HandleFunc("/reset-password", resetHandler.ResetPassword).Methods("POST")
+	r.HandleFunc("/verify-email", verificationHandler.VerifyEmail).Methods("POST")
+	r.HandleFunc("/verify-email/resend", verificationHandler.ResendVerification).Methods("POST")
+	r.HandleFunc("/.well-known/jwks.json", jwksHandler.GetJWKS).Methods("GET")
+	if oidcHandler != nil {
+		r.HandleFunc("/oidc/login", oidcHandler.Login).Methods("GET")
+		r.HandleFunc("/oidc/callback", oidcHandler.Callback).Methods("GET")
+	}
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
+	// Публичный сайт: только опубликованные новости, без аутентификации
+	public := r.PathPrefix("/public").Subrouter()
+	public.HandleFunc("/news", publicNewsHandler.GetNews).Methods("GET", "HEAD")
+	public.HandleFunc("/news/{slug}", publicNewsHandler.GetNewsBySlug).Methods("GET", "HEAD")
+
+	// Ленты новостей RSS и Atom
+	feeds := r.PathPrefix("/feeds").Subrouter()
+	feeds.HandleFunc("/news.rss", feedHandler.GetNewsRSS).Methods("GET", "HEAD")
+	feeds.HandleFunc("/news.atom", feedHandler.GetNewsAtom).Methods("GET", "HEAD")
+
 	// Защищённые маршруты (JWT middleware)
 	protected := r.PathPrefix("/api").Subrouter()
-	protected.Use(middleware.AuthMiddleware(logger))
+	protected.Use(middleware.AuthMiddleware(signingKeys, revokedTokens, apiKeyService, logger))
 
-	protected.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
-		email := r.Context().Value(middleware.UserEmailKey).(string)
-		w.Write([]byte("Добро пожаловать, " + email + "!"))
-	}).Methods("GET")
+	// can оборачивает обработчик проверкой прав доступа
+	can := func(h http.HandlerFunc, permissions ...string) http.Handler {
+		return middleware.RequirePermission(permissions...)(h)
+	}
+
+	// Профиль текущего пользователя
+	protected.HandleFunc("/profile", profileHandler.GetProfile).Methods("GET")
+	protected.HandleFunc("/profile", profileHandler.UpdateProfile).Methods("PATCH")
+	protected.HandleFunc("/profile/avatar", profileHandler.GetAvatar).Methods("GET")
+	protected.HandleFunc("/profile/avatar", profileHandler.UploadAvatar).Methods("PUT")
+	protected.HandleFunc("/profile/avatar", profileHandler.DeleteAvatar).Methods("DELETE")
+	protected.HandleFunc("/profile/password", profileHandler.ChangePassword).Methods("POST")
 
 	// Новости
-	protected.HandleFunc("/news", newsHandler.CreateNews).Methods("POST")
+	protected.Handle("/news", can(newsHandler.CreateNews, models.PermNewsWrite)).Methods("POST")
 	protected.HandleFunc("/news", newsHandler.GetAllNews).Methods("GET")
 	protected.HandleFunc("/news/{id}", newsHandler.GetNewsByID).Methods("GET")
-	protected.HandleFunc("/news/{id}", newsHandler.UpdateNews).Methods("PUT")
-	protected.HandleFunc("/news/{id}", newsHandler.DeleteNews).Methods("DELETE")
+	protected.Handle("/news/{id}", can(newsHandler.UpdateNews, models.PermNewsWrite)).Methods("PUT")
+	protected.Handle("/news/{id}", can(newsHandler.PatchNews, models.PermNewsWrite)).Methods("PATCH")
+	protected.Handle("/news/{id}", can(newsHandler.DeleteNews, models.PermNewsDelete)).Methods("DELETE")
+	// Право на конкретный статус проверяет обработчик: news:write или news:publish
+	protected.HandleFunc("/news/{id}/status", newsHandler.TransitionNews).Methods("POST")
 
 	// Документы
-	protected.HandleFunc("/documents", docHandler.UploadDocument).Methods("POST")
+	protected.Handle("/documents", can(docHandler.UploadDocument, models.PermDocumentsWrite)).Methods("POST")
 	protected.HandleFunc("/documents", docHandler.GetAllDocuments).Methods("GET")
 	protected.HandleFunc("/documents/{id}", docHandler.DownloadDocument).Methods("GET")
-	protected.HandleFunc("/documents/{id}", docHandler.DeleteDocument).Methods("DELETE")
+	protected.Handle("/documents/{id}", can(docHandler.DeleteDocument, models.PermDocumentsDelete)).Methods("DELETE")
 
 	// Приложения
-	protected.HandleFunc("/applications", appHandler.CreateApplication).Methods("POST")
+	protected.Handle("/applications", can(appHandler.CreateApplication, models.PermApplicationsWrite)).Methods("POST")
 	protected.HandleFunc("/applications", appHandler.GetAllApplications).Methods("GET")
 	protected.HandleFunc("/applications/{id}", appHandler.GetApplicationByID).Methods("GET")
-	protected.HandleFunc("/applications/{id}", appHandler.UpdateApplication).Methods("PUT")
-	protected.HandleFunc("/applications/{id}", appHandler.DeleteApplication).Methods("DELETE")
+	protected.Handle("/applications/{id}", can(appHandler.UpdateApplication, models.PermApplicationsWrite)).Methods("PUT")
+	protected.Handle("/applications/{id}", can(appHandler.PatchApplication, models.PermApplicationsWrite)).Methods("PATCH")
+	protected.Handle("/applications/{id}", can(appHandler.DeleteApplication, models.PermApplicationsDelete)).Methods("DELETE")
 
 	// Маршруты для администраторов
 	adminRoute := protected.PathPrefix("/admin").Subrouter()
-	adminRoute.Use(middleware.RoleMiddleware("admin"))
+	adminRoute.Use(middleware.RequirePermission(models.PermUsersManage))
 	adminRoute.HandleFunc("/dashboard", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Добро пожаловать в админ-панель!"))
 	}).Methods("GET")
 
+	// Управление пользователями
+	adminRoute.HandleFunc("/users", userHandler.GetAllUsers).Methods("GET")
+	adminRoute.HandleFunc("/users", userHandler.CreateUser).Methods("POST")
+	adminRoute.HandleFunc("/users/{id}", userHandler.GetUserByID).Methods("GET")
+	adminRoute.HandleFunc("/users/{id}", userHandler.DeleteUser).Methods("DELETE")
+	adminRoute.HandleFunc("/users/{id}/role", userHandler.ChangeRole).Methods("PUT")
+	adminRoute.HandleFunc("/users/{id}/disable", userHandler.DisableUser).Methods("POST")
+	adminRoute.HandleFunc("/users/{id}/enable", userHandler.EnableUser).Methods("POST")
+	adminRoute.HandleFunc("/users/{id}/logout", userHandler.ForceLogout).Methods("POST")
+	adminRoute.HandleFunc("/users/{id}/unlock", userHandler.UnlockUser).Methods("POST")
+	adminRoute.HandleFunc("/users/{id}/mfa/reset", mfaHandler.ResetUserMFA).Methods("POST")
+	adminRoute.HandleFunc("/users/{id}/sessions", sessionHandler.GetUserSessions).Methods("GET")
+	adminRoute.HandleFunc("/sessions/{id}", sessionHandler.RevokeSession).Methods("DELETE")
+
+	// Роли и права
+	adminRoute.Handle("/roles", can(roleHandler.GetAllRoles, models.PermRolesManage)).Methods("GET")
+	adminRoute.Handle("/roles", can(roleHandler.CreateRole, models.PermRolesManage)).Methods("POST")
+	adminRoute.Handle("/roles/{name}", can(roleHandler.DeleteRole, models.PermRolesManage)).Methods("DELETE")
+	adminRoute.Handle("/roles/{name}/permissions", can(roleHandler.SetPermissions, models.PermRolesManage)).Methods("PUT")
+	adminRoute.Handle("/permissions", can(roleHandler.GetAllPermissions, models.PermRolesManage)).Methods("GET")
+
+	// API-ключи для машинных клиентов
+	adminRoute.Handle("/api-keys", can(apiKeyHandler.GetAllAPIKeys, models.PermAPIKeysManage)).Methods("GET")
+	adminRoute.Handle("/api-keys", can(apiKeyHandler.CreateAPIKey, models.PermAPIKeysManage)).Methods("POST")
+	adminRoute.Handle("/api-keys/{id}", can(apiKeyHandler.RevokeAPIKey, models.PermAPIKeysManage)).Methods("DELETE")
+
 	protected.HandleFunc("/logout", authHandler.Logout).Methods("POST")
 
+	// Двухфакторная аутентификация текущего пользователя
+	protected.HandleFunc("/mfa", mfaHandler.GetStatus).Methods("GET")
+	protected.HandleFunc("/mfa/totp", mfaHandler.EnrollTOTP).Methods("POST")
+	protected.HandleFunc("/mfa/totp/confirm", mfaHandler.ConfirmTOTP).Methods("POST")
+	protected.HandleFunc("/mfa/totp/disable", mfaHandler.DisableTOTP).Methods("POST")
+	protected.HandleFunc("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes).Methods("POST")
+
+	// Сессии текущего пользователя
+	protected.HandleFunc("/sessions", sessionHandler.GetMySessions).Methods("GET")
+	protected.HandleFunc("/sessions/{id}", sessionHandler.RevokeMySession).Methods("DELETE")
+
 	handler := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:8081"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "If-Match", middleware.APIKeyHeader},
+		ExposedHeaders:   []string{"ETag"},
 		AllowCredentials: true,
 	}).Handler(r)
 
@@ -122,3 +299,35 @@ func main() {
 	server.Shutdown(ctx)
 	log.Println("Сервер остановлен")
 }
+
+// runMigrate обрабатывает подкоманду `rcoi migrate up|down|status|redo`
+func runMigrate(cfg *config.Config, logger *zap.Logger, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Использование: rcoi migrate up|down|status|redo")
+		return 2
+	}
+
+	runner, err := migrations.NewRunner(cfg.DB, logger)
+	if err != nil {
+		logger.Error("Ошибка инициализации миграций", zap.Error(err))
+		return 1
+	}
+	defer runner.Close()
+
+	if err := runner.Run(context.Background(), args[0], os.Stdout); err != nil {
+		logger.Error("Ошибка выполнения миграций", zap.String("command", args[0]), zap.Error(err))
+		return 1
+	}
+	return 0
+}
+
+// applyMigrations применяет все новые миграции при старте сервиса
+func applyMigrations(cfg *config.Config, logger *zap.Logger) error {
+	runner, err := migrations.NewRunner(cfg.DB, logger)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+
+	return runner.Up(context.Background())
+}
@@ -4,15 +4,174 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DB *pgxpool.Pool
+	DB          *pgxpool.Pool
+	Storage     StorageConfig
+	Mail        MailConfig
+	Auth        AuthConfig
+	Lockout     LockoutConfig
+	OIDC        OIDCConfig
+	Password    PasswordConfig
+	Validation  ValidationConfig
+	News        NewsConfig
+	Feed        FeedConfig
+	AutoMigrate bool
+	// AppBaseURL — адрес фронтенда, используется в ссылках из писем
+	AppBaseURL string
+}
+
+// StorageConfig описывает хранилище загружаемых файлов
+type StorageConfig struct {
+	Driver    string // local или s3
+	LocalPath string
+
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UseSSL       bool
+	S3CreateBucket bool
+}
+
+// AuthConfig описывает политику аутентификации
+type AuthConfig struct {
+	// UnverifiedLogin — вход с неподтверждённым email: allow, restrict (без прав на запись) или deny
+	UnverifiedLogin string
+	// DenylistDriver — хранилище отозванных access-токенов: postgres или memory (только для одной реплики)
+	DenylistDriver string
+	// JWTSecret — HS256-секрет; нужен, пока не настроены асимметричные ключи или не истекли подписанные им токены
+	JWTSecret string
+	// JWTKeysDir — каталог ключей подписи (<kid>.pem, <kid>.pub.pem), JWTActiveKeyID — kid для подписи новых токенов
+	JWTKeysDir     string
+	JWTActiveKeyID string
+	// TokenIssuer и TokenAudience проверяются в iss и aud; refresh-токены адресованы самому издателю
+	TokenIssuer     string
+	TokenAudience   string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// MFARequiredRoles — роли, которым права выдаются только после подключения TOTP (например, admin)
+	MFARequiredRoles []string
+	// MFAIssuer — название сервиса в приложении-аутентификаторе
+	MFAIssuer string
+}
+
+// LockoutConfig описывает защиту входа от перебора паролей
+type LockoutConfig struct {
+	Driver string // postgres или memory (только для одной реплики)
+	// MaxAccountFailures и MaxIPFailures — число неудачных попыток до временной блокировки;
+	// 0 отключает счётчик (например, счётчик по IP за прокси, не передающим адрес клиента)
+	MaxAccountFailures int
+	MaxIPFailures      int
+	// FailureWindow — через сколько после последней неудачи счётчик начинается заново
+	FailureWindow   time.Duration
+	LockoutDuration time.Duration
+	// TrustedProxies — подсети обратных прокси и балансировщиков (TRUSTED_PROXIES, CIDR через запятую).
+	// Только для запросов от них адрес клиента берётся из X-Forwarded-For или X-Real-IP;
+	// без этого за прокси все клиенты делят его адрес и один счётчик MaxIPFailures.
+	// Пусто — заголовки игнорируются, адрес клиента — адрес TCP-соединения.
+	TrustedProxies []*net.IPNet
+}
+
+// PasswordConfig описывает политику паролей для регистрации, сброса и смены пароля
+type PasswordConfig struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// AllowedSymbols — допустимые специальные символы (пробел тоже символ); пусто — любые
+	AllowedSymbols string
+	// AllowUnicode разрешает буквы и символы за пределами ASCII (например, кириллицу в парольных фразах)
+	AllowUnicode bool
+	// BreachedDir — каталог со списком утёкших паролей в формате диапазонов SHA-1; пусто — проверка отключена
+	BreachedDir string
+	// HistorySize — сколько последних паролей, включая текущий, нельзя использовать повторно
+	HistorySize int
+}
+
+// ValidationConfig описывает проверку данных, присланных клиентом
+type ValidationConfig struct {
+	// URLSchemes — допустимые схемы ссылок (например, в приложениях)
+	URLSchemes []string
+	// URLHosts — допустимые хосты ссылок, включая их поддомены; пусто — любые
+	URLHosts []string
+}
+
+// NewsConfig описывает публикацию новостей
+type NewsConfig struct {
+	// SchedulerInterval — как часто проверяются новости, которые пора опубликовать или снять с публикации
+	SchedulerInterval time.Duration
+	// PublicCacheMaxAge — сколько браузеры и CDN могут хранить ответы /public/news без перепроверки
+	PublicCacheMaxAge time.Duration
+}
+
+// FeedConfig описывает ленты новостей RSS и Atom
+type FeedConfig struct {
+	Title       string
+	Description string
+	// Limit — сколько последних новостей попадает в ленту
+	Limit int
+	// CacheTTL — сколько готовая лента хранится в памяти и в кеше читателей (Cache-Control)
+	CacheTTL time.Duration
+}
+
+// OIDCConfig описывает вход через внешний OpenID Connect провайдер; пустой IssuerURL отключает SSO
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL — адрес /oidc/callback этого сервиса, зарегистрированный у провайдера
+	RedirectURL string
+	Scopes      []string
+	// GroupsClaim — claim ID-токена со списком групп пользователя
+	GroupsClaim string
+	// RoleMapping — группы провайдера и соответствующие роли в порядке приоритета.
+	// Если задано, роль пользователя обновляется при каждом входе через SSO.
+	RoleMapping []GroupRole
+	// DefaultRole — роль пользователя, не входящего ни в одну из групп RoleMapping
+	DefaultRole string
+	// AutoProvision разрешает создавать учётные записи для новых пользователей провайдера
+	AutoProvision bool
+	// PostLoginRedirect — страница фронтенда, куда браузер возвращается после входа;
+	// если не задана, /oidc/callback отвечает JSON, как /login
+	PostLoginRedirect string
+}
+
+// GroupRole — соответствие группы провайдера роли rcoi
+type GroupRole struct {
+	Group string
+	Role  string
+}
+
+// MailConfig описывает отправку писем
+type MailConfig struct {
+	Driver  string // smtp, file или log
+	From    string
+	FileDir string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+
+	// LinkLimitPerEmail и LinkLimitPerIP — сколько писем со ссылками (сброс пароля, повторное подтверждение)
+	// можно запросить на один адрес и с одного IP, пока между запросами проходит меньше LinkLimitWindow;
+	// 0 отключает ограничение. Сверх лимита письмо не отправляется, а ответ остаётся тем же.
+	LinkLimitPerEmail int
+	LinkLimitPerIP    int
+	LinkLimitWindow   time.Duration
 }
 
 var (
@@ -39,7 +198,93 @@ func LoadConfig() (*Config, error) {
 			return
 		}
 
-		configInstance = &Config{DB: dbPool}
+		configInstance = &Config{
+			DB:          dbPool,
+			AutoMigrate: getEnvBool("AUTO_MIGRATE", false),
+			AppBaseURL:  getEnv("APP_BASE_URL", "http://localhost:8081"),
+			Storage: StorageConfig{
+				Driver:         getEnv("STORAGE_DRIVER", "local"),
+				LocalPath:      getEnv("STORAGE_LOCAL_PATH", "uploads"),
+				S3Endpoint:     os.Getenv("S3_ENDPOINT"),
+				S3Region:       os.Getenv("S3_REGION"),
+				S3Bucket:       os.Getenv("S3_BUCKET"),
+				S3AccessKey:    os.Getenv("S3_ACCESS_KEY"),
+				S3SecretKey:    os.Getenv("S3_SECRET_KEY"),
+				S3UseSSL:       getEnvBool("S3_USE_SSL", true),
+				S3CreateBucket: getEnvBool("S3_CREATE_BUCKET", false),
+			},
+			Auth: AuthConfig{
+				UnverifiedLogin:  getEnv("AUTH_UNVERIFIED_LOGIN", "restrict"),
+				DenylistDriver:   getEnv("AUTH_DENYLIST_DRIVER", "postgres"),
+				JWTSecret:        os.Getenv("JWT_SECRET"),
+				JWTKeysDir:       os.Getenv("JWT_KEYS_DIR"),
+				JWTActiveKeyID:   os.Getenv("JWT_ACTIVE_KID"),
+				TokenIssuer:      getEnv("JWT_ISSUER", "rcoi"),
+				TokenAudience:    getEnv("JWT_AUDIENCE", "rcoi-api"),
+				AccessTokenTTL:   getEnvDuration("JWT_ACCESS_TTL", time.Hour),
+				RefreshTokenTTL:  getEnvDuration("JWT_REFRESH_TTL", 7*24*time.Hour),
+				MFARequiredRoles: getEnvList("AUTH_MFA_REQUIRED_ROLES"),
+				MFAIssuer:        getEnv("AUTH_MFA_ISSUER", "rcoi"),
+			},
+			Password: PasswordConfig{
+				MinLength:      getEnvInt("PASSWORD_MIN_LENGTH", 8),
+				RequireUpper:   getEnvBool("PASSWORD_REQUIRE_UPPER", true),
+				RequireLower:   getEnvBool("PASSWORD_REQUIRE_LOWER", true),
+				RequireDigit:   getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+				RequireSymbol:  getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+				AllowedSymbols: os.Getenv("PASSWORD_ALLOWED_SYMBOLS"),
+				AllowUnicode:   getEnvBool("PASSWORD_ALLOW_UNICODE", true),
+				BreachedDir:    os.Getenv("PASSWORD_BREACHED_DIR"),
+				HistorySize:    getEnvInt("PASSWORD_HISTORY_SIZE", 5),
+			},
+			Validation: ValidationConfig{
+				URLSchemes: getEnvListDefault("VALIDATION_URL_SCHEMES", []string{"https", "http"}),
+				URLHosts:   getEnvList("VALIDATION_URL_HOSTS"),
+			},
+			News: NewsConfig{
+				SchedulerInterval: getEnvDuration("NEWS_SCHEDULER_INTERVAL", time.Minute),
+				PublicCacheMaxAge: getEnvDuration("NEWS_PUBLIC_CACHE_MAX_AGE", time.Minute),
+			},
+			Feed: FeedConfig{
+				Title:       getEnv("FEED_TITLE", "Новости РЦОИ"),
+				Description: getEnv("FEED_DESCRIPTION", "Новости регионального центра обработки информации"),
+				Limit:       getEnvInt("FEED_LIMIT", 20),
+				CacheTTL:    getEnvDuration("FEED_CACHE_TTL", 5*time.Minute),
+			},
+			OIDC: OIDCConfig{
+				IssuerURL:         os.Getenv("OIDC_ISSUER_URL"),
+				ClientID:          os.Getenv("OIDC_CLIENT_ID"),
+				ClientSecret:      os.Getenv("OIDC_CLIENT_SECRET"),
+				RedirectURL:       os.Getenv("OIDC_REDIRECT_URL"),
+				Scopes:            getEnvList("OIDC_SCOPES"),
+				GroupsClaim:       getEnv("OIDC_GROUPS_CLAIM", "groups"),
+				RoleMapping:       getEnvRoleMapping("OIDC_ROLE_MAPPING"),
+				DefaultRole:       getEnv("OIDC_DEFAULT_ROLE", "user"),
+				AutoProvision:     getEnvBool("OIDC_AUTO_PROVISION", true),
+				PostLoginRedirect: os.Getenv("OIDC_POST_LOGIN_REDIRECT"),
+			},
+			Lockout: LockoutConfig{
+				Driver:             getEnv("LOCKOUT_DRIVER", "postgres"),
+				MaxAccountFailures: getEnvInt("LOCKOUT_MAX_ACCOUNT_FAILURES", 10),
+				MaxIPFailures:      getEnvInt("LOCKOUT_MAX_IP_FAILURES", 50),
+				FailureWindow:      getEnvDuration("LOCKOUT_FAILURE_WINDOW", 15*time.Minute),
+				LockoutDuration:    getEnvDuration("LOCKOUT_DURATION", 15*time.Minute),
+				TrustedProxies:     getEnvCIDRs("TRUSTED_PROXIES"),
+			},
+			Mail: MailConfig{
+				Driver:       getEnv("MAIL_DRIVER", "log"),
+				From:         getEnv("MAIL_FROM", "noreply@localhost"),
+				FileDir:      getEnv("MAIL_FILE_DIR", "mail"),
+				SMTPHost:     os.Getenv("SMTP_HOST"),
+				SMTPPort:     getEnvInt("SMTP_PORT", 587),
+				SMTPUser:     os.Getenv("SMTP_USER"),
+				SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+
+				LinkLimitPerEmail: getEnvInt("MAIL_LINK_LIMIT_PER_EMAIL", 3),
+				LinkLimitPerIP:    getEnvInt("MAIL_LINK_LIMIT_PER_IP", 20),
+				LinkLimitWindow:   getEnvDuration("MAIL_LINK_LIMIT_WINDOW", time.Hour),
+			},
+		}
 	})
 
 	return configInstance, err
@@ -50,3 +295,89 @@ func (c *Config) Close() {
 		c.DB.Close()
 	}
 }
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvList разбирает список значений через запятую
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvListDefault разбирает список значений через запятую; пустой список заменяется fallback
+func getEnvListDefault(key string, fallback []string) []string {
+	if values := getEnvList(key); len(values) > 0 {
+		return values
+	}
+	return fallback
+}
+
+// getEnvRoleMapping разбирает пары "группа=роль" через запятую
+func getEnvRoleMapping(key string) []GroupRole {
+	var mapping []GroupRole
+	for _, pair := range getEnvList(key) {
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || role == "" {
+			log.Printf("⚠️ Внимание: пропущено некорректное соответствие %q в %s", pair, key)
+			continue
+		}
+		mapping = append(mapping, GroupRole{Group: group, Role: role})
+	}
+	return mapping
+}
+
+// getEnvCIDRs разбирает подсети через запятую; одиночный адрес считается подсетью из одного адреса
+func getEnvCIDRs(key string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, value := range getEnvList(key) {
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil && ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			log.Printf("⚠️ Внимание: пропущена некорректная подсеть %q в %s", value, key)
+			continue
+		}
+		nets = append(nets, network)
+	}
+	return nets
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -15,123 +15,56 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/applications": {
+        "/.well-known/jwks.json": {
             "get": {
-                "description": "Возвращает список всех загруженных приложений",
+                "description": "Возвращает JWKS для проверки токенов rcoi другими сервисами",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "applications"
+                    "auth"
                 ],
-                "summary": "Получение списка всех приложений",
+                "summary": "Открытые ключи подписи JWT",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Application"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Ошибка получения приложений"
-                    }
-                }
-            },
-            "post": {
-                "description": "Загружает новое приложение с файлом или URL",
-                "consumes": [
-                    "multipart/form-data"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "applications"
-                ],
-                "summary": "Создание нового приложения",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Название приложения",
-                        "name": "title",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Описание приложения",
-                        "name": "description",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "URL приложения",
-                        "name": "url",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "file",
-                        "description": "Файл приложения",
-                        "name": "file",
-                        "in": "formData"
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/models.Application"
+                            "$ref": "#/definitions/jwtkeys.JWKS"
                         }
-                    },
-                    "400": {
-                        "description": "Файл не найден"
-                    },
-                    "500": {
-                        "description": "Ошибка создания приложения"
                     }
                 }
             }
         },
-        "/api/applications/{id}": {
+        "/api/admin/api-keys": {
             "get": {
-                "description": "Возвращает приложение по указанному ID",
+                "description": "Возвращает все ключи, включая отозванные и истёкшие; сами ключи не возвращаются",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "applications"
-                ],
-                "summary": "Получение приложения по ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID приложения",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "Список API-ключей",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Application"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.APIKey"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Некорректный ID приложения"
-                    },
-                    "404": {
-                        "description": "Приложение не найдено"
+                    "500": {
+                        "description": "Ошибка получения API-ключей",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     }
                 }
             },
-            "put": {
-                "description": "Обновляет данные приложения по указанному ID",
+            "post": {
+                "description": "Ключ передаётся в заголовке X-API-Key и даёт только перечисленные права.\nПраво api_keys:manage ключу выдать нельзя. Ключ действует, пока выпустивший\nего администратор активен, и только в пределах его текущих прав.\nЗначение ключа возвращается один раз — сохраните его сразу",
                 "consumes": [
                     "application/json"
                 ],
@@ -139,52 +72,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "applications"
+                    "admin"
                 ],
-                "summary": "Обновление данных приложения",
+                "summary": "Выпуск API-ключа",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID приложения",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Обновляемые данные приложения",
-                        "name": "application",
+                        "description": "Название, права и срок действия (RFC 3339, необязательно)",
+                        "name": "key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Application"
+                            "$ref": "#/definitions/services.NewAPIKey"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Application"
+                            "type": "object",
+                            "properties": {
+                                "api_key": {
+                                    "$ref": "#/definitions/models.APIKey"
+                                },
+                                "key": {
+                                    "type": "string"
+                                }
+                            }
                         }
                     },
                     "400": {
-                        "description": "Некорректный ID или неверный формат запроса"
+                        "description": "Неверный формат запроса, неизвестное или недопустимое для ключа право",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     },
-                    "500": {
-                        "description": "Ошибка обновления приложения"
+                    "403": {
+                        "description": "Нельзя выдать ключу право, которого нет у вас",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/api/admin/api-keys/{id}": {
             "delete": {
-                "description": "Удаляет приложение по указанному ID",
                 "tags": [
-                    "applications"
+                    "admin"
                 ],
-                "summary": "Удаление приложения",
+                "summary": "Отзыв API-ключа",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID приложения",
+                        "description": "ID ключа",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -192,208 +133,346 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "204": {
-                        "description": "Приложение удалено"
-                    },
-                    "400": {
-                        "description": "Некорректный ID приложения"
+                        "description": "Ключ отозван"
                     },
                     "404": {
-                        "description": "Приложение не найдено"
+                        "description": "API-ключ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/permissions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Список прав доступа",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Permission"
+                            }
+                        }
                     },
                     "500": {
-                        "description": "Ошибка удаления приложения"
+                        "description": "Ошибка получения прав",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     }
                 }
             }
         },
-        "/api/documents": {
+        "/api/admin/roles": {
             "get": {
-                "description": "Возвращает список всех загруженных документов",
+                "description": "Возвращает роли вместе с их правами",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "documents"
+                    "admin"
                 ],
-                "summary": "Получение списка всех документов",
+                "summary": "Список ролей",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "object"
+                                "$ref": "#/definitions/models.Role"
                             }
                         }
                     },
                     "500": {
-                        "description": "Ошибка получения документов"
+                        "description": "Ошибка получения ролей",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     }
                 }
             },
             "post": {
-                "description": "Загрузка документа на сервер",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "documents"
+                    "admin"
                 ],
-                "summary": "Загрузка документа",
+                "summary": "Создание роли",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Название документа",
-                        "name": "title",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Файл документа",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "description": "Роль",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "description": {
+                                    "type": "string"
+                                },
+                                "name": {
+                                    "type": "string"
+                                },
+                                "permissions": {
+                                    "type": "array",
+                                    "items": {
+                                        "type": "string"
+                                    }
+                                }
+                            }
+                        }
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "type": "object"
+                            "$ref": "#/definitions/models.Role"
                         }
                     },
                     "400": {
-                        "description": "Файл не найден"
+                        "description": "Неверный формат запроса или неизвестное право",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     },
-                    "500": {
-                        "description": "Ошибка загрузки файла"
+                    "409": {
+                        "description": "Роль уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     }
                 }
             }
         },
-        "/api/documents/{id}": {
-            "get": {
-                "description": "Скачивание документа по его ID",
+        "/api/admin/roles/{name}": {
+            "delete": {
                 "tags": [
-                    "documents"
+                    "admin"
                 ],
-                "summary": "Скачивание документа по ID",
+                "summary": "Удаление роли",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID документа",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Название роли",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Файл для скачивания"
+                    "204": {
+                        "description": "Роль удалена"
                     },
-                    "400": {
-                        "description": "Некорректный ID документа"
+                    "403": {
+                        "description": "Встроенную роль нельзя удалить",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     },
                     "404": {
-                        "description": "Документ не найден"
+                        "description": "Роль не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Роль назначена пользователям",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     }
                 }
-            },
-            "delete": {
-                "description": "Удаляет документ по указанному ID",
+            }
+        },
+        "/api/admin/roles/{name}/permissions": {
+            "put": {
+                "description": "Полностью заменяет набор прав роли. Access-токены пользователей с этой ролью\nотзываются: клиенты получают токены с новыми правами через /refresh.\nСнять roles:manage и users:manage с роли admin или со своей роли нельзя.",
+                "consumes": [
+                    "application/json"
+                ],
                 "tags": [
-                    "documents"
+                    "admin"
                 ],
-                "summary": "Удаление документа по ID",
+                "summary": "Изменение прав роли",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID документа",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Название роли",
+                        "name": "name",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Права роли",
+                        "name": "permissions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "permissions": {
+                                    "type": "array",
+                                    "items": {
+                                        "type": "string"
+                                    }
+                                }
+                            }
+                        }
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "Документ удален"
+                        "description": "Права изменены"
                     },
                     "400": {
-                        "description": "Некорректный ID документа"
+                        "description": "Неизвестное право",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     },
-                    "500": {
-                        "description": "Ошибка удаления документа"
+                    "404": {
+                        "description": "Роль не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Изменение лишило бы администраторов доступа",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     }
                 }
             }
         },
-        "/api/logout": {
-            "post": {
-                "description": "Выход пользователя и удаление refresh-токена",
+        "/api/admin/sessions/{id}": {
+            "delete": {
+                "description": "Отзывает сессию по ID независимо от владельца",
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Выход пользователя",
-                "responses": {
-                    "200": {
-                        "description": "Успешный выход"
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "properties": {
-                                "error": {
-                                    "type": "string"
-                                }
-                            }
-                        }
+                "summary": "Завершение любой сессии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Сессия завершена"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Сессия не найдена",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "error": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/news": {
+        "/api/admin/users": {
             "get": {
-                "description": "Возвращает список всех новостей",
+                "description": "Возвращает список пользователей с пагинацией",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "news"
+                    "admin"
+                ],
+                "summary": "Список пользователей",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 20, максимум 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (не используется вместе с cursor)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поле сортировки: id, email, created_at; префикс '-' — по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подстрока email",
+                        "name": "email",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Получение списка всех новостей",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.News"
+                            "type": "object",
+                            "properties": {
+                                "items": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.User"
+                                    }
+                                },
+                                "limit": {
+                                    "type": "integer"
+                                },
+                                "next_cursor": {
+                                    "type": "string"
+                                },
+                                "offset": {
+                                    "type": "integer"
+                                },
+                                "total": {
+                                    "type": "integer"
+                                }
                             }
                         }
                     },
+                    "400": {
+                        "description": "Некорректные параметры списка",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
                     "500": {
-                        "description": "Ошибка получения новостей"
+                        "description": "Ошибка получения пользователей",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     }
                 }
             },
             "post": {
-                "description": "Создаёт новую новость",
+                "description": "Создаёт пользователя с указанной ролью",
                 "consumes": [
                     "application/json"
                 ],
@@ -401,17 +480,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "news"
+                    "admin"
                 ],
-                "summary": "Создание новости",
+                "summary": "Создание пользователя",
                 "parameters": [
                     {
-                        "description": "Данные новости",
-                        "name": "news",
+                        "description": "Данные пользователя",
+                        "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.News"
+                            "type": "object",
+                            "properties": {
+                                "email": {
+                                    "type": "string"
+                                },
+                                "password": {
+                                    "type": "string"
+                                },
+                                "role": {
+                                    "type": "string"
+                                }
+                            }
                         }
                     }
                 ],
@@ -419,32 +509,37 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.News"
+                            "$ref": "#/definitions/models.User"
                         }
                     },
                     "400": {
-                        "description": "Неверный формат запроса"
+                        "description": "Неверный формат запроса или некорректный email",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     },
-                    "500": {
-                        "description": "Ошибка создания новости"
+                    "409": {
+                        "description": "Email уже используется",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     }
                 }
             }
         },
-        "/api/news/{id}": {
+        "/api/admin/users/{id}": {
             "get": {
-                "description": "Возвращает новость по указанному ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "news"
+                    "admin"
                 ],
-                "summary": "Получение новости по ID",
+                "summary": "Получение пользователя по ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID новости",
+                        "description": "ID пользователя",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -454,72 +549,118 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.News"
+                            "$ref": "#/definitions/models.User"
                         }
                     },
                     "400": {
-                        "description": "Некорректный ID"
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     },
                     "404": {
-                        "description": "Новость не найдена"
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     }
                 }
             },
-            "put": {
-                "description": "Обновляет новость по указанному ID",
-                "consumes": [
-                    "application/json"
+            "delete": {
+                "tags": [
+                    "admin"
                 ],
-                "produces": [
-                    "application/json"
+                "summary": "Удаление пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
+                "responses": {
+                    "204": {
+                        "description": "Пользователь удалён"
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}/disable": {
+            "post": {
+                "description": "Отключает учётную запись и завершает её сессии",
                 "tags": [
-                    "news"
+                    "admin"
                 ],
-                "summary": "Обновление новости по ID",
+                "summary": "Отключение учётной записи",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID новости",
+                        "description": "ID пользователя",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Учётная запись отключена"
                     },
-                    {
-                        "description": "Обновляемые данные новости",
-                        "name": "news",
-                        "in": "body",
-                        "required": true,
+                    "404": {
+                        "description": "Пользователь не найден",
                         "schema": {
-                            "$ref": "#/definitions/models.News"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
+                }
+            }
+        },
+        "/api/admin/users/{id}/enable": {
+            "post": {
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Включение учётной записи",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "Учётная запись включена"
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
                         "schema": {
-                            "$ref": "#/definitions/models.News"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
-                    },
-                    "400": {
-                        "description": "Некорректный ID или неверный формат запроса"
-                    },
-                    "500": {
-                        "description": "Ошибка обновления новости"
                     }
                 }
-            },
-            "delete": {
-                "description": "Удаляет новость по указанному ID",
+            }
+        },
+        "/api/admin/users/{id}/logout": {
+            "post": {
+                "description": "Завершает все сессии пользователя",
                 "tags": [
-                    "news"
+                    "admin"
                 ],
-                "summary": "Удаление новости по ID",
+                "summary": "Принудительный выход пользователя",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID новости",
+                        "description": "ID пользователя",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -527,43 +668,72 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "204": {
-                        "description": "Новость удалена"
+                        "description": "Сессии завершены"
                     },
-                    "400": {
-                        "description": "Некорректный ID"
-                    },
-                    "500": {
-                        "description": "Ошибка удаления новости"
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     }
                 }
             }
         },
-        "/login": {
+        "/api/admin/users/{id}/mfa/reset": {
             "post": {
-                "description": "Авторизация пользователя по email и паролю",
-                "consumes": [
-                    "application/json"
+                "description": "Отключает TOTP и коды восстановления пользователя, потерявшего устройство",
+                "tags": [
+                    "admin"
                 ],
-                "produces": [
+                "summary": "Сброс двухфакторной аутентификации пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Двухфакторная аутентификация сброшена"
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}/role": {
+            "put": {
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Авторизация пользователя",
+                "summary": "Изменение роли пользователя",
                 "parameters": [
                     {
-                        "description": "Данные пользователя",
-                        "name": "user",
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новая роль",
+                        "name": "role",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "type": "object",
                             "properties": {
-                                "email": {
-                                    "type": "string"
-                                },
-                                "password": {
+                                "role": {
                                     "type": "string"
                                 }
                             }
@@ -571,162 +741,2755 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "object",
-                            "properties": {
-                                "access_token": {
-                                    "type": "string"
-                                }
-                            }
-                        }
+                    "204": {
+                        "description": "Роль изменена"
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Неизвестная роль",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "error": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Пользователь не найден",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "error": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
                 }
             }
         },
-        "/refresh": {
-            "post": {
-                "description": "Обновляет access-токен с помощью refresh-токена",
+        "/api/admin/users/{id}/sessions": {
+            "get": {
+                "description": "Возвращает активные сессии указанного пользователя",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
+                ],
+                "summary": "Сессии пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Обновление access-токена",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "access_token": {
-                                    "type": "string"
-                                }
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Session"
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Некорректный ID",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "error": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка получения сессий",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
                 }
             }
         },
-        "/register": {
+        "/api/admin/users/{id}/unlock": {
             "post": {
-                "description": "Регистрация нового пользователя с email и паролем",
-                "consumes": [
-                    "application/json"
+                "description": "Сбрасывает счётчик неудачных попыток входа и временную блокировку учётной записи",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Снятие блокировки входа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
+                "responses": {
+                    "204": {
+                        "description": "Блокировка снята"
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/applications": {
+            "get": {
+                "description": "Возвращает список всех загруженных приложений",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "applications"
                 ],
-                "summary": "Регистрация пользователя",
+                "summary": "Получение списка всех приложений",
                 "parameters": [
                     {
-                        "description": "Данные пользователя",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 20, максимум 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (не используется вместе с cursor)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поле сортировки, префикс '-' — по убыванию (по умолчанию -created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата создания с (RFC 3339 или YYYY-MM-DD)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата создания по (RFC 3339 или YYYY-MM-DD)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подстрока названия",
+                        "name": "title",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "properties": {
-                                "email": {
-                                    "type": "string"
+                                "items": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.Application"
+                                    }
                                 },
-                                "password": {
+                                "limit": {
+                                    "type": "integer"
+                                },
+                                "next_cursor": {
                                     "type": "string"
+                                },
+                                "offset": {
+                                    "type": "integer"
+                                },
+                                "total": {
+                                    "type": "integer"
                                 }
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры списка",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка получения приложений",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Загружает новое приложение с файлом или URL",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Создание нового приложения",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название приложения",
+                        "name": "title",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Описание приложения",
+                        "name": "description",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL приложения",
+                        "name": "url",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл приложения",
+                        "name": "file",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "message": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/models.Application"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Данные не прошли проверку или не передан ни файл, ни URL",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "error": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "500": {
+                        "description": "Ошибка создания приложения",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "error": {
-                                    "type": "string"
-                                }
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/applications/{id}": {
+            "get": {
+                "description": "Возвращает приложение по указанному ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Получение приложения по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID приложения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Application"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия приложения для If-Match"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Некорректный ID приложения",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Приложение или его файл не найден",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Ошибка чтения файла",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "error": {
-                                    "type": "string"
-                                }
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет название, описание и URL приложения; требует If-Match с ETag текущей версии.\nПустой url допустим, только если у приложения загружен файл. Указанный url заменяет файл: он удаляется из хранилища",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Обновление данных приложения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID приложения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии приложения",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Обновляемые данные приложения",
+                        "name": "application",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ApplicationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Application"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия приложения"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Некорректный ID, неверный формат запроса или данные не прошли проверку",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Приложение не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Запись изменена другим пользователем",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка обновления приложения",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "models.Application": {
-            "type": "object",
+            },
+            "delete": {
+                "description": "Удаляет приложение по указанному ID; требует If-Match с ETag текущей версии",
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Удаление приложения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID приложения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии приложения",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Приложение удалено"
+                    },
+                    "400": {
+                        "description": "Некорректный ID приложения",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Приложение не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Запись изменена другим пользователем",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка удаления приложения",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Меняет только переданные поля (JSON Merge Patch, RFC 7386); требует If-Match с ETag текущей версии.\nПустой url допустим, только если у приложения загружен файл. Указанный url заменяет файл: он удаляется из хранилища",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Частичное обновление приложения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID приложения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии приложения",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля приложения",
+                        "name": "application",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ApplicationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Application"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия приложения"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID, неверный формат запроса или данные не прошли проверку",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Приложение не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Запись изменена другим пользователем",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка обновления приложения",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/documents": {
+            "get": {
+                "description": "Возвращает список всех загруженных документов",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "documents"
+                ],
+                "summary": "Получение списка всех документов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 20, максимум 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (не используется вместе с cursor)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поле сортировки, префикс '-' — по убыванию (по умолчанию -created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата создания с (RFC 3339 или YYYY-MM-DD)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата создания по (RFC 3339 или YYYY-MM-DD)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подстрока названия",
+                        "name": "title",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "items": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.Document"
+                                    }
+                                },
+                                "limit": {
+                                    "type": "integer"
+                                },
+                                "next_cursor": {
+                                    "type": "string"
+                                },
+                                "offset": {
+                                    "type": "integer"
+                                },
+                                "total": {
+                                    "type": "integer"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры списка",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка получения документов",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Загрузка документа на сервер",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "documents"
+                ],
+                "summary": "Загрузка документа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название документа",
+                        "name": "title",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл документа",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "Данные не прошли проверку или файл не передан",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка загрузки файла",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/documents/{id}": {
+            "get": {
+                "description": "Скачивание документа по его ID",
+                "tags": [
+                    "documents"
+                ],
+                "summary": "Скачивание документа по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID документа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Файл для скачивания"
+                    },
+                    "400": {
+                        "description": "Некорректный ID документа",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Документ или его файл не найден",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка чтения файла",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет документ по указанному ID",
+                "tags": [
+                    "documents"
+                ],
+                "summary": "Удаление документа по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID документа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Документ удален"
+                    },
+                    "400": {
+                        "description": "Некорректный ID документа",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Документ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка удаления документа",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/logout": {
+            "post": {
+                "description": "Завершает текущую сессию пользователя",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Выход пользователя",
+                "responses": {
+                    "200": {
+                        "description": "Успешный выход"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/mfa": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Состояние двухфакторной аутентификации",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MFAStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не определён",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/mfa/recovery-codes": {
+            "post": {
+                "description": "Заменяет все коды восстановления новыми; требует действующий код второго фактора",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Новые коды восстановления",
+                "parameters": [
+                    {
+                        "description": "TOTP-код или код восстановления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "recovery_codes": {
+                                    "type": "array",
+                                    "items": {
+                                        "type": "string"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный код подтверждения",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/mfa/totp": {
+            "post": {
+                "description": "Создаёт секрет и возвращает его вместе с otpauth URI и QR-кодом (PNG в base64).\nTOTP включается после подтверждения кодом из приложения",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Начало подключения TOTP",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TOTPEnrollment"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не определён",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Двухфакторная аутентификация уже подключена",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/mfa/totp/confirm": {
+            "post": {
+                "description": "Включает TOTP по коду из приложения и возвращает одноразовые коды восстановления (показываются один раз).\nЧтобы получить права, требующие 2FA, обновите токен через /refresh",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Подтверждение подключения TOTP",
+                "parameters": [
+                    {
+                        "description": "Код из приложения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "recovery_codes": {
+                                    "type": "array",
+                                    "items": {
+                                        "type": "string"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный код подтверждения",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Двухфакторная аутентификация уже подключена",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/mfa/totp/disable": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Отключение TOTP",
+                "parameters": [
+                    {
+                        "description": "TOTP-код или код восстановления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Двухфакторная аутентификация отключена"
+                    },
+                    "400": {
+                        "description": "Неверный код подтверждения",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/news": {
+            "get": {
+                "description": "Возвращает список новостей; без прав редактора — только опубликованные",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "news"
+                ],
+                "summary": "Получение списка всех новостей",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 20, максимум 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (не используется вместе с cursor)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поле сортировки, префикс '-' — по убыванию (по умолчанию -created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата создания с (RFC 3339 или YYYY-MM-DD)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата создания по (RFC 3339 или YYYY-MM-DD)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подстрока названия",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "draft",
+                            "in_review",
+                            "scheduled",
+                            "published",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "description": "Статус новости (только для редакторов)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "items": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.News"
+                                    }
+                                },
+                                "limit": {
+                                    "type": "integer"
+                                },
+                                "next_cursor": {
+                                    "type": "string"
+                                },
+                                "offset": {
+                                    "type": "integer"
+                                },
+                                "total": {
+                                    "type": "integer"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры списка",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка получения новостей",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создаёт черновик новости; автором становится текущий пользователь",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "news"
+                ],
+                "summary": "Создание новости",
+                "parameters": [
+                    {
+                        "description": "Данные новости",
+                        "name": "news",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NewsInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.News"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса или данные не прошли проверку",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка создания новости",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/news/{id}": {
+            "get": {
+                "description": "Возвращает новость по указанному ID; без прав редактора — только опубликованную",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "news"
+                ],
+                "summary": "Получение новости по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID новости",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.News"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия новости для If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Новость не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет все поля новости; требует If-Match с ETag текущей версии.\nИзменять можно только черновик или новость на рецензии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "news"
+                ],
+                "summary": "Обновление новости по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID новости",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии новости",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Обновляемые данные новости",
+                        "name": "news",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NewsInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.News"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия новости"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID, неверный формат запроса или данные не прошли проверку",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Новость не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Новость опубликована, запланирована или в архиве",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Запись изменена другим пользователем",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка обновления новости",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет новость по указанному ID; требует If-Match с ETag текущей версии",
+                "tags": [
+                    "news"
+                ],
+                "summary": "Удаление новости по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID новости",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии новости",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Новость удалена"
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Новость не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Запись изменена другим пользователем",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка удаления новости",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Меняет только переданные поля (JSON Merge Patch, RFC 7386); требует If-Match с ETag текущей версии.\nИзменять можно только черновик или новость на рецензии",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "news"
+                ],
+                "summary": "Частичное обновление новости",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID новости",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии новости",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля новости",
+                        "name": "news",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NewsInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.News"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия новости"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID, неверный формат запроса или данные не прошли проверку",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Новость не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Новость опубликована, запланирована или в архиве",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Запись изменена другим пользователем",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка обновления новости",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/news/{id}/status": {
+            "post": {
+                "description": "Переводит новость по этапам публикации: draft → in_review → scheduled/published → archived.\nСтатусы scheduled, published и archived выставляет рецензент с правом news:publish.\nТребует If-Match с ETag текущей версии.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "news"
+                ],
+                "summary": "Смена статуса новости",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID новости",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии новости",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый статус и время публикации",
+                        "name": "transition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NewsTransition"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.News"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия новости"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID, неверный формат запроса или данные не прошли проверку",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав для этого статуса",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Новость не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Недопустимая смена статуса",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Запись изменена другим пользователем",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка смены статуса новости",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Профиль текущего пользователя",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не определён",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Меняет только переданные поля; пустая строка очищает поле",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Изменение профиля",
+                "parameters": [
+                    {
+                        "description": "Поля профиля",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProfileUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные профиля",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не определён",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/avatar": {
+            "get": {
+                "produces": [
+                    "image/png",
+                    "image/jpeg",
+                    "image/gif",
+                    "image/webp"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Аватар текущего пользователя",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Аватар не загружен",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет аватар изображением PNG, JPEG, GIF или WebP размером до 2 МБ",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Загрузка аватара",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Изображение",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Аватар сохранён"
+                    },
+                    "400": {
+                        "description": "Файл не найден или не является изображением",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Файл слишком большой",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Удаление аватара",
+                "responses": {
+                    "204": {
+                        "description": "Аватар удалён"
+                    },
+                    "404": {
+                        "description": "Аватар не загружен",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/password": {
+            "post": {
+                "description": "Проверяет текущий пароль, устанавливает новый по политике паролей и завершает все сессии, кроме текущей",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Смена пароля",
+                "parameters": [
+                    {
+                        "description": "Текущий и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "current_password": {
+                                    "type": "string"
+                                },
+                                "new_password": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Пароль изменён"
+                    },
+                    "400": {
+                        "description": "Новый пароль не соответствует политике",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Текущий пароль указан неверно",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions": {
+            "get": {
+                "description": "Возвращает активные сессии текущего пользователя; текущая отмечена полем current",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Мои сессии",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Session"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не определён",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка получения сессий",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions/{id}": {
+            "delete": {
+                "description": "Отзывает одну из сессий текущего пользователя",
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Завершение своей сессии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Сессия завершена"
+                    },
+                    "401": {
+                        "description": "Пользователь не определён",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Сессия не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/news.atom": {
+            "get": {
+                "description": "Последние опубликованные новости. Поддерживает If-None-Match и If-Modified-Since (ответ 304).\nУ новостей нет тегов и рубрик, поэтому лента одна; параметры tag и category отклоняются.",
+                "produces": [
+                    "application/atom+xml"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Лента новостей Atom 1.0",
+                "responses": {
+                    "200": {
+                        "description": "Лента Atom",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия ленты"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Время последнего изменения новостей"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Лента не изменилась"
+                    },
+                    "400": {
+                        "description": "Фильтр по тегу или рубрике не поддерживается",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка формирования ленты",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/news.rss": {
+            "get": {
+                "description": "Последние опубликованные новости. Поддерживает If-None-Match и If-Modified-Since (ответ 304).\nУ новостей нет тегов и рубрик, поэтому лента одна; параметры tag и category отклоняются.",
+                "produces": [
+                    "application/rss+xml"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Лента новостей RSS 2.0",
+                "responses": {
+                    "200": {
+                        "description": "Лента RSS",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия ленты"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Время последнего изменения новостей"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Лента не изменилась"
+                    },
+                    "400": {
+                        "description": "Фильтр по тегу или рубрике не поддерживается",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка формирования ленты",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/forgot-password": {
+            "post": {
+                "description": "Отправляет на email ссылку для сброса пароля. Ответ не зависит от того, зарегистрирован ли email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Запрос сброса пароля",
+                "parameters": [
+                    {
+                        "description": "Email пользователя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "email": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "message": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Авторизация пользователя по email и паролю.\nЕсли подключена двухфакторная аутентификация, вместо access-токена возвращается mfa_token для /login/mfa",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Авторизация пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные пользователя",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "email": {
+                                    "type": "string"
+                                },
+                                "password": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "access_token": {
+                                    "type": "string"
+                                },
+                                "mfa_required": {
+                                    "type": "boolean"
+                                },
+                                "mfa_token": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/mfa": {
+            "post": {
+                "description": "Обменивает mfa_token из /login и TOTP-код (или код восстановления) на access- и refresh-токены",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Второй шаг входа",
+                "parameters": [
+                    {
+                        "description": "MFA-токен и код",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "string"
+                                },
+                                "mfa_token": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "access_token": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/oidc/callback": {
+            "get": {
+                "description": "Завершает вход: создаёт или привязывает пользователя, устанавливает cookie с refresh-токеном.\nЕсли задан OIDC_POST_LOGIN_REDIRECT, браузер перенаправляется на фронтенд\n(при подключённой 2FA — с mfa_token во фрагменте адреса), иначе ответ такой же, как у /login",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Возврат от SSO-провайдера",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код авторизации",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Состояние входа",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "access_token": {
+                                    "type": "string"
+                                },
+                                "mfa_required": {
+                                    "type": "boolean"
+                                },
+                                "mfa_token": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "302": {
+                        "description": "Перенаправление на фронтенд"
+                    },
+                    "400": {
+                        "description": "Состояние входа недействительно",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка входа",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Учётная запись не может быть использована",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/oidc/login": {
+            "get": {
+                "description": "Перенаправляет браузер к OpenID Connect провайдеру организации",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход через SSO",
+                "responses": {
+                    "302": {
+                        "description": "Перенаправление к провайдеру"
+                    },
+                    "502": {
+                        "description": "Провайдер недоступен",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/news": {
+            "get": {
+                "description": "Возвращает опубликованные новости с анонсами, без аутентификации.\nПоддерживает условные запросы: If-None-Match и If-Modified-Since (ответ 304).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Лента опубликованных новостей",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 20, максимум 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (не используется вместе с cursor)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "published_at или title, префикс '-' — по убыванию (по умолчанию -published_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подстрока названия",
+                        "name": "title",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "items": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.PublicNews"
+                                    }
+                                },
+                                "limit": {
+                                    "type": "integer"
+                                },
+                                "next_cursor": {
+                                    "type": "string"
+                                },
+                                "offset": {
+                                    "type": "integer"
+                                },
+                                "total": {
+                                    "type": "integer"
+                                }
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия ответа"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Время последнего изменения новостей"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Лента не изменилась"
+                    },
+                    "400": {
+                        "description": "Некорректные параметры списка",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка получения новостей",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/news/{slug}": {
+            "get": {
+                "description": "Возвращает опубликованную новость целиком, без аутентификации.\nПоддерживает условные запросы: If-None-Match и If-Modified-Since (ответ 304).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Опубликованная новость по slug",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug новости",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PublicNews"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия ответа"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Время последнего изменения новости"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Новость не изменилась"
+                    },
+                    "404": {
+                        "description": "Новость не найдена или не опубликована",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка получения новости",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/refresh": {
+            "post": {
+                "description": "Обновляет access-токен с помощью refresh-токена",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Обновление access-токена",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "access_token": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Регистрация нового пользователя с email и паролем",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Регистрация пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные пользователя",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "email": {
+                                    "type": "string"
+                                },
+                                "password": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "message": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/reset-password": {
+            "post": {
+                "description": "Устанавливает новый пароль по одноразовому токену из письма и завершает все сессии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Сброс пароля",
+                "parameters": [
+                    {
+                        "description": "Токен и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "password": {
+                                    "type": "string"
+                                },
+                                "token": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "message": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email": {
+            "post": {
+                "description": "Подтверждает email по одноразовому токену из письма",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Подтверждение email",
+                "parameters": [
+                    {
+                        "description": "Токен подтверждения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "token": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "message": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email/resend": {
+            "post": {
+                "description": "Ответ не зависит от того, зарегистрирован ли email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Повторная отправка письма подтверждения",
+                "parameters": [
+                    {
+                        "description": "Email пользователя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "email": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "message": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apperrors.Code": {
+            "type": "string",
+            "enum": [
+                "bad_request",
+                "invalid_json",
+                "validation_failed",
+                "weak_password",
+                "unauthorized",
+                "invalid_credentials",
+                "token_invalid",
+                "token_revoked",
+                "mfa_invalid",
+                "forbidden",
+                "email_not_verified",
+                "account_disabled",
+                "not_found",
+                "conflict",
+                "already_exists",
+                "precondition_failed",
+                "precondition_required",
+                "payload_too_large",
+                "too_many_requests",
+                "internal_error",
+                "upstream_unavailable"
+            ],
+            "x-enum-varnames": [
+                "CodeBadRequest",
+                "CodeInvalidJSON",
+                "CodeValidation",
+                "CodeWeakPassword",
+                "CodeUnauthorized",
+                "CodeInvalidCredentials",
+                "CodeTokenInvalid",
+                "CodeTokenRevoked",
+                "CodeMFAInvalid",
+                "CodeForbidden",
+                "CodeEmailNotVerified",
+                "CodeAccountDisabled",
+                "CodeNotFound",
+                "CodeConflict",
+                "CodeAlreadyExists",
+                "CodePreconditionFailed",
+                "CodePreconditionRequired",
+                "CodePayloadTooLarge",
+                "CodeTooManyRequests",
+                "CodeInternal",
+                "CodeUpstreamFailed"
+            ]
+        },
+        "apperrors.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "apperrors.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "$ref": "#/definitions/apperrors.Code"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apperrors.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "jwtkeys.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "jwtkeys.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jwtkeys.JWK"
+                    }
+                }
+            }
+        },
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.Application": {
+            "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
@@ -745,12 +3508,67 @@ const docTemplate = `{
                 },
                 "url": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "Version увеличивается при каждом изменении и передаётся в ETag",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ApplicationInput": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 5000
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "models.Document": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MFAStatus": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "recovery_codes_left": {
+                    "type": "integer"
                 }
             }
         },
         "models.News": {
             "type": "object",
             "properties": {
+                "author_id": {
+                    "type": "integer"
+                },
                 "content": {
                     "type": "string"
                 },
@@ -760,6 +3578,146 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "publish_at": {
+                    "description": "PublishAt — плановое время публикации, а после публикации — фактическое",
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "integer"
+                },
+                "slug": {
+                    "description": "Slug — адрес новости на публичном сайте; задаётся при создании и не меняется",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.NewsStatus"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "unpublish_at": {
+                    "description": "UnpublishAt — время, когда планировщик переведёт новость в архив",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version увеличивается при каждом изменении и передаётся в ETag",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.NewsInput": {
+            "type": "object",
+            "required": [
+                "content",
+                "title"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "models.NewsStatus": {
+            "type": "string",
+            "enum": [
+                "draft",
+                "in_review",
+                "scheduled",
+                "published",
+                "archived"
+            ],
+            "x-enum-varnames": [
+                "NewsDraft",
+                "NewsInReview",
+                "NewsScheduled",
+                "NewsPublished",
+                "NewsArchived"
+            ]
+        },
+        "models.NewsTransition": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "publish_at": {
+                    "description": "PublishAt обязателен для статуса scheduled",
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "draft",
+                        "in_review",
+                        "scheduled",
+                        "published",
+                        "archived"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.NewsStatus"
+                        }
+                    ]
+                },
+                "unpublish_at": {
+                    "description": "UnpublishAt можно задать при планировании или публикации",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Permission": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProfileUpdate": {
+            "type": "object",
+            "properties": {
+                "full_name": {
+                    "type": "string"
+                },
+                "org_unit": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PublicNews": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Content отдаётся только при запросе одной новости",
+                    "type": "string"
+                },
+                "excerpt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
                 "title": {
                     "type": "string"
                 },
@@ -767,6 +3725,130 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.Role": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.Session": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "mfa_enabled": {
+                    "type": "boolean"
+                },
+                "org_unit": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.NewAPIKey": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "services.TOTPEnrollment": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string"
+                },
+                "qr_png": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,132 @@
+// Package apperrors описывает ошибки API со стабильными машинными кодами
+// и их представление в формате RFC 7807 (application/problem+json).
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Code — стабильный машинный код ошибки, на который может опираться клиент
+type Code string
+
+const (
+	CodeBadRequest         Code = "bad_request"
+	CodeInvalidJSON        Code = "invalid_json"
+	CodeValidation         Code = "validation_failed"
+	CodeWeakPassword       Code = "weak_password"
+	CodeUnauthorized       Code = "unauthorized"
+	CodeInvalidCredentials Code = "invalid_credentials"
+	CodeTokenInvalid       Code = "token_invalid"
+	CodeTokenRevoked       Code = "token_revoked"
+	CodeMFAInvalid         Code = "mfa_invalid"
+	CodeForbidden          Code = "forbidden"
+	CodeEmailNotVerified   Code = "email_not_verified"
+	CodeAccountDisabled    Code = "account_disabled"
+	CodeNotFound           Code = "not_found"
+	CodeConflict           Code = "conflict"
+	CodeAlreadyExists      Code = "already_exists"
+	// CodePreconditionFailed — запись изменилась после того, как клиент её получил (If-Match не совпал)
+	CodePreconditionFailed   Code = "precondition_failed"
+	CodePreconditionRequired Code = "precondition_required"
+	CodePayloadTooLarge      Code = "payload_too_large"
+	CodeTooManyRequests      Code = "too_many_requests"
+	CodeInternal             Code = "internal_error"
+	CodeUpstreamFailed       Code = "upstream_unavailable"
+)
+
+// statusByCode — HTTP-статус для каждого кода; неизвестный код считается внутренней ошибкой
+var statusByCode = map[Code]int{
+	CodeBadRequest:           http.StatusBadRequest,
+	CodeInvalidJSON:          http.StatusBadRequest,
+	CodeValidation:           http.StatusBadRequest,
+	CodeWeakPassword:         http.StatusBadRequest,
+	CodeUnauthorized:         http.StatusUnauthorized,
+	CodeInvalidCredentials:   http.StatusUnauthorized,
+	CodeTokenInvalid:         http.StatusUnauthorized,
+	CodeTokenRevoked:         http.StatusUnauthorized,
+	CodeMFAInvalid:           http.StatusUnauthorized,
+	CodeForbidden:            http.StatusForbidden,
+	CodeEmailNotVerified:     http.StatusForbidden,
+	CodeAccountDisabled:      http.StatusForbidden,
+	CodeNotFound:             http.StatusNotFound,
+	CodeConflict:             http.StatusConflict,
+	CodeAlreadyExists:        http.StatusConflict,
+	CodePreconditionFailed:   http.StatusPreconditionFailed,
+	CodePreconditionRequired: http.StatusPreconditionRequired,
+	CodePayloadTooLarge:      http.StatusRequestEntityTooLarge,
+	CodeTooManyRequests:      http.StatusTooManyRequests,
+	CodeInternal:             http.StatusInternalServerError,
+	CodeUpstreamFailed:       http.StatusBadGateway,
+}
+
+// Status возвращает HTTP-статус кода
+func (c Code) Status() int {
+	if status, ok := statusByCode[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// FieldError — ошибка отдельного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error — ошибка, которую можно показать клиенту. Err — исходная причина, в ответ не попадает.
+type Error struct {
+	Code    Code
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status — HTTP-статус ответа
+func (e *Error) Status() int {
+	return e.Code.Status()
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap сохраняет причину для журнала и errors.Is, показывая клиенту только message
+func Wrap(err error, code Code, message string) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// Validation — ошибка с перечнем полей, не прошедших проверку
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Code: CodeValidation, Message: message, Fields: fields}
+}
+
+func BadRequest(message string) *Error {
+	return New(CodeBadRequest, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(CodeUnauthorized, message)
+}
+
+func NotFound(message string) *Error {
+	return New(CodeNotFound, message)
+}
+
+// As извлекает *Error из цепочки ошибок
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	ok := errors.As(err, &appErr)
+	return appErr, ok
+}
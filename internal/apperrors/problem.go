@@ -0,0 +1,47 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ContentType — тип тела ответа с ошибкой по RFC 7807
+const ContentType = "application/problem+json"
+
+// typePrefix — префикс URI типа проблемы; к нему добавляется машинный код
+const typePrefix = "urn:rcoi:error:"
+
+// Problem — тело ответа RFC 7807 с расширениями code и errors
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     Code         `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem собирает тело ответа; instance — путь запроса
+func NewProblem(e *Error, instance string) *Problem {
+	status := e.Status()
+	return &Problem{
+		Type:     typePrefix + string(e.Code),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     e.Code,
+		Errors:   e.Fields,
+	}
+}
+
+// Write отправляет ошибку клиенту в формате application/problem+json
+func Write(w http.ResponseWriter, r *http.Request, e *Error) {
+	problem := NewProblem(e, r.URL.Path)
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
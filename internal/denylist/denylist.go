@@ -0,0 +1,29 @@
+package denylist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store хранит идентификаторы (jti) отозванных access-токенов.
+// Запись нужна только до истечения срока действия токена, поэтому хранится
+// в течение ttl — не меньше времени жизни access-токена.
+type Store interface {
+	Add(ctx context.Context, jti string) error
+	Contains(ctx context.Context, jti string) (bool, error)
+}
+
+// New создаёт хранилище отозванных токенов в соответствии с конфигурацией
+func New(driver string, db *pgxpool.Pool, ttl time.Duration) (Store, error) {
+	switch driver {
+	case "", "postgres":
+		return NewPostgresStore(db, ttl), nil
+	case "memory":
+		return NewMemoryStore(ttl), nil
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер denylist: %s", driver)
+	}
+}
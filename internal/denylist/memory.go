@@ -0,0 +1,46 @@
+package denylist
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore хранит отозванные токены в памяти процесса.
+// Подходит только для одного экземпляра API: реплики не видят записи друг друга.
+type MemoryStore struct {
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[string]time.Time
+}
+
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{ttl: ttl, entries: make(map[string]time.Time)}
+}
+
+func (s *MemoryStore) Add(_ context.Context, jti string) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Заодно убираем записи, срок которых истёк
+	for key, expiresAt := range s.entries {
+		if !expiresAt.After(now) {
+			delete(s.entries, key)
+		}
+	}
+
+	if expiresAt := now.Add(s.ttl); expiresAt.After(s.entries[jti]) {
+		s.entries[jti] = expiresAt
+	}
+	return nil
+}
+
+func (s *MemoryStore) Contains(_ context.Context, jti string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	expiresAt, ok := s.entries[jti]
+	return ok && expiresAt.After(time.Now()), nil
+}
@@ -0,0 +1,40 @@
+package denylist
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore хранит отозванные токены в таблице revoked_access_tokens,
+// общей для всех реплик API
+type PostgresStore struct {
+	db  *pgxpool.Pool
+	ttl time.Duration
+}
+
+func NewPostgresStore(db *pgxpool.Pool, ttl time.Duration) *PostgresStore {
+	return &PostgresStore{db: db, ttl: ttl}
+}
+
+func (s *PostgresStore) Add(ctx context.Context, jti string) error {
+	query := `
+		INSERT INTO revoked_access_tokens (jti, expires_at)
+		VALUES ($1, NOW() + make_interval(secs => $2))
+		ON CONFLICT (jti) DO UPDATE SET expires_at = GREATEST(revoked_access_tokens.expires_at, EXCLUDED.expires_at)
+	`
+	if _, err := s.db.Exec(ctx, query, jti, s.ttl.Seconds()); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(ctx, `DELETE FROM revoked_access_tokens WHERE expires_at <= NOW()`)
+	return err
+}
+
+func (s *PostgresStore) Contains(ctx context.Context, jti string) (bool, error) {
+	var found bool
+	query := `SELECT EXISTS (SELECT 1 FROM revoked_access_tokens WHERE jti = $1 AND expires_at > NOW())`
+	err := s.db.QueryRow(ctx, query, jti).Scan(&found)
+	return found, err
+}
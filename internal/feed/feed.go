@@ -0,0 +1,187 @@
+// Package feed формирует ленты RSS 2.0 и Atom 1.0
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// Format — формат ленты
+type Format string
+
+const (
+	RSS  Format = "rss"
+	Atom Format = "atom"
+)
+
+// ContentType — MIME-тип ленты для заголовка Content-Type
+func (f Format) ContentType() string {
+	if f == Atom {
+		return "application/atom+xml; charset=utf-8"
+	}
+	return "application/rss+xml; charset=utf-8"
+}
+
+// Feed — лента, не зависящая от формата
+type Feed struct {
+	Title       string
+	Description string
+	// Link — страница сайта, которую описывает лента
+	Link     string
+	Language string
+	// Updated — время последнего изменения ленты
+	Updated time.Time
+	Items   []Item
+}
+
+// Item — запись ленты
+type Item struct {
+	// ID — постоянный идентификатор записи (guid в RSS, id в Atom); не меняется при правке записи
+	ID      string
+	Title   string
+	Link    string
+	Summary string
+	// Content — HTML записи; экранируется при формировании XML
+	Content   string
+	Published time.Time
+	Updated   time.Time
+}
+
+// Render формирует документ ленты в формате format
+func (f *Feed) Render(format Format) ([]byte, error) {
+	var doc any
+	switch format {
+	case RSS:
+		doc = f.rss()
+	case Atom:
+		doc = f.atom()
+	default:
+		return nil, fmt.Errorf("неизвестный формат ленты %q", format)
+	}
+
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (f *Feed) rss() rssDocument {
+	channel := rssChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		Description:   f.Description,
+		Language:      f.Language,
+		LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+	}
+	for _, item := range f.Items {
+		// В RSS нет отдельного поля для полного текста: description содержит HTML записи
+		description := item.Content
+		if description == "" {
+			description = item.Summary
+		}
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: item.ID == item.Link, Value: item.ID},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Description: description,
+		})
+	}
+	return rssDocument{Version: "2.0", Channel: channel}
+}
+
+type atomDocument struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang     string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Link     atomLink    `xml:"link"`
+	Author   atomAuthor  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string    `xml:"id"`
+	Title     string    `xml:"title"`
+	Link      atomLink  `xml:"link"`
+	Published string    `xml:"published"`
+	Updated   string    `xml:"updated"`
+	Summary   *atomText `xml:"summary,omitempty"`
+	Content   *atomText `xml:"content,omitempty"`
+}
+
+func (f *Feed) atom() atomDocument {
+	doc := atomDocument{
+		Lang:     f.Language,
+		ID:       f.Link,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.UTC().Format(time.RFC3339),
+		Link:     atomLink{Rel: "alternate", Href: f.Link},
+		// Atom требует автора у ленты или у каждой записи
+		Author: atomAuthor{Name: f.Title},
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Link:      atomLink{Rel: "alternate", Href: item.Link},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+		}
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Value: item.Summary}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Value: item.Content}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return doc
+}
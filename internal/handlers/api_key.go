@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"rcoi/internal/middleware"
+	"rcoi/internal/models"
+	"rcoi/internal/services"
+)
+
+type APIKeyHandler struct {
+	service services.APIKeyService
+	logger  *zap.Logger
+}
+
+func NewAPIKeyHandler(service services.APIKeyService, logger *zap.Logger) *APIKeyHandler {
+	return &APIKeyHandler{service: service, logger: logger}
+}
+
+// GetAllAPIKeys godoc
+// @Summary Список API-ключей
+// @Description Возвращает все ключи, включая отозванные и истёкшие; сами ключи не возвращаются
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.APIKey
+// @Failure 500 {object} apperrors.Problem "Ошибка получения API-ключей"
+// @Router /api/admin/api-keys [get]
+func (h *APIKeyHandler) GetAllAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.GetAll(r.Context())
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения API-ключей")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// CreateAPIKey godoc
+// @Summary Выпуск API-ключа
+// @Description Ключ передаётся в заголовке X-API-Key и даёт только перечисленные права.
+// @Description Право api_keys:manage ключу выдать нельзя. Ключ действует, пока выпустивший
+// @Description его администратор активен, и только в пределах его текущих прав.
+// @Description Значение ключа возвращается один раз — сохраните его сразу
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key body services.NewAPIKey true "Название, права и срок действия (RFC 3339, необязательно)"
+// @Success 201 {object} object{key=string,api_key=models.APIKey}
+// @Failure 400 {object} apperrors.Problem "Неверный формат запроса, неизвестное или недопустимое для ключа право"
+// @Failure 403 {object} apperrors.Problem "Нельзя выдать ключу право, которого нет у вас"
+// @Router /api/admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req services.NewAPIKey
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, r)
+		return
+	}
+
+	actor, _ := middleware.GetEmailFromContext(r.Context())
+	permissions, _ := middleware.GetPermissionsFromContext(r.Context())
+	key, rawKey, err := h.service.Issue(r.Context(), actor, permissions, req)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка управления API-ключами")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		Key    string         `json:"key"`
+		APIKey *models.APIKey `json:"api_key"`
+	}{Key: rawKey, APIKey: key})
+}
+
+// RevokeAPIKey godoc
+// @Summary Отзыв API-ключа
+// @Tags admin
+// @Param id path int true "ID ключа"
+// @Success 204 "Ключ отозван"
+// @Failure 404 {object} apperrors.Problem "API-ключ не найден"
+// @Router /api/admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	actor, _ := middleware.GetEmailFromContext(r.Context())
+	if err := h.service.Revoke(r.Context(), actor, id); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка управления API-ключами")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
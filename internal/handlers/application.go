@@ -4,23 +4,24 @@ import (
 	"encoding/json"
 	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
 	"rcoi/internal/models"
 	"rcoi/internal/services"
+	"rcoi/internal/validation"
 )
 
 type ApplicationHandler struct {
-	service services.ApplicationService
-	logger  *zap.Logger
+	service   services.ApplicationService
+	validator *validation.Validator
+	logger    *zap.Logger
 }
 
-func NewApplicationHandler(service services.ApplicationService, logger *zap.Logger) *ApplicationHandler {
-	return &ApplicationHandler{service: service, logger: logger}
+func NewApplicationHandler(service services.ApplicationService, validator *validation.Validator, logger *zap.Logger) *ApplicationHandler {
+	return &ApplicationHandler{service: service, validator: validator, logger: logger}
 }
 
 // CreateApplication godoc
@@ -34,37 +35,45 @@ func NewApplicationHandler(service services.ApplicationService, logger *zap.Logg
 // @Param url formData string false "URL приложения"
 // @Param file formData file false "Файл приложения"
 // @Success 201 {object} models.Application
-// @Failure 400 "Файл не найден"
-// @Failure 500 "Ошибка создания приложения"
+// @Failure 400 {object} apperrors.Problem "Данные не прошли проверку или не передан ни файл, ни URL"
+// @Failure 500 {object} apperrors.Problem "Ошибка создания приложения"
 // @Router /api/applications [post]
 func (h *ApplicationHandler) CreateApplication(w http.ResponseWriter, r *http.Request) {
-	title := r.FormValue("title")
-	description := r.FormValue("description")
-	url := r.FormValue("url")
+	input := models.ApplicationInput{
+		Title:       r.FormValue("title"),
+		Description: r.FormValue("description"),
+		URL:         r.FormValue("url"),
+	}
 
 	var file multipart.File
 	var fileHeader *multipart.FileHeader
-	var err error
+	var missing []apperrors.FieldError
 
-	if url == "" {
+	if input.URL == "" {
+		var err error
 		file, fileHeader, err = r.FormFile("file")
 		if err != nil {
-			http.Error(w, "Файл не найден", http.StatusBadRequest)
-			return
+			missing = append(missing, apperrors.FieldError{Field: "file", Message: "Загрузите файл или укажите url"})
+		} else {
+			defer file.Close()
+			input.FileName = fileHeader.Filename
 		}
-		defer file.Close()
+	}
+
+	if err := h.validator.Validate(&input, missing...); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка создания приложения")
+		return
 	}
 
 	app := &models.Application{
-		Title:       title,
-		Description: description,
-		URL:         url,
+		Title:       input.Title,
+		Description: input.Description,
+		URL:         input.URL,
 	}
 
-	err = h.service.CreateApplication(r.Context(), app, file, fileHeader)
+	err := h.service.CreateApplication(r.Context(), app, file, fileHeader)
 	if err != nil {
-		h.logger.Error("Ошибка создания приложения", zap.Error(err))
-		http.Error(w, "Ошибка создания приложения", http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка создания приложения")
 		return
 	}
 
@@ -77,13 +86,27 @@ func (h *ApplicationHandler) CreateApplication(w http.ResponseWriter, r *http.Re
 // @Description Возвращает список всех загруженных приложений
 // @Tags applications
 // @Produce json
-// @Success 200 {array} models.Application
-// @Failure 500 "Ошибка получения приложений"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param offset query int false "Смещение (не используется вместе с cursor)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Поле сортировки, префикс '-' — по убыванию (по умолчанию -created_at)"
+// @Param created_from query string false "Дата создания с (RFC 3339 или YYYY-MM-DD)"
+// @Param created_to query string false "Дата создания по (RFC 3339 или YYYY-MM-DD)"
+// @Param title query string false "Подстрока названия"
+// @Success 200 {object} object{items=[]models.Application,total=int,limit=int,offset=int,next_cursor=string}
+// @Failure 400 {object} apperrors.Problem "Некорректные параметры списка"
+// @Failure 500 {object} apperrors.Problem "Ошибка получения приложений"
 // @Router /api/applications [get]
 func (h *ApplicationHandler) GetAllApplications(w http.ResponseWriter, r *http.Request) {
-	apps, err := h.service.GetAllApplications(r.Context())
+	q, err := parseListQuery(r, "title")
 	if err != nil {
-		http.Error(w, "Ошибка получения приложений", http.StatusInternalServerError)
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	apps, err := h.service.GetAllApplications(r.Context(), q)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения приложений")
 		return
 	}
 
@@ -97,97 +120,195 @@ func (h *ApplicationHandler) GetAllApplications(w http.ResponseWriter, r *http.R
 // @Produce json
 // @Param id path int true "ID приложения"
 // @Success 200 {object} models.Application
-// @Failure 400 "Некорректный ID приложения"
-// @Failure 404 "Приложение не найдено"
+// @Header 200 {string} ETag "Версия приложения для If-Match"
+// @Failure 400 {object} apperrors.Problem "Некорректный ID приложения"
+// @Failure 404 {object} apperrors.Problem "Приложение или его файл не найден"
+// @Failure 500 {object} apperrors.Problem "Ошибка чтения файла"
 // @Router /api/applications/{id} [get]
 func (h *ApplicationHandler) GetApplicationByID(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Некорректный ID приложения", http.StatusBadRequest)
+		badRequest(w, r, "Некорректный ID приложения")
 		return
 	}
 
 	app, err := h.service.GetApplicationByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Приложение не найдено", http.StatusNotFound)
+		writeError(w, r, h.logger, err, "Ошибка получения приложения")
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(app.Version))
 	if app.URL != "" {
 		json.NewEncoder(w).Encode(app)
 		return
 	}
 
-	filePath := filepath.Join("uploads", app.Filename)
-	w.Header().Set("Content-Disposition", "attachment; filename="+app.Filename)
-	http.ServeFile(w, r, filePath)
+	file, info, err := h.service.OpenApplicationFile(r.Context(), app)
+	if err != nil {
+		writeFileError(w, r, h.logger, err, "Файл приложения не найден")
+		return
+	}
+
+	serveStoredFile(w, r, app.Filename, file, info)
 }
 
 // UpdateApplication godoc
 // @Summary Обновление данных приложения
-// @Description Обновляет данные приложения по указанному ID
+// @Description Заменяет название, описание и URL приложения; требует If-Match с ETag текущей версии.
+// @Description Пустой url допустим, только если у приложения загружен файл. Указанный url заменяет файл: он удаляется из хранилища
 // @Tags applications
 // @Accept json
 // @Produce json
 // @Param id path int true "ID приложения"
-// @Param application body models.Application true "Обновляемые данные приложения"
+// @Param If-Match header string true "ETag текущей версии приложения"
+// @Param application body models.ApplicationInput true "Обновляемые данные приложения"
 // @Success 200 {object} models.Application
-// @Failure 400 "Некорректный ID или неверный формат запроса"
-// @Failure 500 "Ошибка обновления приложения"
+// @Header 200 {string} ETag "Новая версия приложения"
+// @Failure 400 {object} apperrors.Problem "Некорректный ID, неверный формат запроса или данные не прошли проверку"
+// @Failure 404 {object} apperrors.Problem "Приложение не найдено"
+// @Failure 412 {object} apperrors.Problem "Запись изменена другим пользователем"
+// @Failure 428 {object} apperrors.Problem "Не передан заголовок If-Match"
+// @Failure 500 {object} apperrors.Problem "Ошибка обновления приложения"
 // @Router /api/applications/{id} [put]
 func (h *ApplicationHandler) UpdateApplication(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Некорректный ID", http.StatusBadRequest)
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	current, err := h.service.GetApplicationByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обновления приложения")
+		return
+	}
+	if !checkIfMatch(w, r, current.Version) {
 		return
 	}
 
-	var app models.Application
-	if err := json.NewDecoder(r.Body).Decode(&app); err != nil {
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+	var input models.ApplicationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		invalidJSON(w, r)
 		return
 	}
-	app.ID = id
+	h.saveApplication(w, r, current, input)
+}
 
+// PatchApplication godoc
+// @Summary Частичное обновление приложения
+// @Description Меняет только переданные поля (JSON Merge Patch, RFC 7386); требует If-Match с ETag текущей версии.
+// @Description Пустой url допустим, только если у приложения загружен файл. Указанный url заменяет файл: он удаляется из хранилища
+// @Tags applications
+// @Accept application/merge-patch+json
+// @Accept json
+// @Produce json
+// @Param id path int true "ID приложения"
+// @Param If-Match header string true "ETag текущей версии приложения"
+// @Param application body models.ApplicationInput true "Изменяемые поля приложения"
+// @Success 200 {object} models.Application
+// @Header 200 {string} ETag "Новая версия приложения"
+// @Failure 400 {object} apperrors.Problem "Некорректный ID, неверный формат запроса или данные не прошли проверку"
+// @Failure 404 {object} apperrors.Problem "Приложение не найдено"
+// @Failure 412 {object} apperrors.Problem "Запись изменена другим пользователем"
+// @Failure 428 {object} apperrors.Problem "Не передан заголовок If-Match"
+// @Failure 500 {object} apperrors.Problem "Ошибка обновления приложения"
+// @Router /api/applications/{id} [patch]
+func (h *ApplicationHandler) PatchApplication(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	current, err := h.service.GetApplicationByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обновления приложения")
+		return
+	}
+	if !checkIfMatch(w, r, current.Version) {
+		return
+	}
+
+	patch, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+	input, err := applyMergePatch(models.ApplicationInput{
+		Title:       current.Title,
+		Description: current.Description,
+		URL:         current.URL,
+	}, patch)
+	if err != nil {
+		invalidJSON(w, r)
+		return
+	}
+	h.saveApplication(w, r, current, input)
+}
+
+// saveApplication проверяет input и сохраняет его поверх версии current
+func (h *ApplicationHandler) saveApplication(w http.ResponseWriter, r *http.Request, current *models.Application, input models.ApplicationInput) {
+	// То же правило, что и при создании: у приложения должен остаться файл или url.
+	// Файл здесь не загружается, поэтому без файла url обязателен.
+	var missing []apperrors.FieldError
+	if input.URL == "" && current.Filename == "" {
+		missing = append(missing, apperrors.FieldError{Field: "url", Message: "У приложения нет файла: укажите url"})
+	}
+
+	if err := h.validator.Validate(&input, missing...); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обновления приложения")
+		return
+	}
+
+	app := models.Application{
+		ID:          current.ID,
+		Title:       input.Title,
+		Description: input.Description,
+		URL:         input.URL,
+		Filename:    current.Filename,
+		Version:     current.Version,
+	}
 	if err := h.service.UpdateApplication(r.Context(), &app); err != nil {
-		http.Error(w, "Ошибка обновления приложения", http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка обновления приложения")
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(app.Version))
 	json.NewEncoder(w).Encode(app)
 }
 
 // DeleteApplication godoc
 // @Summary Удаление приложения
-// @Description Удаляет приложение по указанному ID
+// @Description Удаляет приложение по указанному ID; требует If-Match с ETag текущей версии
 // @Tags applications
 // @Param id path int true "ID приложения"
+// @Param If-Match header string true "ETag текущей версии приложения"
 // @Success 204 "Приложение удалено"
-// @Failure 400 "Некорректный ID приложения"
-// @Failure 404 "Приложение не найдено"
-// @Failure 500 "Ошибка удаления приложения"
+// @Failure 400 {object} apperrors.Problem "Некорректный ID приложения"
+// @Failure 404 {object} apperrors.Problem "Приложение не найдено"
+// @Failure 412 {object} apperrors.Problem "Запись изменена другим пользователем"
+// @Failure 428 {object} apperrors.Problem "Не передан заголовок If-Match"
+// @Failure 500 {object} apperrors.Problem "Ошибка удаления приложения"
 // @Router /api/applications/{id} [delete]
 func (h *ApplicationHandler) DeleteApplication(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Некорректный ID приложения", http.StatusBadRequest)
+		badRequest(w, r, "Некорректный ID приложения")
 		return
 	}
 
-	app, err := h.service.GetApplicationByID(r.Context(), id)
+	current, err := h.service.GetApplicationByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Приложение не найдено", http.StatusNotFound)
+		writeError(w, r, h.logger, err, "Ошибка удаления приложения")
 		return
 	}
-
-	if app.Filename != "" {
-		os.Remove(filepath.Join("uploads", app.Filename))
+	if !checkIfMatch(w, r, current.Version) {
+		return
 	}
 
-	err = h.service.DeleteApplication(r.Context(), id)
+	err = h.service.DeleteApplication(r.Context(), id, current.Version)
 	if err != nil {
-		h.logger.Error("Ошибка удаления приложения", zap.Error(err))
-		http.Error(w, "Ошибка удаления приложения", http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка удаления приложения")
 		return
 	}
 
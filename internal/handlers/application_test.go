@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"rcoi/internal/models"
+	"rcoi/internal/services"
+)
+
+type fakeApplicationService struct {
+	services.ApplicationService
+	app     *models.Application
+	updated *models.Application
+}
+
+func (f *fakeApplicationService) GetApplicationByID(ctx context.Context, id int) (*models.Application, error) {
+	return f.app, nil
+}
+
+func (f *fakeApplicationService) UpdateApplication(ctx context.Context, app *models.Application) error {
+	f.updated = app
+	app.Version++
+	return nil
+}
+
+func TestSaveApplicationRequiresFileOrURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		method   string
+		body     string
+		status   int
+	}{
+		{"PUT без файла и url", "", http.MethodPut, `{"title":"Приложение","url":""}`, http.StatusBadRequest},
+		{"PATCH стирает url без файла", "", http.MethodPatch, `{"url":null}`, http.StatusBadRequest},
+		{"PUT с url", "", http.MethodPut, `{"title":"Приложение","url":"https://example.org/app"}`, http.StatusOK},
+		{"PATCH стирает url при файле", "app.zip", http.MethodPatch, `{"url":null}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeApplicationService{app: &models.Application{
+				ID: 1, Title: "Приложение", URL: "https://example.org/old", Filename: tt.filename, Version: 3,
+			}}
+			h := NewApplicationHandler(service, newTestValidator(), zap.NewNop())
+			router := mux.NewRouter()
+			router.HandleFunc("/applications/{id}", h.UpdateApplication).Methods(http.MethodPut)
+			router.HandleFunc("/applications/{id}", h.PatchApplication).Methods(http.MethodPatch)
+
+			req := httptest.NewRequest(tt.method, "/applications/1", strings.NewReader(tt.body))
+			req.Header.Set("If-Match", versionETag(3))
+			if tt.method == http.MethodPatch {
+				req.Header.Set("Content-Type", "application/merge-patch+json")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status == http.StatusBadRequest {
+				problem := decodeProblem(t, w)
+				if len(problem.Errors) != 1 || problem.Errors[0].Field != "url" {
+					t.Fatalf("errors = %+v, ожидается ошибка поля url", problem.Errors)
+				}
+				if service.updated != nil {
+					t.Fatal("приложение сохранено без файла и url")
+				}
+				return
+			}
+			// Сервису передаётся файл сохраняемой версии, чтобы он мог снять его при замене на url
+			if service.updated.Filename != tt.filename {
+				t.Fatalf("Filename = %q, want %q", service.updated.Filename, tt.filename)
+			}
+		})
+	}
+}
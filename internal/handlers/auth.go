@@ -2,64 +2,25 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
+	"net"
 	"net/http"
 	"rcoi/internal/middleware"
 	"rcoi/internal/services"
-	"regexp"
-	"strings"
+	"time"
 
 	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
 )
 
 type AuthHandler struct {
-	service services.AuthService
-	logger  *zap.Logger
+	service    services.AuthService
+	refreshTTL time.Duration
+	logger     *zap.Logger
 }
 
-func NewAuthHandler(service services.AuthService, logger *zap.Logger) *AuthHandler {
-	return &AuthHandler{service: service, logger: logger}
-}
-
-func validatePassword(password string) string {
-	var (
-		lengthRegex    = regexp.MustCompile(`^.{8,}$`)
-		upperCaseRegex = regexp.MustCompile(`[A-Z]`)
-		lowerCaseRegex = regexp.MustCompile(`[a-z]`)
-		englishRegex   = regexp.MustCompile(`^[A-Za-z\d]+$`)
-	)
-
-	var errors []string
-
-	if !lengthRegex.MatchString(password) {
-		errors = append(errors, "Пароль должен быть не менее 8 символов")
-	}
-	if !upperCaseRegex.MatchString(password) {
-		errors = append(errors, "Пароль должен содержать хотя бы одну заглавную букву")
-	}
-	if !lowerCaseRegex.MatchString(password) {
-		errors = append(errors, "Пароль должен содержать хотя бы одну строчную букву")
-	}
-	if !englishRegex.MatchString(password) {
-		errors = append(errors, "Пароль должен содержать только латинские буквы и цифры")
-	}
-
-	if len(errors) > 0 {
-		return "Ошибка пароля: " + formatErrors(errors)
-	}
-
-	return ""
-}
-
-func formatErrors(errors []string) string {
-	result := ""
-	for i, err := range errors {
-		if i > 0 {
-			result += "; "
-		}
-		result += err
-	}
-	return result
+func NewAuthHandler(service services.AuthService, refreshTTL time.Duration, logger *zap.Logger) *AuthHandler {
+	return &AuthHandler{service: service, refreshTTL: refreshTTL, logger: logger}
 }
 
 // Register godoc
@@ -70,9 +31,9 @@ func formatErrors(errors []string) string {
 // @Produce json
 // @Param user body object{email=string,password=string} true "Данные пользователя"
 // @Success 201 {object} object{message=string}
-// @Failure 400 {object} object{error=string}
-// @Failure 409 {object} object{error=string}
-// @Failure 500 {object} object{error=string}
+// @Failure 400 {object} apperrors.Problem
+// @Failure 409 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
 // @Router /register [post]
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -82,50 +43,47 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.Error("Ошибка декодирования JSON", zap.Error(err))
-		http.Error(w, `{"error": "Неверный формат запроса"}`, http.StatusBadRequest)
+		invalidJSON(w, r)
 		return
 	}
 
 	if req.Email == "" || req.Password == "" {
-		http.Error(w, `{"error": "Email и пароль обязательны"}`, http.StatusBadRequest)
-		return
-	}
-
-	if errMsg := validatePassword(req.Password); errMsg != "" {
-		http.Error(w, fmt.Sprintf(`{"error": "%s"}`, errMsg), http.StatusBadRequest)
+		apperrors.Write(w, r, apperrors.Validation("Email и пароль обязательны", requiredFields(map[string]string{
+			"email":    req.Email,
+			"password": req.Password,
+		})...))
 		return
 	}
 
 	err := h.service.RegisterUser(r.Context(), req.Email, req.Password)
 	if err != nil {
-		if strings.Contains(err.Error(), "SQLSTATE 23505") {
+		if errors.Is(err, services.ErrEmailExists) {
 			h.logger.Warn("Попытка регистрации с уже существующим email", zap.String("email", req.Email))
-			http.Error(w, `{"error": "Email уже используется"}`, http.StatusConflict)
-			return
 		}
-
-		h.logger.Error("Ошибка регистрации пользователя", zap.Error(err))
-		http.Error(w, `{"error": "Ошибка регистрации"}`, http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка регистрации")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Пользователь успешно зарегистрирован",
+		"message": "Пользователь успешно зарегистрирован. Подтвердите email по ссылке из письма",
 	})
 }
 
 // Login godoc
 // @Summary Авторизация пользователя
-// @Description Авторизация пользователя по email и паролю
+// @Description Авторизация пользователя по email и паролю.
+// @Description Если подключена двухфакторная аутентификация, вместо access-токена возвращается mfa_token для /login/mfa
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param user body object{email=string,password=string} true "Данные пользователя"
-// @Success 200 {object} object{access_token=string}
-// @Failure 400 {object} object{error=string}
-// @Failure 401 {object} object{error=string}
+// @Success 200 {object} object{access_token=string,mfa_required=bool,mfa_token=string}
+// @Failure 400 {object} apperrors.Problem
+// @Failure 401 {object} apperrors.Problem
+// @Failure 403 {object} apperrors.Problem
+// @Failure 429 {object} apperrors.Problem
 // @Router /login [post]
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -135,31 +93,74 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.Error("Ошибка декодирования JSON", zap.Error(err))
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+		invalidJSON(w, r)
 		return
 	}
 
-	accessToken, refreshToken, err := h.service.Login(r.Context(), req.Email, req.Password)
+	result, err := h.service.Login(r.Context(), req.Email, req.Password, sessionMeta(r))
 	if err != nil {
-		h.logger.Warn("Ошибка входа", zap.Error(err))
-		http.Error(w, "Ошибка входа", http.StatusUnauthorized)
+		h.writeLoginError(w, r, err)
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "refresh_token",
-		Value:    refreshToken,
-		HttpOnly: true,
-		Secure:   true,
-		Path:     "/",
-		MaxAge:   604800,
-	})
+	if result.MFAToken != "" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"mfa_required": true,
+			"mfa_token":    result.MFAToken,
+		})
+		return
+	}
+
+	h.writeTokens(w, result)
+}
+
+// LoginMFA godoc
+// @Summary Второй шаг входа
+// @Description Обменивает mfa_token из /login и TOTP-код (или код восстановления) на access- и refresh-токены
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body object{mfa_token=string,code=string} true "MFA-токен и код"
+// @Success 200 {object} object{access_token=string}
+// @Failure 400 {object} apperrors.Problem
+// @Failure 401 {object} apperrors.Problem
+// @Failure 429 {object} apperrors.Problem
+// @Router /login/mfa [post]
+func (h *AuthHandler) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		MFAToken string `json:"mfa_token"`
+		Code     string `json:"code"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MFAToken == "" || req.Code == "" {
+		badRequest(w, r, "Требуются mfa_token и code")
+		return
+	}
+
+	result, err := h.service.CompleteMFA(r.Context(), req.MFAToken, req.Code, sessionMeta(r))
+	if err != nil {
+		h.writeLoginError(w, r, err)
+		return
+	}
+
+	h.writeTokens(w, result)
+}
+
+func (h *AuthHandler) writeTokens(w http.ResponseWriter, result *services.LoginResult) {
+	setRefreshCookie(w, result.RefreshToken, int(h.refreshTTL.Seconds()))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
-		"access_token": accessToken,
+		"access_token": result.AccessToken,
 	})
+}
 
+func (h *AuthHandler) writeLoginError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		h.logger.Warn("Ошибка входа", zap.Error(err))
+	}
+	writeError(w, r, h.logger, err, "Ошибка входа")
 }
 
 // Refresh godoc
@@ -168,29 +169,22 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 // @Tags auth
 // @Produce json
 // @Success 200 {object} object{access_token=string}
-// @Failure 401 {object} object{error=string}
+// @Failure 401 {object} apperrors.Problem
 // @Router /refresh [post]
 func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	cookie, err := r.Cookie("refresh_token")
 	if err != nil {
-		http.Error(w, "Refresh-токен отсутствует", http.StatusUnauthorized)
+		apperrors.Write(w, r, apperrors.New(apperrors.CodeTokenInvalid, "Refresh-токен отсутствует"))
 		return
 	}
 
-	newAccessToken, newRefreshToken, err := h.service.RefreshToken(r.Context(), cookie.Value)
+	newAccessToken, newRefreshToken, err := h.service.RefreshToken(r.Context(), cookie.Value, sessionMeta(r))
 	if err != nil {
-		http.Error(w, "Ошибка обновления токена", http.StatusUnauthorized)
+		writeError(w, r, h.logger, err, "Ошибка обновления токена")
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "refresh_token",
-		Value:    newRefreshToken,
-		HttpOnly: true,
-		Secure:   false,
-		Path:     "/",
-		MaxAge:   604800,
-	})
+	setRefreshCookie(w, newRefreshToken, int(h.refreshTTL.Seconds()))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
@@ -201,34 +195,51 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 
 // Logout godoc
 // @Summary Выход пользователя
-// @Description Выход пользователя и удаление refresh-токена
+// @Description Завершает текущую сессию пользователя
 // @Tags auth
 // @Success 200 "Успешный выход"
-// @Failure 401 {object} object{error=string}
-// @Failure 500 {object} object{error=string}
+// @Failure 401 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
 // @Router /api/logout [post]
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
-	email, ok := r.Context().Value(middleware.UserEmailKey).(string)
+	sessionID, ok := middleware.GetSessionIDFromContext(r.Context())
 	if !ok {
-		h.logger.Warn("Ошибка выхода: email не найден в контексте")
-		http.Error(w, "Ошибка выхода", http.StatusUnauthorized)
+		h.logger.Warn("Ошибка выхода: сессия не найдена в контексте")
+		apperrors.Write(w, r, apperrors.Unauthorized("Ошибка выхода"))
 		return
 	}
 
-	if err := h.service.Logout(r.Context(), email); err != nil {
-		h.logger.Error("Ошибка выхода", zap.Error(err))
-		http.Error(w, "Ошибка выхода", http.StatusInternalServerError)
+	if err := h.service.Logout(r.Context(), sessionID); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка выхода")
 		return
 	}
 
+	setRefreshCookie(w, "", -1)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// setRefreshCookie устанавливает или (при maxAge < 0) удаляет cookie с refresh-токеном
+func setRefreshCookie(w http.ResponseWriter, value string, maxAge int) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "refresh_token",
-		Value:    "",
+		Value:    value,
 		HttpOnly: true,
 		Secure:   true,
 		Path:     "/",
-		MaxAge:   -1,
+		MaxAge:   maxAge,
 	})
+}
 
-	w.WriteHeader(http.StatusOK)
+// sessionMeta собирает сведения об устройстве для записи сессии
+func sessionMeta(r *http.Request) services.SessionMeta {
+	return services.SessionMeta{UserAgent: r.UserAgent(), IP: clientIP(r)}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
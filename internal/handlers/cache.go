@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// writeCacheable отдаёт v в формате JSON с заголовками для кеширования в браузере и CDN:
+// ETag по содержимому ответа, Last-Modified и Cache-Control. Условные запросы
+// (If-None-Match, If-Modified-Since) и HEAD обрабатывает http.ServeContent и отвечает 304.
+func writeCacheable(w http.ResponseWriter, r *http.Request, logger *zap.Logger, v any, lastModified time.Time, maxAge time.Duration) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, r, logger, err, "Ошибка формирования ответа")
+		return
+	}
+	writeCacheableBody(w, r, "application/json", body, lastModified, maxAge)
+}
+
+// writeCacheableBody отдаёт готовое тело ответа с теми же заголовками кеширования, что и writeCacheable
+func writeCacheableBody(w http.ResponseWriter, r *http.Request, contentType string, body []byte, lastModified time.Time, maxAge time.Duration) {
+	sum := sha256.Sum256(body)
+	header := w.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("ETag", `"`+base64.RawURLEncoding.EncodeToString(sum[:16])+`"`)
+	header.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+
+	http.ServeContent(w, r, "", lastModified.UTC().Truncate(time.Second), bytes.NewReader(body))
+}
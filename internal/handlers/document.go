@@ -5,18 +5,21 @@ import (
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 	"net/http"
-	"path/filepath"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/models"
 	"rcoi/internal/services"
+	"rcoi/internal/validation"
 	"strconv"
 )
 
 type DocumentHandler struct {
-	service services.DocumentService
-	logger  *zap.Logger
+	service   services.DocumentService
+	validator *validation.Validator
+	logger    *zap.Logger
 }
 
-func NewDocumentHandler(service services.DocumentService, logger *zap.Logger) *DocumentHandler {
-	return &DocumentHandler{service: service, logger: logger}
+func NewDocumentHandler(service services.DocumentService, validator *validation.Validator, logger *zap.Logger) *DocumentHandler {
+	return &DocumentHandler{service: service, validator: validator, logger: logger}
 }
 
 // UploadDocument godoc
@@ -28,22 +31,29 @@ func NewDocumentHandler(service services.DocumentService, logger *zap.Logger) *D
 // @Param title formData string true "Название документа"
 // @Param file formData file true "Файл документа"
 // @Success 201 {object} object
-// @Failure 400 "Файл не найден"
-// @Failure 500 "Ошибка загрузки файла"
+// @Failure 400 {object} apperrors.Problem "Данные не прошли проверку или файл не передан"
+// @Failure 500 {object} apperrors.Problem "Ошибка загрузки файла"
 // @Router /api/documents [post]
 func (h *DocumentHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
-	title := r.FormValue("title")
+	input := models.DocumentInput{Title: r.FormValue("title")}
+
+	var missing []apperrors.FieldError
 	file, fileHeader, err := r.FormFile("file")
 	if err != nil {
-		http.Error(w, "Файл не найден", http.StatusBadRequest)
+		missing = append(missing, apperrors.FieldError{Field: "file", Message: "Обязательное поле"})
+	} else {
+		defer file.Close()
+		input.FileName = fileHeader.Filename
+	}
+
+	if err := h.validator.Validate(&input, missing...); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка загрузки файла")
 		return
 	}
-	defer file.Close()
 
-	doc, err := h.service.UploadDocument(r.Context(), title, file, fileHeader)
+	doc, err := h.service.UploadDocument(r.Context(), input.Title, file, fileHeader)
 	if err != nil {
-		h.logger.Error("Ошибка загрузки файла", zap.Error(err))
-		http.Error(w, "Ошибка загрузки файла", http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка загрузки файла")
 		return
 	}
 
@@ -56,13 +66,27 @@ func (h *DocumentHandler) UploadDocument(w http.ResponseWriter, r *http.Request)
 // @Description Возвращает список всех загруженных документов
 // @Tags documents
 // @Produce json
-// @Success 200 {array} object
-// @Failure 500 "Ошибка получения документов"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param offset query int false "Смещение (не используется вместе с cursor)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Поле сортировки, префикс '-' — по убыванию (по умолчанию -created_at)"
+// @Param created_from query string false "Дата создания с (RFC 3339 или YYYY-MM-DD)"
+// @Param created_to query string false "Дата создания по (RFC 3339 или YYYY-MM-DD)"
+// @Param title query string false "Подстрока названия"
+// @Success 200 {object} object{items=[]models.Document,total=int,limit=int,offset=int,next_cursor=string}
+// @Failure 400 {object} apperrors.Problem "Некорректные параметры списка"
+// @Failure 500 {object} apperrors.Problem "Ошибка получения документов"
 // @Router /api/documents [get]
 func (h *DocumentHandler) GetAllDocuments(w http.ResponseWriter, r *http.Request) {
-	docs, err := h.service.GetAllDocuments(r.Context())
+	q, err := parseListQuery(r, "title")
 	if err != nil {
-		http.Error(w, "Ошибка получения документов", http.StatusInternalServerError)
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	docs, err := h.service.GetAllDocuments(r.Context(), q)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения документов")
 		return
 	}
 
@@ -75,25 +99,30 @@ func (h *DocumentHandler) GetAllDocuments(w http.ResponseWriter, r *http.Request
 // @Tags documents
 // @Param id path int true "ID документа"
 // @Success 200 "Файл для скачивания"
-// @Failure 400 "Некорректный ID документа"
-// @Failure 404 "Документ не найден"
+// @Failure 400 {object} apperrors.Problem "Некорректный ID документа"
+// @Failure 404 {object} apperrors.Problem "Документ или его файл не найден"
+// @Failure 500 {object} apperrors.Problem "Ошибка чтения файла"
 // @Router /api/documents/{id} [get]
 func (h *DocumentHandler) DownloadDocument(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Некорректный ID документа", http.StatusBadRequest)
+		badRequest(w, r, "Некорректный ID документа")
 		return
 	}
 
 	doc, err := h.service.GetDocumentByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Документ не найден", http.StatusNotFound)
+		writeError(w, r, h.logger, err, "Ошибка получения документа")
+		return
+	}
+
+	file, info, err := h.service.OpenDocumentFile(r.Context(), doc)
+	if err != nil {
+		writeFileError(w, r, h.logger, err, "Файл документа не найден")
 		return
 	}
 
-	filePath := filepath.Join("uploads", doc.Filename)
-	w.Header().Set("Content-Disposition", "attachment; filename="+doc.Filename)
-	http.ServeFile(w, r, filePath)
+	serveStoredFile(w, r, doc.Filename, file, info)
 }
 
 // DeleteDocument godoc
@@ -102,20 +131,20 @@ func (h *DocumentHandler) DownloadDocument(w http.ResponseWriter, r *http.Reques
 // @Tags documents
 // @Param id path int true "ID документа"
 // @Success 204 "Документ удален"
-// @Failure 400 "Некорректный ID документа"
-// @Failure 500 "Ошибка удаления документа"
+// @Failure 400 {object} apperrors.Problem "Некорректный ID документа"
+// @Failure 404 {object} apperrors.Problem "Документ не найден"
+// @Failure 500 {object} apperrors.Problem "Ошибка удаления документа"
 // @Router /api/documents/{id} [delete]
 func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Некорректный ID документа", http.StatusBadRequest)
+		badRequest(w, r, "Некорректный ID документа")
 		return
 	}
 
 	err = h.service.DeleteDocument(r.Context(), id)
 	if err != nil {
-		h.logger.Error("Ошибка удаления документа", zap.Error(err))
-		http.Error(w, "Ошибка удаления документа", http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка удаления документа")
 		return
 	}
 
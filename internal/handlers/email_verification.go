@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/services"
+)
+
+type EmailVerificationHandler struct {
+	service services.EmailVerificationService
+	logger  *zap.Logger
+}
+
+func NewEmailVerificationHandler(service services.EmailVerificationService, logger *zap.Logger) *EmailVerificationHandler {
+	return &EmailVerificationHandler{service: service, logger: logger}
+}
+
+// VerifyEmail godoc
+// @Summary Подтверждение email
+// @Description Подтверждает email по одноразовому токену из письма
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body object{token=string} true "Токен подтверждения"
+// @Success 200 {object} object{message=string}
+// @Failure 400 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /verify-email [post]
+func (h *EmailVerificationHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token string `json:"token"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		apperrors.Write(w, r, apperrors.Validation("Токен обязателен", apperrors.FieldError{Field: "token", Message: "Обязательное поле"}))
+		return
+	}
+
+	if err := h.service.Verify(r.Context(), req.Token); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка подтверждения email")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Email успешно подтверждён",
+	})
+}
+
+// ResendVerification godoc
+// @Summary Повторная отправка письма подтверждения
+// @Description Ответ не зависит от того, зарегистрирован ли email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body object{email=string} true "Email пользователя"
+// @Success 202 {object} object{message=string}
+// @Failure 400 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /verify-email/resend [post]
+func (h *EmailVerificationHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		apperrors.Write(w, r, apperrors.Validation("Email обязателен", apperrors.FieldError{Field: "email", Message: "Обязательное поле"}))
+		return
+	}
+
+	if err := h.service.Resend(r.Context(), req.Email, clientIP(r)); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка отправки письма")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Если email зарегистрирован и не подтверждён, письмо отправлено повторно",
+	})
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/loginguard"
+	"rcoi/internal/models"
+	"rcoi/internal/passwordpolicy"
+	"rcoi/internal/repositories"
+	"rcoi/internal/services"
+	"rcoi/internal/storage"
+)
+
+// knownErrors — ошибки сервисов, которые показываются клиенту, и их машинные коды.
+// Клиент получает текст самой ошибки из таблицы: обёртки (например, ошибка PostgreSQL
+// с именем ограничения и значениями) остаются только в журнале.
+var knownErrors = []struct {
+	err  error
+	code apperrors.Code
+}{
+	{models.ErrInvalidListQuery, apperrors.CodeBadRequest},
+	{storage.ErrNotFound, apperrors.CodeNotFound},
+	{repositories.ErrNotFound, apperrors.CodeNotFound},
+	{repositories.ErrConflict, apperrors.CodeConflict},
+	{repositories.ErrForeignKey, apperrors.CodeConflict},
+	{repositories.ErrVersionMismatch, apperrors.CodePreconditionFailed},
+
+	{services.ErrApplicationNotFound, apperrors.CodeNotFound},
+	{services.ErrDocumentNotFound, apperrors.CodeNotFound},
+	{services.ErrNewsNotFound, apperrors.CodeNotFound},
+	{services.ErrInvalidNewsStatus, apperrors.CodeBadRequest},
+	{services.ErrInvalidNewsTransition, apperrors.CodeConflict},
+	{services.ErrNewsNotEditable, apperrors.CodeConflict},
+	{services.ErrInvalidPublishAt, apperrors.CodeValidation},
+	{services.ErrInvalidUnpublishAt, apperrors.CodeValidation},
+	{services.ErrVersionMismatch, apperrors.CodePreconditionFailed},
+
+	{services.ErrInvalidEmail, apperrors.CodeValidation},
+	{services.ErrEmailExists, apperrors.CodeAlreadyExists},
+	{services.ErrEmailNotVerified, apperrors.CodeEmailNotVerified},
+	{services.ErrAccountDisabled, apperrors.CodeAccountDisabled},
+	{services.ErrInvalidCredentials, apperrors.CodeInvalidCredentials},
+	{services.ErrInvalidRefreshToken, apperrors.CodeTokenInvalid},
+	{services.ErrRefreshTokenReuse, apperrors.CodeTokenRevoked},
+	{services.ErrInvalidMFAToken, apperrors.CodeTokenInvalid},
+	{services.ErrInvalidResetToken, apperrors.CodeBadRequest},
+	{services.ErrInvalidVerificationToken, apperrors.CodeBadRequest},
+	{services.ErrInvalidSSOState, apperrors.CodeBadRequest},
+	{services.ErrSSOEmailNotVerified, apperrors.CodeForbidden},
+	{services.ErrSSOUserNotProvisioned, apperrors.CodeForbidden},
+
+	{services.ErrUserNotFound, apperrors.CodeNotFound},
+	{services.ErrUserExists, apperrors.CodeAlreadyExists},
+	{services.ErrInvalidRole, apperrors.CodeValidation},
+	{services.ErrSelfModeration, apperrors.CodeForbidden},
+	{services.ErrSessionNotFound, apperrors.CodeNotFound},
+
+	{services.ErrRoleNotFound, apperrors.CodeNotFound},
+	{services.ErrRoleExists, apperrors.CodeAlreadyExists},
+	{services.ErrRoleInUse, apperrors.CodeConflict},
+	{services.ErrRoleProtected, apperrors.CodeForbidden},
+	{services.ErrRoleLockout, apperrors.CodeConflict},
+	{services.ErrUnknownPermission, apperrors.CodeValidation},
+
+	{services.ErrMFAAlreadyEnabled, apperrors.CodeConflict},
+	{services.ErrMFANotEnrolled, apperrors.CodeConflict},
+	{services.ErrMFANotEnabled, apperrors.CodeConflict},
+	{services.ErrInvalidMFACode, apperrors.CodeMFAInvalid},
+
+	{services.ErrAPIKeyNotFound, apperrors.CodeNotFound},
+	{services.ErrInvalidAPIKey, apperrors.CodeTokenInvalid},
+	{services.ErrAPIKeyNameRequired, apperrors.CodeValidation},
+	{services.ErrAPIKeyScopeRequired, apperrors.CodeValidation},
+	{services.ErrAPIKeyExpiresAt, apperrors.CodeValidation},
+	{services.ErrAPIKeyScopeDenied, apperrors.CodeForbidden},
+	{services.ErrAPIKeyScopeForbidden, apperrors.CodeValidation},
+
+	{services.ErrProfileFieldTooLong, apperrors.CodeValidation},
+	{services.ErrInvalidPhone, apperrors.CodeValidation},
+	{services.ErrInvalidAvatar, apperrors.CodeValidation},
+	{services.ErrAvatarTooLarge, apperrors.CodePayloadTooLarge},
+	{services.ErrAvatarNotFound, apperrors.CodeNotFound},
+	{services.ErrWrongPassword, apperrors.CodeForbidden},
+}
+
+// detailedErrors — ошибки, подробности которых составлены нами и полезны клиенту (например, какой параметр списка неверен)
+var detailedErrors = []error{models.ErrInvalidListQuery}
+
+// toAppError переводит ошибку сервиса в ошибку API; false — ошибка внутренняя и клиенту не показывается
+func toAppError(err error) (*apperrors.Error, bool) {
+	if appErr, ok := apperrors.As(err); ok {
+		return appErr, true
+	}
+
+	var violation *passwordpolicy.ViolationError
+	if errors.As(err, &violation) {
+		fields := make([]apperrors.FieldError, len(violation.Violations))
+		for i, v := range violation.Violations {
+			fields[i] = apperrors.FieldError{Field: "password", Message: v}
+		}
+		return &apperrors.Error{Code: apperrors.CodeWeakPassword, Message: "Пароль не соответствует политике", Fields: fields, Err: err}, true
+	}
+
+	var locked *loginguard.LockedError
+	if errors.As(err, &locked) {
+		return apperrors.Wrap(err, apperrors.CodeTooManyRequests, "Слишком много неудачных попыток, попробуйте позже"), true
+	}
+
+	for _, known := range knownErrors {
+		if errors.Is(err, known.err) {
+			message := known.err.Error()
+			if slices.Contains(detailedErrors, known.err) {
+				message = err.Error()
+			}
+			return apperrors.Wrap(err, known.code, message), true
+		}
+	}
+	return nil, false
+}
+
+// writeError отвечает ошибкой в формате application/problem+json.
+// Неизвестные ошибки журналируются, а клиент получает internal_error с сообщением internalMessage.
+func writeError(w http.ResponseWriter, r *http.Request, logger *zap.Logger, err error, internalMessage string) {
+	appErr, ok := toAppError(err)
+	if !ok {
+		logger.Error(internalMessage, zap.String("path", r.URL.Path), zap.Error(err))
+		appErr = apperrors.Wrap(err, apperrors.CodeInternal, internalMessage)
+	}
+
+	var locked *loginguard.LockedError
+	if errors.As(err, &locked) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+	}
+
+	apperrors.Write(w, r, appErr)
+}
+
+// requiredFields возвращает ошибки для незаполненных обязательных полей (имя поля → значение)
+func requiredFields(values map[string]string) []apperrors.FieldError {
+	var fields []apperrors.FieldError
+	for name, value := range values {
+		if strings.TrimSpace(value) == "" {
+			fields = append(fields, apperrors.FieldError{Field: name, Message: "Обязательное поле"})
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return fields
+}
+
+// badRequest отвечает 400 с кодом bad_request
+func badRequest(w http.ResponseWriter, r *http.Request, message string) {
+	apperrors.Write(w, r, apperrors.BadRequest(message))
+}
+
+// invalidJSON отвечает 400 на тело запроса, которое не удалось разобрать
+func invalidJSON(w http.ResponseWriter, r *http.Request) {
+	apperrors.Write(w, r, apperrors.New(apperrors.CodeInvalidJSON, "Неверный формат запроса"))
+}
+
+// unauthenticated отвечает 401, если в контексте нет пользователя (например, запрос по API-ключу)
+func unauthenticated(w http.ResponseWriter, r *http.Request) {
+	apperrors.Write(w, r, apperrors.Unauthorized("Пользователь не определён"))
+}
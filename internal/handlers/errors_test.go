@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+	"rcoi/internal/services"
+)
+
+// pgDetails — фрагменты ошибки PostgreSQL, которые не должны попасть в ответ
+var pgDetails = []string{"users_email_key", "a@example.com", "23505", "23503", "news_author_id_fkey", "SQLSTATE"}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) apperrors.Problem {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/problem+json") {
+		t.Fatalf("Content-Type = %q, ожидается application/problem+json", ct)
+	}
+	var problem apperrors.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("тело ответа не problem+json: %v", err)
+	}
+	return problem
+}
+
+func TestWriteErrorRepositoryErrors(t *testing.T) {
+	unique := &pgconn.PgError{
+		Code:           "23505",
+		Message:        `duplicate key value violates unique constraint "users_email_key"`,
+		Detail:         "Key (email)=(a@example.com) already exists.",
+		ConstraintName: "users_email_key",
+	}
+	foreignKey := &pgconn.PgError{
+		Code:           "23503",
+		Message:        `insert or update on table "news" violates foreign key constraint "news_author_id_fkey"`,
+		ConstraintName: "news_author_id_fkey",
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   apperrors.Code
+	}{
+		{"not found", repositories.ErrNotFound, http.StatusNotFound, apperrors.CodeNotFound},
+		{"unique violation", wrapDB(repositories.ErrConflict, unique), http.StatusConflict, apperrors.CodeConflict},
+		{"foreign key violation", wrapDB(repositories.ErrForeignKey, foreignKey), http.StatusConflict, apperrors.CodeConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+
+			writeError(w, r, zap.NewNop(), tt.err, "Внутренняя ошибка")
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if problem := decodeProblem(t, w); problem.Code != tt.wantCode {
+				t.Fatalf("code = %q, want %q", problem.Code, tt.wantCode)
+			}
+			for _, detail := range pgDetails {
+				if strings.Contains(w.Body.String(), detail) {
+					t.Fatalf("ответ содержит подробности PostgreSQL %q: %s", detail, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteErrorListQueryKeepsDetails(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/news", nil)
+	err := wrapDB(models.ErrInvalidListQuery, errString("сортировка по полю \"x\" не поддерживается"))
+
+	writeError(w, r, zap.NewNop(), err, "Внутренняя ошибка")
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(decodeProblem(t, w).Detail, "сортировка по полю") {
+		t.Fatalf("ответ = %d %s", w.Code, w.Body.String())
+	}
+}
+
+// fakeNewsService возвращает err из любого метода; неиспользуемые методы не реализованы
+type fakeNewsService struct {
+	services.NewsService
+	err error
+}
+
+func (s *fakeNewsService) GetNewsByID(context.Context, int, bool) (*models.News, error) {
+	return nil, s.err
+}
+
+func (s *fakeNewsService) CreateNews(context.Context, *models.News) error {
+	return s.err
+}
+
+func TestNewsHandlerRepositoryErrors(t *testing.T) {
+	conflict := wrapDB(repositories.ErrConflict, &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"})
+
+	t.Run("get not found", func(t *testing.T) {
+		h := NewNewsHandler(&fakeNewsService{err: services.ErrNewsNotFound}, nil, zap.NewNop())
+		r := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/news/1", nil), map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+
+		h.GetNewsByID(w, r)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want 404", w.Code)
+		}
+	})
+
+	t.Run("create conflict", func(t *testing.T) {
+		h := NewNewsHandler(&fakeNewsService{err: conflict}, newTestValidator(), zap.NewNop())
+		body := strings.NewReader(`{"title":"Новость","content":"Текст"}`)
+		w := httptest.NewRecorder()
+
+		h.CreateNews(w, httptest.NewRequest(http.MethodPost, "/api/news", body))
+
+		if w.Code != http.StatusConflict {
+			t.Fatalf("status = %d, want 409", w.Code)
+		}
+		if strings.Contains(w.Body.String(), "users_email_key") {
+			t.Fatalf("ответ содержит имя ограничения: %s", w.Body.String())
+		}
+	})
+}
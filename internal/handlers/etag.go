@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"rcoi/internal/apperrors"
+	"rcoi/internal/mergepatch"
+)
+
+// maxPatchSize — максимальный размер тела PATCH-запроса
+const maxPatchSize = 1 << 20
+
+// versionETag — сильный ETag записи по её версии
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// checkIfMatch проверяет заголовок If-Match по текущей версии записи и при несовпадении
+// сам пишет ответ: 428 без заголовка, 412 если клиент правил устаревшую версию
+func checkIfMatch(w http.ResponseWriter, r *http.Request, version int) bool {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		apperrors.Write(w, r, apperrors.New(apperrors.CodePreconditionRequired,
+			"Передайте заголовок If-Match со значением ETag записи"))
+		return false
+	}
+
+	current := versionETag(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		// Слабые ETag для изменений не подходят (RFC 9110, 13.1.1)
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+
+	w.Header().Set("ETag", current)
+	apperrors.Write(w, r, apperrors.New(apperrors.CodePreconditionFailed,
+		"Запись уже изменена другим пользователем, загрузите её заново"))
+	return false
+}
+
+// readMergePatch читает тело PATCH-запроса в формате JSON Merge Patch
+func readMergePatch(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != mergepatch.ContentType && mediaType != "application/json" {
+		badRequest(w, r, "Ожидается тело "+mergepatch.ContentType)
+		return nil, false
+	}
+
+	patch, err := io.ReadAll(io.LimitReader(r.Body, maxPatchSize+1))
+	if err != nil || len(patch) > maxPatchSize || !json.Valid(patch) {
+		invalidJSON(w, r)
+		return nil, false
+	}
+	return patch, true
+}
+
+// applyMergePatch применяет patch к current и возвращает новый DTO
+func applyMergePatch[T any](current T, patch []byte) (T, error) {
+	var result T
+	target, err := json.Marshal(current)
+	if err != nil {
+		return result, err
+	}
+	merged, err := mergepatch.Apply(target, patch)
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal(merged, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/feed"
+	"rcoi/internal/services"
+)
+
+// FeedHandler отдаёт ленты новостей RSS и Atom без аутентификации
+type FeedHandler struct {
+	service services.FeedService
+	maxAge  time.Duration
+	logger  *zap.Logger
+}
+
+// NewFeedHandler создаёт обработчик; maxAge — срок кеширования лент в Cache-Control
+func NewFeedHandler(service services.FeedService, maxAge time.Duration, logger *zap.Logger) *FeedHandler {
+	return &FeedHandler{service: service, maxAge: maxAge, logger: logger}
+}
+
+// GetNewsRSS godoc
+// @Summary Лента новостей RSS 2.0
+// @Description Последние опубликованные новости. Поддерживает If-None-Match и If-Modified-Since (ответ 304).
+// @Description У новостей нет тегов и рубрик, поэтому лента одна; параметры tag и category отклоняются.
+// @Tags feeds
+// @Produce application/rss+xml
+// @Success 200 {string} string "Лента RSS"
+// @Success 304 "Лента не изменилась"
+// @Header 200 {string} ETag "Версия ленты"
+// @Header 200 {string} Last-Modified "Время последнего изменения новостей"
+// @Failure 400 {object} apperrors.Problem "Фильтр по тегу или рубрике не поддерживается"
+// @Failure 500 {object} apperrors.Problem "Ошибка формирования ленты"
+// @Router /feeds/news.rss [get]
+func (h *FeedHandler) GetNewsRSS(w http.ResponseWriter, r *http.Request) {
+	h.serveNewsFeed(w, r, feed.RSS)
+}
+
+// GetNewsAtom godoc
+// @Summary Лента новостей Atom 1.0
+// @Description Последние опубликованные новости. Поддерживает If-None-Match и If-Modified-Since (ответ 304).
+// @Description У новостей нет тегов и рубрик, поэтому лента одна; параметры tag и category отклоняются.
+// @Tags feeds
+// @Produce application/atom+xml
+// @Success 200 {string} string "Лента Atom"
+// @Success 304 "Лента не изменилась"
+// @Header 200 {string} ETag "Версия ленты"
+// @Header 200 {string} Last-Modified "Время последнего изменения новостей"
+// @Failure 400 {object} apperrors.Problem "Фильтр по тегу или рубрике не поддерживается"
+// @Failure 500 {object} apperrors.Problem "Ошибка формирования ленты"
+// @Router /feeds/news.atom [get]
+func (h *FeedHandler) GetNewsAtom(w http.ResponseWriter, r *http.Request) {
+	h.serveNewsFeed(w, r, feed.Atom)
+}
+
+func (h *FeedHandler) serveNewsFeed(w http.ResponseWriter, r *http.Request, format feed.Format) {
+	// Без явного отказа читатель, подписанный на «ленту тега», молча получал бы все новости
+	for _, param := range []string{"tag", "category"} {
+		if r.URL.Query().Has(param) {
+			apperrors.Write(w, r, apperrors.Validation("Ленты по тегам и рубрикам не поддерживаются",
+				apperrors.FieldError{Field: param, Message: "Неизвестный параметр"}))
+			return
+		}
+	}
+
+	body, lastModified, err := h.service.NewsFeed(r.Context(), format)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка формирования ленты новостей")
+		return
+	}
+
+	writeCacheableBody(w, r, format.ContentType(), body, lastModified, h.maxAge)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/internal/feed"
+	"rcoi/internal/services"
+)
+
+type fakeFeedService struct {
+	services.FeedService
+	calls int
+}
+
+func (f *fakeFeedService) NewsFeed(ctx context.Context, format feed.Format) ([]byte, time.Time, error) {
+	f.calls++
+	return []byte("<rss/>"), time.Now(), nil
+}
+
+func TestNewsFeedRejectsFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{"без фильтра", "/feeds/news.rss", http.StatusOK},
+		{"по тегу", "/feeds/news.rss?tag=olympiad", http.StatusBadRequest},
+		{"по рубрике", "/feeds/news.rss?category=ege", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeFeedService{}
+			h := NewFeedHandler(service, time.Minute, zap.NewNop())
+
+			w := httptest.NewRecorder()
+			h.GetNewsRSS(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusBadRequest {
+				decodeProblem(t, w)
+				if service.calls != 0 {
+					t.Fatal("лента построена для неподдерживаемого фильтра")
+				}
+			}
+		})
+	}
+}
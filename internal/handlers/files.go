@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/storage"
+)
+
+// writeFileError отвечает на ошибку открытия файла из хранилища: 404, если объекта нет,
+// иначе — 500 (хранилище недоступно, нет прав и т.п.)
+func writeFileError(w http.ResponseWriter, r *http.Request, logger *zap.Logger, err error, notFoundMessage string) {
+	if errors.Is(err, storage.ErrNotFound) {
+		logger.Warn(notFoundMessage, zap.String("path", r.URL.Path))
+		apperrors.Write(w, r, apperrors.NotFound(notFoundMessage))
+		return
+	}
+	writeError(w, r, logger, err, "Ошибка чтения файла")
+}
+
+// serveStoredFile отдаёт файл из хранилища как вложение
+func serveStoredFile(w http.ResponseWriter, r *http.Request, name string, rc io.ReadCloser, info *storage.ObjectInfo) {
+	serveStoredObject(w, r, "attachment", name, rc, info)
+}
+
+// serveStoredObject отдаёт файл из хранилища; disposition — attachment или inline (например, для изображений)
+func serveStoredObject(w http.ResponseWriter, r *http.Request, disposition, name string, rc io.ReadCloser, info *storage.ObjectInfo) {
+	defer rc.Close()
+
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": name}))
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+
+	// Локальные файлы и объекты S3 поддерживают Seek — тогда работают Range и If-Modified-Since
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, name, info.ModTime, rs)
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	if !info.ModTime.IsZero() {
+		w.Header().Set("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+	}
+	io.Copy(w, rc)
+}
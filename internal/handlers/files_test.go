@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"rcoi/internal/models"
+	"rcoi/internal/services"
+	"rcoi/internal/storage"
+)
+
+type fakeDocumentService struct {
+	services.DocumentService
+	openErr error
+}
+
+func (f *fakeDocumentService) GetDocumentByID(ctx context.Context, id int) (*models.Document, error) {
+	return &models.Document{ID: id, Title: "Отчёт"}, nil
+}
+
+func (f *fakeDocumentService) OpenDocumentFile(ctx context.Context, doc *models.Document) (io.ReadCloser, *storage.ObjectInfo, error) {
+	return nil, nil, f.openErr
+}
+
+func TestDownloadDocumentStorageErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"объекта нет", storage.ErrNotFound, http.StatusNotFound},
+		{"обёрнутое отсутствие объекта", errors.Join(errors.New("minio"), storage.ErrNotFound), http.StatusNotFound},
+		{"хранилище недоступно", errors.New("dial tcp 10.0.0.5:9000: connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewDocumentHandler(&fakeDocumentService{openErr: tt.err}, newTestValidator(), zap.NewNop())
+			router := mux.NewRouter()
+			router.HandleFunc("/documents/{id}/download", h.DownloadDocument)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/documents/1/download", nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			problem := decodeProblem(t, w)
+			if strings.Contains(problem.Detail, "connection refused") {
+				t.Fatalf("ответ раскрывает ошибку хранилища: %q", problem.Detail)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"fmt"
+
+	"rcoi/config"
+	"rcoi/internal/validation"
+)
+
+type errString string
+
+func (e errString) Error() string { return string(e) }
+
+// wrapDB повторяет обёртку repositories.dbError: ошибка пакета и исходная причина
+func wrapDB(sentinel, cause error) error {
+	return fmt.Errorf("%w: %w", sentinel, cause)
+}
+
+func newTestValidator() *validation.Validator {
+	return validation.New(config.ValidationConfig{URLSchemes: []string{"https", "http"}})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"rcoi/internal/jwtkeys"
+)
+
+type JWKSHandler struct {
+	keys *jwtkeys.KeySet
+}
+
+func NewJWKSHandler(keys *jwtkeys.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS godoc
+// @Summary Открытые ключи подписи JWT
+// @Description Возвращает JWKS для проверки токенов rcoi другими сервисами
+// @Tags auth
+// @Produce json
+// @Success 200 {object} jwtkeys.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	// Короткое кеширование: после ротации новый kid должен появиться у клиентов быстро
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.keys.JWKS())
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rcoi/internal/models"
+)
+
+// searchParams — параметры поиска по подстроке; у каждого списка свой
+var searchParams = []string{"title", "email"}
+
+// parseListQuery разбирает общие параметры списков:
+// limit, offset, cursor, sort (например, "-created_at"), created_from, created_to
+// и параметр поиска searchParam. Чужой параметр поиска отклоняется, а не игнорируется:
+// иначе ?title= в списке пользователей молча искал бы по email.
+func parseListQuery(r *http.Request, searchParam string) (models.ListQuery, error) {
+	values := r.URL.Query()
+	var q models.ListQuery
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return q, errors.New("некорректный параметр limit")
+		}
+		q.Limit = min(limit, models.MaxListLimit)
+	}
+
+	if v := values.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return q, errors.New("некорректный параметр offset")
+		}
+		q.Offset = offset
+	}
+
+	q.Cursor = values.Get("cursor")
+	if q.Cursor != "" && q.Offset > 0 {
+		return q, errors.New("параметры cursor и offset нельзя использовать вместе")
+	}
+
+	if v := values.Get("sort"); v != "" {
+		q.Desc = strings.HasPrefix(v, "-")
+		q.Sort = strings.TrimPrefix(v, "-")
+	}
+
+	if v := values.Get("created_from"); v != "" {
+		from, _, err := parseDateParam(v)
+		if err != nil {
+			return q, errors.New("некорректный параметр created_from")
+		}
+		q.CreatedFrom = &from
+	}
+
+	if v := values.Get("created_to"); v != "" {
+		to, dateOnly, err := parseDateParam(v)
+		if err != nil {
+			return q, errors.New("некорректный параметр created_to")
+		}
+		// Дата без времени включает весь указанный день
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		q.CreatedTo = &to
+	}
+
+	for _, param := range searchParams {
+		if param != searchParam && values.Has(param) {
+			return q, fmt.Errorf("параметр %s не поддерживается, поиск — по параметру %s", param, searchParam)
+		}
+	}
+	q.Search = strings.TrimSpace(values.Get(searchParam))
+
+	return q, nil
+}
+
+// parseDateParam принимает дату в формате RFC 3339 или YYYY-MM-DD
+func parseDateParam(v string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), false, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	return t, true, err
+}
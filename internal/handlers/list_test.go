@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseListQuerySearch(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		searchParam string
+		want        string
+		wantErr     bool
+	}{
+		{"title у новостей", "/news?title=+ЕГЭ+", "title", "ЕГЭ", false},
+		{"email у пользователей", "/users?email=ivanov", "email", "ivanov", false},
+		{"title у пользователей", "/users?title=ivanov", "email", "", true},
+		{"email у новостей", "/news?email=ivanov", "title", "", true},
+		{"без поиска", "/users?limit=5", "email", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := parseListQuery(httptest.NewRequest(http.MethodGet, tt.target, nil), tt.searchParam)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if q.Search != tt.want {
+				t.Fatalf("Search = %q, want %q", q.Search, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/middleware"
+	"rcoi/internal/services"
+)
+
+type MFAHandler struct {
+	service services.MFAService
+	logger  *zap.Logger
+}
+
+func NewMFAHandler(service services.MFAService, logger *zap.Logger) *MFAHandler {
+	return &MFAHandler{service: service, logger: logger}
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// GetStatus godoc
+// @Summary Состояние двухфакторной аутентификации
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} models.MFAStatus
+// @Failure 401 {object} apperrors.Problem "Пользователь не определён"
+// @Router /api/mfa [get]
+func (h *MFAHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+
+	status, err := h.service.Status(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка двухфакторной аутентификации")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// EnrollTOTP godoc
+// @Summary Начало подключения TOTP
+// @Description Создаёт секрет и возвращает его вместе с otpauth URI и QR-кодом (PNG в base64).
+// @Description TOTP включается после подтверждения кодом из приложения
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} services.TOTPEnrollment
+// @Failure 401 {object} apperrors.Problem "Пользователь не определён"
+// @Failure 409 {object} apperrors.Problem "Двухфакторная аутентификация уже подключена"
+// @Router /api/mfa/totp [post]
+func (h *MFAHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+
+	enrollment, err := h.service.BeginEnrollment(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка двухфакторной аутентификации")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+// ConfirmTOTP godoc
+// @Summary Подтверждение подключения TOTP
+// @Description Включает TOTP по коду из приложения и возвращает одноразовые коды восстановления (показываются один раз).
+// @Description Чтобы получить права, требующие 2FA, обновите токен через /refresh
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body object{code=string} true "Код из приложения"
+// @Success 200 {object} object{recovery_codes=[]string}
+// @Failure 400 {object} apperrors.Problem "Неверный код подтверждения"
+// @Failure 409 {object} apperrors.Problem "Двухфакторная аутентификация уже подключена"
+// @Router /api/mfa/totp/confirm [post]
+func (h *MFAHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		apperrors.Write(w, r, apperrors.Validation("Требуется код подтверждения", apperrors.FieldError{Field: "code", Message: "Обязательное поле"}))
+		return
+	}
+
+	codes, err := h.service.ConfirmEnrollment(r.Context(), userID, req.Code)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка двухфакторной аутентификации")
+		return
+	}
+
+	h.writeRecoveryCodes(w, codes)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Новые коды восстановления
+// @Description Заменяет все коды восстановления новыми; требует действующий код второго фактора
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body object{code=string} true "TOTP-код или код восстановления"
+// @Success 200 {object} object{recovery_codes=[]string}
+// @Failure 400 {object} apperrors.Problem "Неверный код подтверждения"
+// @Router /api/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		apperrors.Write(w, r, apperrors.Validation("Требуется код подтверждения", apperrors.FieldError{Field: "code", Message: "Обязательное поле"}))
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка двухфакторной аутентификации")
+		return
+	}
+
+	h.writeRecoveryCodes(w, codes)
+}
+
+// DisableTOTP godoc
+// @Summary Отключение TOTP
+// @Tags mfa
+// @Accept json
+// @Param request body object{code=string} true "TOTP-код или код восстановления"
+// @Success 204 "Двухфакторная аутентификация отключена"
+// @Failure 400 {object} apperrors.Problem "Неверный код подтверждения"
+// @Router /api/mfa/totp/disable [post]
+func (h *MFAHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		apperrors.Write(w, r, apperrors.Validation("Требуется код подтверждения", apperrors.FieldError{Field: "code", Message: "Обязательное поле"}))
+		return
+	}
+
+	if err := h.service.Disable(r.Context(), userID, req.Code); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка двухфакторной аутентификации")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResetUserMFA godoc
+// @Summary Сброс двухфакторной аутентификации пользователя
+// @Description Отключает TOTP и коды восстановления пользователя, потерявшего устройство
+// @Tags admin
+// @Param id path int true "ID пользователя"
+// @Success 204 "Двухфакторная аутентификация сброшена"
+// @Failure 404 {object} apperrors.Problem "Пользователь не найден"
+// @Router /api/admin/users/{id}/mfa/reset [post]
+func (h *MFAHandler) ResetUserMFA(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	actor, _ := middleware.GetEmailFromContext(r.Context())
+	if err := h.service.Reset(r.Context(), actor, id); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка двухфакторной аутентификации")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *MFAHandler) writeRecoveryCodes(w http.ResponseWriter, codes []string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string][]string{"recovery_codes": codes})
+}
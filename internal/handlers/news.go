@@ -3,43 +3,62 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/middleware"
 	"rcoi/internal/models"
 	"rcoi/internal/services"
+	"rcoi/internal/validation"
 )
 
 type NewsHandler struct {
-	service services.NewsService
-	logger  *zap.Logger
+	service   services.NewsService
+	validator *validation.Validator
+	logger    *zap.Logger
 }
 
-func NewNewsHandler(service services.NewsService, logger *zap.Logger) *NewsHandler {
-	return &NewsHandler{service: service, logger: logger}
+func NewNewsHandler(service services.NewsService, validator *validation.Validator, logger *zap.Logger) *NewsHandler {
+	return &NewsHandler{service: service, validator: validator, logger: logger}
+}
+
+// canSeeUnpublished — редакторы и рецензенты видят новости в любом статусе, остальные — только опубликованные
+func canSeeUnpublished(r *http.Request) bool {
+	permissions, _ := middleware.GetPermissionsFromContext(r.Context())
+	return slices.Contains(permissions, models.PermNewsWrite) || slices.Contains(permissions, models.PermNewsPublish)
 }
 
 // CreateNews godoc
 // @Summary Создание новости
-// @Description Создаёт новую новость
+// @Description Создаёт черновик новости; автором становится текущий пользователь
 // @Tags news
 // @Accept json
 // @Produce json
-// @Param news body models.News true "Данные новости"
+// @Param news body models.NewsInput true "Данные новости"
 // @Success 201 {object} models.News
-// @Failure 400 "Неверный формат запроса"
-// @Failure 500 "Ошибка создания новости"
+// @Failure 400 {object} apperrors.Problem "Неверный формат запроса или данные не прошли проверку"
+// @Failure 500 {object} apperrors.Problem "Ошибка создания новости"
 // @Router /api/news [post]
 func (h *NewsHandler) CreateNews(w http.ResponseWriter, r *http.Request) {
-	var news models.News
-	if err := json.NewDecoder(r.Body).Decode(&news); err != nil {
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+	var input models.NewsInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		invalidJSON(w, r)
+		return
+	}
+	if err := h.validator.Validate(&input); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка создания новости")
 		return
 	}
 
+	news := models.News{Title: input.Title, Content: input.Content}
+	if userID, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		news.AuthorID = &userID
+	}
 	if err := h.service.CreateNews(r.Context(), &news); err != nil {
-		http.Error(w, "Ошибка создания новости", http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка создания новости")
 		return
 	}
 
@@ -49,42 +68,63 @@ func (h *NewsHandler) CreateNews(w http.ResponseWriter, r *http.Request) {
 
 // GetNewsByID godoc
 // @Summary Получение новости по ID
-// @Description Возвращает новость по указанному ID
+// @Description Возвращает новость по указанному ID; без прав редактора — только опубликованную
 // @Tags news
 // @Produce json
 // @Param id path int true "ID новости"
 // @Success 200 {object} models.News
-// @Failure 400 "Некорректный ID"
-// @Failure 404 "Новость не найдена"
+// @Header 200 {string} ETag "Версия новости для If-Match"
+// @Failure 400 {object} apperrors.Problem "Некорректный ID"
+// @Failure 404 {object} apperrors.Problem "Новость не найдена"
 // @Router /api/news/{id} [get]
 func (h *NewsHandler) GetNewsByID(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Некорректный ID", http.StatusBadRequest)
+		badRequest(w, r, "Некорректный ID")
 		return
 	}
 
-	news, err := h.service.GetNewsByID(r.Context(), id)
+	news, err := h.service.GetNewsByID(r.Context(), id, !canSeeUnpublished(r))
 	if err != nil {
-		http.Error(w, "Новость не найдена", http.StatusNotFound)
+		writeError(w, r, h.logger, err, "Ошибка получения новости")
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(news.Version))
 	json.NewEncoder(w).Encode(news)
 }
 
 // GetAllNews godoc
 // @Summary Получение списка всех новостей
-// @Description Возвращает список всех новостей
+// @Description Возвращает список новостей; без прав редактора — только опубликованные
 // @Tags news
 // @Produce json
-// @Success 200 {array} models.News
-// @Failure 500 "Ошибка получения новостей"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param offset query int false "Смещение (не используется вместе с cursor)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Поле сортировки, префикс '-' — по убыванию (по умолчанию -created_at)"
+// @Param created_from query string false "Дата создания с (RFC 3339 или YYYY-MM-DD)"
+// @Param created_to query string false "Дата создания по (RFC 3339 или YYYY-MM-DD)"
+// @Param title query string false "Подстрока названия"
+// @Param status query string false "Статус новости (только для редакторов)" Enums(draft, in_review, scheduled, published, archived)
+// @Success 200 {object} object{items=[]models.News,total=int,limit=int,offset=int,next_cursor=string}
+// @Failure 400 {object} apperrors.Problem "Некорректные параметры списка"
+// @Failure 500 {object} apperrors.Problem "Ошибка получения новостей"
 // @Router /api/news [get]
 func (h *NewsHandler) GetAllNews(w http.ResponseWriter, r *http.Request) {
-	newsList, err := h.service.GetAllNews(r.Context())
+	q, err := parseListQuery(r, "title")
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	filter := models.NewsFilter{
+		PublishedOnly: !canSeeUnpublished(r),
+		Status:        models.NewsStatus(r.URL.Query().Get("status")),
+	}
+	newsList, err := h.service.GetAllNews(r.Context(), q, filter)
 	if err != nil {
-		http.Error(w, "Ошибка получения новостей", http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка получения новостей")
 		return
 	}
 
@@ -93,56 +133,212 @@ func (h *NewsHandler) GetAllNews(w http.ResponseWriter, r *http.Request) {
 
 // UpdateNews godoc
 // @Summary Обновление новости по ID
-// @Description Обновляет новость по указанному ID
+// @Description Заменяет все поля новости; требует If-Match с ETag текущей версии.
+// @Description Изменять можно только черновик или новость на рецензии
 // @Tags news
 // @Accept json
 // @Produce json
 // @Param id path int true "ID новости"
-// @Param news body models.News true "Обновляемые данные новости"
+// @Param If-Match header string true "ETag текущей версии новости"
+// @Param news body models.NewsInput true "Обновляемые данные новости"
 // @Success 200 {object} models.News
-// @Failure 400 "Некорректный ID или неверный формат запроса"
-// @Failure 500 "Ошибка обновления новости"
+// @Header 200 {string} ETag "Новая версия новости"
+// @Failure 400 {object} apperrors.Problem "Некорректный ID, неверный формат запроса или данные не прошли проверку"
+// @Failure 404 {object} apperrors.Problem "Новость не найдена"
+// @Failure 409 {object} apperrors.Problem "Новость опубликована, запланирована или в архиве"
+// @Failure 412 {object} apperrors.Problem "Запись изменена другим пользователем"
+// @Failure 428 {object} apperrors.Problem "Не передан заголовок If-Match"
+// @Failure 500 {object} apperrors.Problem "Ошибка обновления новости"
 // @Router /api/news/{id} [put]
 func (h *NewsHandler) UpdateNews(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Некорректный ID", http.StatusBadRequest)
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	current, err := h.service.GetNewsByID(r.Context(), id, false)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обновления новости")
+		return
+	}
+	if !checkIfMatch(w, r, current.Version) {
+		return
+	}
+
+	var input models.NewsInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		invalidJSON(w, r)
+		return
+	}
+	h.saveNews(w, r, current, input)
+}
+
+// PatchNews godoc
+// @Summary Частичное обновление новости
+// @Description Меняет только переданные поля (JSON Merge Patch, RFC 7386); требует If-Match с ETag текущей версии.
+// @Description Изменять можно только черновик или новость на рецензии
+// @Tags news
+// @Accept application/merge-patch+json
+// @Accept json
+// @Produce json
+// @Param id path int true "ID новости"
+// @Param If-Match header string true "ETag текущей версии новости"
+// @Param news body models.NewsInput true "Изменяемые поля новости"
+// @Success 200 {object} models.News
+// @Header 200 {string} ETag "Новая версия новости"
+// @Failure 400 {object} apperrors.Problem "Некорректный ID, неверный формат запроса или данные не прошли проверку"
+// @Failure 404 {object} apperrors.Problem "Новость не найдена"
+// @Failure 409 {object} apperrors.Problem "Новость опубликована, запланирована или в архиве"
+// @Failure 412 {object} apperrors.Problem "Запись изменена другим пользователем"
+// @Failure 428 {object} apperrors.Problem "Не передан заголовок If-Match"
+// @Failure 500 {object} apperrors.Problem "Ошибка обновления новости"
+// @Router /api/news/{id} [patch]
+func (h *NewsHandler) PatchNews(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	current, err := h.service.GetNewsByID(r.Context(), id, false)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обновления новости")
+		return
+	}
+	if !checkIfMatch(w, r, current.Version) {
+		return
+	}
+
+	patch, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+	input, err := applyMergePatch(models.NewsInput{Title: current.Title, Content: current.Content}, patch)
+	if err != nil {
+		invalidJSON(w, r)
 		return
 	}
+	h.saveNews(w, r, current, input)
+}
 
-	var news models.News
-	if err := json.NewDecoder(r.Body).Decode(&news); err != nil {
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+// saveNews проверяет input и сохраняет его поверх версии current
+func (h *NewsHandler) saveNews(w http.ResponseWriter, r *http.Request, current *models.News, input models.NewsInput) {
+	if err := h.validator.Validate(&input); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обновления новости")
 		return
 	}
-	news.ID = id
 
+	news := models.News{ID: current.ID, Title: input.Title, Content: input.Content, Status: current.Status, Version: current.Version}
 	if err := h.service.UpdateNews(r.Context(), &news); err != nil {
-		http.Error(w, "Ошибка обновления новости", http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка обновления новости")
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(news.Version))
 	json.NewEncoder(w).Encode(news)
 }
 
+// TransitionNews godoc
+// @Summary Смена статуса новости
+// @Description Переводит новость по этапам публикации: draft → in_review → scheduled/published → archived.
+// @Description Статусы scheduled, published и archived выставляет рецензент с правом news:publish.
+// @Description Требует If-Match с ETag текущей версии.
+// @Tags news
+// @Accept json
+// @Produce json
+// @Param id path int true "ID новости"
+// @Param If-Match header string true "ETag текущей версии новости"
+// @Param transition body models.NewsTransition true "Новый статус и время публикации"
+// @Success 200 {object} models.News
+// @Header 200 {string} ETag "Новая версия новости"
+// @Failure 400 {object} apperrors.Problem "Некорректный ID, неверный формат запроса или данные не прошли проверку"
+// @Failure 403 {object} apperrors.Problem "Недостаточно прав для этого статуса"
+// @Failure 404 {object} apperrors.Problem "Новость не найдена"
+// @Failure 409 {object} apperrors.Problem "Недопустимая смена статуса"
+// @Failure 412 {object} apperrors.Problem "Запись изменена другим пользователем"
+// @Failure 428 {object} apperrors.Problem "Не передан заголовок If-Match"
+// @Failure 500 {object} apperrors.Problem "Ошибка смены статуса новости"
+// @Router /api/news/{id}/status [post]
+func (h *NewsHandler) TransitionNews(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	var change models.NewsTransition
+	if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+		invalidJSON(w, r)
+		return
+	}
+	if err := h.validator.Validate(&change); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка смены статуса новости")
+		return
+	}
+
+	required := models.PermNewsWrite
+	if change.Status.NeedsReviewer() {
+		required = models.PermNewsPublish
+	}
+	if permissions, _ := middleware.GetPermissionsFromContext(r.Context()); !slices.Contains(permissions, required) {
+		apperrors.Write(w, r, apperrors.New(apperrors.CodeForbidden, "Доступ запрещён"))
+		return
+	}
+
+	current, err := h.service.GetNewsByID(r.Context(), id, false)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка смены статуса новости")
+		return
+	}
+	if !checkIfMatch(w, r, current.Version) {
+		return
+	}
+
+	var reviewerID *int
+	if userID, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		reviewerID = &userID
+	}
+	if err := h.service.TransitionNews(r.Context(), current, change, reviewerID); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка смены статуса новости")
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(current.Version))
+	json.NewEncoder(w).Encode(current)
+}
+
 // DeleteNews godoc
 // @Summary Удаление новости по ID
-// @Description Удаляет новость по указанному ID
+// @Description Удаляет новость по указанному ID; требует If-Match с ETag текущей версии
 // @Tags news
 // @Param id path int true "ID новости"
+// @Param If-Match header string true "ETag текущей версии новости"
 // @Success 204 "Новость удалена"
-// @Failure 400 "Некорректный ID"
-// @Failure 500 "Ошибка удаления новости"
+// @Failure 400 {object} apperrors.Problem "Некорректный ID"
+// @Failure 404 {object} apperrors.Problem "Новость не найдена"
+// @Failure 412 {object} apperrors.Problem "Запись изменена другим пользователем"
+// @Failure 428 {object} apperrors.Problem "Не передан заголовок If-Match"
+// @Failure 500 {object} apperrors.Problem "Ошибка удаления новости"
 // @Router /api/news/{id} [delete]
 func (h *NewsHandler) DeleteNews(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Некорректный ID", http.StatusBadRequest)
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	current, err := h.service.GetNewsByID(r.Context(), id, false)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка удаления новости")
+		return
+	}
+	if !checkIfMatch(w, r, current.Version) {
 		return
 	}
 
-	if err := h.service.DeleteNews(r.Context(), id); err != nil {
-		http.Error(w, "Ошибка удаления новости", http.StatusInternalServerError)
+	if err := h.service.DeleteNews(r.Context(), id, current.Version); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка удаления новости")
 		return
 	}
 
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+	"rcoi/internal/services"
+)
+
+// fakeNewsRepo хранит одну новость; обработчик проверяется вместе с настоящим NewsService
+type fakeNewsRepo struct {
+	repositories.NewsRepository
+	news    models.News
+	updated bool
+}
+
+func (f *fakeNewsRepo) GetByID(ctx context.Context, id int) (*models.News, error) {
+	news := f.news
+	return &news, nil
+}
+
+func (f *fakeNewsRepo) Update(ctx context.Context, news *models.News) error {
+	f.updated = true
+	news.Version++
+	return nil
+}
+
+func TestUpdateNewsOnlyBeforePublication(t *testing.T) {
+	tests := []struct {
+		status models.NewsStatus
+		want   int
+	}{
+		{models.NewsDraft, http.StatusOK},
+		{models.NewsInReview, http.StatusOK},
+		{models.NewsScheduled, http.StatusConflict},
+		{models.NewsPublished, http.StatusConflict},
+		{models.NewsArchived, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		for _, method := range []string{http.MethodPut, http.MethodPatch} {
+			t.Run(string(tt.status)+" "+method, func(t *testing.T) {
+				repo := &fakeNewsRepo{news: models.News{ID: 1, Title: "Новость", Content: "Текст", Status: tt.status, Version: 2}}
+				h := NewNewsHandler(services.NewNewsService(repo, zap.NewNop()), newTestValidator(), zap.NewNop())
+				router := mux.NewRouter()
+				router.HandleFunc("/api/news/{id}", h.UpdateNews).Methods(http.MethodPut)
+				router.HandleFunc("/api/news/{id}", h.PatchNews).Methods(http.MethodPatch)
+
+				req := httptest.NewRequest(method, "/api/news/1", strings.NewReader(`{"title":"Новость","content":"Непроверенный текст"}`))
+				req.Header.Set("If-Match", versionETag(2))
+				if method == http.MethodPatch {
+					req.Header.Set("Content-Type", "application/merge-patch+json")
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				if w.Code != tt.want {
+					t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+				}
+				if repo.updated != (tt.want == http.StatusOK) {
+					t.Fatalf("новость сохранена = %v при статусе %s", repo.updated, tt.status)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/services"
+)
+
+// oidcStateCookie хранит подписанное состояние входа между /oidc/login и /oidc/callback
+const oidcStateCookie = "oidc_state"
+
+type OIDCHandler struct {
+	service           services.SSOService
+	refreshTTL        time.Duration
+	postLoginRedirect string
+	logger            *zap.Logger
+}
+
+func NewOIDCHandler(service services.SSOService, refreshTTL time.Duration, postLoginRedirect string, logger *zap.Logger) *OIDCHandler {
+	return &OIDCHandler{service: service, refreshTTL: refreshTTL, postLoginRedirect: postLoginRedirect, logger: logger}
+}
+
+// Login godoc
+// @Summary Вход через SSO
+// @Description Перенаправляет браузер к OpenID Connect провайдеру организации
+// @Tags auth
+// @Success 302 "Перенаправление к провайдеру"
+// @Failure 502 {object} apperrors.Problem "Провайдер недоступен"
+// @Router /oidc/login [get]
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	authURL, stateToken, err := h.service.Begin(r.Context())
+	if err != nil {
+		h.logger.Error("Ошибка начала входа через SSO", zap.Error(err))
+		apperrors.Write(w, r, apperrors.Wrap(err, apperrors.CodeUpstreamFailed, "Провайдер входа недоступен"))
+		return
+	}
+
+	h.setStateCookie(w, stateToken, int(services.SSOStateTTL.Seconds()))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback godoc
+// @Summary Возврат от SSO-провайдера
+// @Description Завершает вход: создаёт или привязывает пользователя, устанавливает cookie с refresh-токеном.
+// @Description Если задан OIDC_POST_LOGIN_REDIRECT, браузер перенаправляется на фронтенд
+// @Description (при подключённой 2FA — с mfa_token во фрагменте адреса), иначе ответ такой же, как у /login
+// @Tags auth
+// @Produce json
+// @Param code query string true "Код авторизации"
+// @Param state query string true "Состояние входа"
+// @Success 200 {object} object{access_token=string,mfa_required=bool,mfa_token=string}
+// @Success 302 "Перенаправление на фронтенд"
+// @Failure 400 {object} apperrors.Problem "Состояние входа недействительно"
+// @Failure 401 {object} apperrors.Problem "Ошибка входа"
+// @Failure 403 {object} apperrors.Problem "Учётная запись не может быть использована"
+// @Router /oidc/callback [get]
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	cookie, cookieErr := r.Cookie(oidcStateCookie)
+	// Состояние одноразовое на любом исходе: повторный возврат с тем же кодом или после отказа не пройдёт
+	h.setStateCookie(w, "", -1)
+
+	if providerErr := query.Get("error"); providerErr != "" {
+		h.logger.Warn("Провайдер отказал во входе", zap.String("error", providerErr), zap.String("description", query.Get("error_description")))
+		apperrors.Write(w, r, apperrors.Unauthorized("Провайдер отказал во входе"))
+		return
+	}
+
+	if cookieErr != nil {
+		writeError(w, r, h.logger, services.ErrInvalidSSOState, "Ошибка входа")
+		return
+	}
+
+	result, err := h.service.Complete(r.Context(), cookie.Value, query.Get("state"), query.Get("code"), sessionMeta(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	if result.MFAToken != "" {
+		if h.postLoginRedirect != "" {
+			http.Redirect(w, r, h.postLoginRedirect+"#"+url.Values{"mfa_token": {result.MFAToken}}.Encode(), http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"mfa_required": true,
+			"mfa_token":    result.MFAToken,
+		})
+		return
+	}
+
+	setRefreshCookie(w, result.RefreshToken, int(h.refreshTTL.Seconds()))
+
+	// Access-токен фронтенд получает через /refresh, чтобы он не попадал в адресную строку
+	if h.postLoginRedirect != "" {
+		http.Redirect(w, r, h.postLoginRedirect, http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"access_token": result.AccessToken,
+	})
+}
+
+func (h *OIDCHandler) setStateCookie(w http.ResponseWriter, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		HttpOnly: true,
+		Secure:   true,
+		// Lax: cookie должна прийти при переходе браузера обратно от провайдера
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/oidc",
+		MaxAge:   maxAge,
+	})
+}
+
+// writeError отвечает на ошибку завершения входа; сбои обмена кода и проверки ID-токена — это 401, а не 500
+func (h *OIDCHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if _, known := toAppError(err); known {
+		writeError(w, r, h.logger, err, "Ошибка входа")
+		return
+	}
+	h.logger.Warn("Ошибка входа через SSO", zap.Error(err))
+	apperrors.Write(w, r, apperrors.Wrap(err, apperrors.CodeUnauthorized, "Ошибка входа"))
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/internal/services"
+)
+
+type fakeSSOService struct {
+	services.SSOService
+	completed bool
+}
+
+func (s *fakeSSOService) Complete(context.Context, string, string, string, services.SessionMeta) (*services.LoginResult, error) {
+	s.completed = true
+	return &services.LoginResult{AccessToken: "access", RefreshToken: "refresh"}, nil
+}
+
+func TestOIDCCallbackClearsStateOnProviderError(t *testing.T) {
+	service := &fakeSSOService{}
+	h := NewOIDCHandler(service, time.Hour, "", zap.NewNop())
+
+	r := httptest.NewRequest(http.MethodGet, "/oidc/callback?error=access_denied", nil)
+	r.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: "state-token"})
+	w := httptest.NewRecorder()
+
+	h.Callback(w, r)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", w.Code)
+	}
+	if service.completed {
+		t.Fatal("вход завершён несмотря на отказ провайдера")
+	}
+	cleared := false
+	for _, c := range w.Result().Cookies() {
+		if c.Name == oidcStateCookie && c.MaxAge < 0 {
+			cleared = true
+		}
+	}
+	if !cleared {
+		t.Fatal("cookie состояния не удалена")
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/services"
+)
+
+type PasswordResetHandler struct {
+	service services.PasswordResetService
+	logger  *zap.Logger
+}
+
+func NewPasswordResetHandler(service services.PasswordResetService, logger *zap.Logger) *PasswordResetHandler {
+	return &PasswordResetHandler{service: service, logger: logger}
+}
+
+// ForgotPassword godoc
+// @Summary Запрос сброса пароля
+// @Description Отправляет на email ссылку для сброса пароля. Ответ не зависит от того, зарегистрирован ли email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body object{email=string} true "Email пользователя"
+// @Success 202 {object} object{message=string}
+// @Failure 400 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /forgot-password [post]
+func (h *PasswordResetHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		apperrors.Write(w, r, apperrors.Validation("Email обязателен", apperrors.FieldError{Field: "email", Message: "Обязательное поле"}))
+		return
+	}
+
+	if err := h.service.ForgotPassword(r.Context(), req.Email, clientIP(r)); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка отправки письма")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Если email зарегистрирован, на него отправлена ссылка для сброса пароля",
+	})
+}
+
+// ResetPassword godoc
+// @Summary Сброс пароля
+// @Description Устанавливает новый пароль по одноразовому токену из письма и завершает все сессии
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body object{token=string,password=string} true "Токен и новый пароль"
+// @Success 200 {object} object{message=string}
+// @Failure 400 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /reset-password [post]
+func (h *PasswordResetHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, r)
+		return
+	}
+
+	if req.Token == "" || req.Password == "" {
+		apperrors.Write(w, r, apperrors.Validation("Токен и пароль обязательны", requiredFields(map[string]string{
+			"token":    req.Token,
+			"password": req.Password,
+		})...))
+		return
+	}
+
+	if err := h.service.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка сброса пароля")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Пароль успешно изменён",
+	})
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+
+	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/middleware"
+	"rcoi/internal/models"
+	"rcoi/internal/services"
+)
+
+type ProfileHandler struct {
+	service services.ProfileService
+	logger  *zap.Logger
+}
+
+func NewProfileHandler(service services.ProfileService, logger *zap.Logger) *ProfileHandler {
+	return &ProfileHandler{service: service, logger: logger}
+}
+
+// GetProfile godoc
+// @Summary Профиль текущего пользователя
+// @Tags profile
+// @Produce json
+// @Success 200 {object} models.User
+// @Failure 401 {object} apperrors.Problem "Пользователь не определён"
+// @Router /api/profile [get]
+func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+
+	user, err := h.service.GetProfile(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с профилем")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// UpdateProfile godoc
+// @Summary Изменение профиля
+// @Description Меняет только переданные поля; пустая строка очищает поле
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param profile body models.ProfileUpdate true "Поля профиля"
+// @Success 200 {object} models.User
+// @Failure 400 {object} apperrors.Problem "Некорректные данные профиля"
+// @Failure 401 {object} apperrors.Problem "Пользователь не определён"
+// @Router /api/profile [patch]
+func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+
+	var update models.ProfileUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		invalidJSON(w, r)
+		return
+	}
+
+	user, err := h.service.UpdateProfile(r.Context(), userID, update)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с профилем")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// UploadAvatar godoc
+// @Summary Загрузка аватара
+// @Description Заменяет аватар изображением PNG, JPEG, GIF или WebP размером до 2 МБ
+// @Tags profile
+// @Accept multipart/form-data
+// @Param avatar formData file true "Изображение"
+// @Success 204 "Аватар сохранён"
+// @Failure 400 {object} apperrors.Problem "Файл не найден или не является изображением"
+// @Failure 413 {object} apperrors.Problem "Файл слишком большой"
+// @Router /api/profile/avatar [put]
+func (h *ProfileHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+
+	// Запас на заголовки multipart сверх размера самого файла
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxAvatarSize+64<<10)
+	file, _, err := r.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, r, h.logger, services.ErrAvatarTooLarge, "Ошибка работы с профилем")
+			return
+		}
+		badRequest(w, r, "Файл не найден")
+		return
+	}
+	defer file.Close()
+
+	if err := h.service.SetAvatar(r.Context(), userID, file); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с профилем")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAvatar godoc
+// @Summary Аватар текущего пользователя
+// @Tags profile
+// @Produce image/png,image/jpeg,image/gif,image/webp
+// @Success 200 {file} file
+// @Failure 404 {object} apperrors.Problem "Аватар не загружен"
+// @Router /api/profile/avatar [get]
+func (h *ProfileHandler) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+
+	rc, info, err := h.service.OpenAvatar(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с профилем")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-cache")
+	serveStoredObject(w, r, "inline", path.Base(info.Key), rc, info)
+}
+
+// DeleteAvatar godoc
+// @Summary Удаление аватара
+// @Tags profile
+// @Success 204 "Аватар удалён"
+// @Failure 404 {object} apperrors.Problem "Аватар не загружен"
+// @Router /api/profile/avatar [delete]
+func (h *ProfileHandler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+
+	if err := h.service.DeleteAvatar(r.Context(), userID); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с профилем")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ChangePassword godoc
+// @Summary Смена пароля
+// @Description Проверяет текущий пароль, устанавливает новый по политике паролей и завершает все сессии, кроме текущей
+// @Tags profile
+// @Accept json
+// @Param request body object{current_password=string,new_password=string} true "Текущий и новый пароль"
+// @Success 204 "Пароль изменён"
+// @Failure 400 {object} apperrors.Problem "Новый пароль не соответствует политике"
+// @Failure 403 {object} apperrors.Problem "Текущий пароль указан неверно"
+// @Failure 429 {object} apperrors.Problem "Слишком много неудачных попыток"
+// @Router /api/profile/password [post]
+func (h *ProfileHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+	sessionID, _ := middleware.GetSessionIDFromContext(r.Context())
+
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, r)
+		return
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		apperrors.Write(w, r, apperrors.Validation("Текущий и новый пароль обязательны", requiredFields(map[string]string{
+			"current_password": req.CurrentPassword,
+			"new_password":     req.NewPassword,
+		})...))
+		return
+	}
+
+	err := h.service.ChangePassword(r.Context(), userID, sessionID, req.CurrentPassword, req.NewPassword, sessionMeta(r))
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с профилем")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
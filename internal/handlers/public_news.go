@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"rcoi/internal/services"
+)
+
+// PublicNewsHandler обслуживает публичный сайт: только чтение опубликованных новостей без аутентификации
+type PublicNewsHandler struct {
+	service services.PublicNewsService
+	maxAge  time.Duration
+	logger  *zap.Logger
+}
+
+// NewPublicNewsHandler создаёт обработчик; maxAge — срок кеширования ответов в Cache-Control
+func NewPublicNewsHandler(service services.PublicNewsService, maxAge time.Duration, logger *zap.Logger) *PublicNewsHandler {
+	return &PublicNewsHandler{service: service, maxAge: maxAge, logger: logger}
+}
+
+// GetNews godoc
+// @Summary Лента опубликованных новостей
+// @Description Возвращает опубликованные новости с анонсами, без аутентификации.
+// @Description Поддерживает условные запросы: If-None-Match и If-Modified-Since (ответ 304).
+// @Tags public
+// @Produce json
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param offset query int false "Смещение (не используется вместе с cursor)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "published_at или title, префикс '-' — по убыванию (по умолчанию -published_at)"
+// @Param title query string false "Подстрока названия"
+// @Success 200 {object} object{items=[]models.PublicNews,total=int,limit=int,offset=int,next_cursor=string}
+// @Success 304 "Лента не изменилась"
+// @Header 200 {string} ETag "Версия ответа"
+// @Header 200 {string} Last-Modified "Время последнего изменения новостей"
+// @Failure 400 {object} apperrors.Problem "Некорректные параметры списка"
+// @Failure 500 {object} apperrors.Problem "Ошибка получения новостей"
+// @Router /public/news [get]
+func (h *PublicNewsHandler) GetNews(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r, "title")
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	// Время берётся до выборки: изменение между запросами даст более старый Last-Modified, а не пропуск
+	lastModified, err := h.service.LastModified(r.Context())
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения новостей")
+		return
+	}
+
+	page, err := h.service.GetPublishedNews(r.Context(), q)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения новостей")
+		return
+	}
+
+	writeCacheable(w, r, h.logger, page, lastModified, h.maxAge)
+}
+
+// GetNewsBySlug godoc
+// @Summary Опубликованная новость по slug
+// @Description Возвращает опубликованную новость целиком, без аутентификации.
+// @Description Поддерживает условные запросы: If-None-Match и If-Modified-Since (ответ 304).
+// @Tags public
+// @Produce json
+// @Param slug path string true "Slug новости"
+// @Success 200 {object} models.PublicNews
+// @Success 304 "Новость не изменилась"
+// @Header 200 {string} ETag "Версия ответа"
+// @Header 200 {string} Last-Modified "Время последнего изменения новости"
+// @Failure 404 {object} apperrors.Problem "Новость не найдена или не опубликована"
+// @Failure 500 {object} apperrors.Problem "Ошибка получения новости"
+// @Router /public/news/{slug} [get]
+func (h *PublicNewsHandler) GetNewsBySlug(w http.ResponseWriter, r *http.Request) {
+	news, err := h.service.GetPublishedNewsBySlug(r.Context(), mux.Vars(r)["slug"])
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения новости")
+		return
+	}
+
+	writeCacheable(w, r, h.logger, news, news.UpdatedAt, h.maxAge)
+}
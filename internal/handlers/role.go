@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/middleware"
+	"rcoi/internal/models"
+	"rcoi/internal/services"
+)
+
+type RoleHandler struct {
+	service services.RoleService
+	logger  *zap.Logger
+}
+
+func NewRoleHandler(service services.RoleService, logger *zap.Logger) *RoleHandler {
+	return &RoleHandler{service: service, logger: logger}
+}
+
+// GetAllRoles godoc
+// @Summary Список ролей
+// @Description Возвращает роли вместе с их правами
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.Role
+// @Failure 500 {object} apperrors.Problem "Ошибка получения ролей"
+// @Router /api/admin/roles [get]
+func (h *RoleHandler) GetAllRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.service.GetAllRoles(r.Context())
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения ролей")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(roles)
+}
+
+// GetAllPermissions godoc
+// @Summary Список прав доступа
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.Permission
+// @Failure 500 {object} apperrors.Problem "Ошибка получения прав"
+// @Router /api/admin/permissions [get]
+func (h *RoleHandler) GetAllPermissions(w http.ResponseWriter, r *http.Request) {
+	permissions, err := h.service.GetAllPermissions(r.Context())
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения прав")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(permissions)
+}
+
+// CreateRole godoc
+// @Summary Создание роли
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param role body object{name=string,description=string,permissions=[]string} true "Роль"
+// @Success 201 {object} models.Role
+// @Failure 400 {object} apperrors.Problem "Неверный формат запроса или неизвестное право"
+// @Failure 409 {object} apperrors.Problem "Роль уже существует"
+// @Router /api/admin/roles [post]
+func (h *RoleHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	var role models.Role
+	if err := json.NewDecoder(r.Body).Decode(&role); err != nil {
+		invalidJSON(w, r)
+		return
+	}
+
+	if role.Name == "" {
+		apperrors.Write(w, r, apperrors.Validation("Название роли обязательно", apperrors.FieldError{Field: "name", Message: "Обязательное поле"}))
+		return
+	}
+
+	if err := h.service.CreateRole(r.Context(), h.actor(r), &role); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка управления ролями")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(role)
+}
+
+// SetPermissions godoc
+// @Summary Изменение прав роли
+// @Description Полностью заменяет набор прав роли. Access-токены пользователей с этой ролью
+// @Description отзываются: клиенты получают токены с новыми правами через /refresh.
+// @Description Снять roles:manage и users:manage с роли admin или со своей роли нельзя.
+// @Tags admin
+// @Accept json
+// @Param name path string true "Название роли"
+// @Param permissions body object{permissions=[]string} true "Права роли"
+// @Success 204 "Права изменены"
+// @Failure 400 {object} apperrors.Problem "Неизвестное право"
+// @Failure 404 {object} apperrors.Problem "Роль не найдена"
+// @Failure 409 {object} apperrors.Problem "Изменение лишило бы администраторов доступа"
+// @Router /api/admin/roles/{name}/permissions [put]
+func (h *RoleHandler) SetPermissions(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, r)
+		return
+	}
+
+	if err := h.service.SetPermissions(r.Context(), h.actor(r), h.actorRole(r), mux.Vars(r)["name"], req.Permissions); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка управления ролями")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteRole godoc
+// @Summary Удаление роли
+// @Tags admin
+// @Param name path string true "Название роли"
+// @Success 204 "Роль удалена"
+// @Failure 403 {object} apperrors.Problem "Встроенную роль нельзя удалить"
+// @Failure 404 {object} apperrors.Problem "Роль не найдена"
+// @Failure 409 {object} apperrors.Problem "Роль назначена пользователям"
+// @Router /api/admin/roles/{name} [delete]
+func (h *RoleHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteRole(r.Context(), h.actor(r), mux.Vars(r)["name"]); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка управления ролями")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RoleHandler) actor(r *http.Request) string {
+	email, _ := middleware.GetEmailFromContext(r.Context())
+	return email
+}
+
+func (h *RoleHandler) actorRole(r *http.Request) string {
+	role, _ := middleware.GetRoleFromContext(r.Context())
+	return role
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"rcoi/internal/middleware"
+	"rcoi/internal/services"
+)
+
+type SessionHandler struct {
+	service services.SessionService
+	logger  *zap.Logger
+}
+
+func NewSessionHandler(service services.SessionService, logger *zap.Logger) *SessionHandler {
+	return &SessionHandler{service: service, logger: logger}
+}
+
+// GetMySessions godoc
+// @Summary Мои сессии
+// @Description Возвращает активные сессии текущего пользователя; текущая отмечена полем current
+// @Tags sessions
+// @Produce json
+// @Success 200 {array} models.Session
+// @Failure 401 {object} apperrors.Problem "Пользователь не определён"
+// @Failure 500 {object} apperrors.Problem "Ошибка получения сессий"
+// @Router /api/sessions [get]
+func (h *SessionHandler) GetMySessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+	currentID, _ := middleware.GetSessionIDFromContext(r.Context())
+
+	sessions, err := h.service.GetUserSessions(r.Context(), userID, currentID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения сессий")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// RevokeMySession godoc
+// @Summary Завершение своей сессии
+// @Description Отзывает одну из сессий текущего пользователя
+// @Tags sessions
+// @Param id path string true "ID сессии"
+// @Success 204 "Сессия завершена"
+// @Failure 401 {object} apperrors.Problem "Пользователь не определён"
+// @Failure 404 {object} apperrors.Problem "Сессия не найдена"
+// @Router /api/sessions/{id} [delete]
+func (h *SessionHandler) RevokeMySession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		unauthenticated(w, r)
+		return
+	}
+
+	if err := h.service.RevokeOwnSession(r.Context(), userID, mux.Vars(r)["id"]); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка завершения сессии")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetUserSessions godoc
+// @Summary Сессии пользователя
+// @Description Возвращает активные сессии указанного пользователя
+// @Tags admin
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {array} models.Session
+// @Failure 400 {object} apperrors.Problem "Некорректный ID"
+// @Failure 500 {object} apperrors.Problem "Ошибка получения сессий"
+// @Router /api/admin/users/{id}/sessions [get]
+func (h *SessionHandler) GetUserSessions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	sessions, err := h.service.GetUserSessions(r.Context(), id, "")
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения сессий")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// RevokeSession godoc
+// @Summary Завершение любой сессии
+// @Description Отзывает сессию по ID независимо от владельца
+// @Tags admin
+// @Param id path string true "ID сессии"
+// @Success 204 "Сессия завершена"
+// @Failure 404 {object} apperrors.Problem "Сессия не найдена"
+// @Router /api/admin/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	actor, _ := middleware.GetEmailFromContext(r.Context())
+
+	if err := h.service.RevokeSession(r.Context(), actor, mux.Vars(r)["id"]); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка завершения сессии")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"rcoi/internal/middleware"
+	"rcoi/internal/services"
+)
+
+type UserHandler struct {
+	service services.UserService
+	logger  *zap.Logger
+}
+
+func NewUserHandler(service services.UserService, logger *zap.Logger) *UserHandler {
+	return &UserHandler{service: service, logger: logger}
+}
+
+// GetAllUsers godoc
+// @Summary Список пользователей
+// @Description Возвращает список пользователей с пагинацией
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param offset query int false "Смещение (не используется вместе с cursor)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Поле сортировки: id, email, created_at; префикс '-' — по убыванию"
+// @Param email query string false "Подстрока email"
+// @Success 200 {object} object{items=[]models.User,total=int,limit=int,offset=int,next_cursor=string}
+// @Failure 400 {object} apperrors.Problem "Некорректные параметры списка"
+// @Failure 500 {object} apperrors.Problem "Ошибка получения пользователей"
+// @Router /api/admin/users [get]
+func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r, "email")
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	users, err := h.service.GetAllUsers(r.Context(), q)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения пользователей")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// GetUserByID godoc
+// @Summary Получение пользователя по ID
+// @Tags admin
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} models.User
+// @Failure 400 {object} apperrors.Problem "Некорректный ID"
+// @Failure 404 {object} apperrors.Problem "Пользователь не найден"
+// @Router /api/admin/users/{id} [get]
+func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	user, err := h.service.GetUserByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка управления пользователями")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// CreateUser godoc
+// @Summary Создание пользователя
+// @Description Создаёт пользователя с указанной ролью
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param user body object{email=string,password=string,role=string} true "Данные пользователя"
+// @Success 201 {object} models.User
+// @Failure 400 {object} apperrors.Problem "Неверный формат запроса или некорректный email"
+// @Failure 409 {object} apperrors.Problem "Email уже используется"
+// @Router /api/admin/users [post]
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Role     string `json:"role"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, r)
+		return
+	}
+
+	if req.Email == "" || req.Password == "" {
+		badRequest(w, r, "Email и пароль обязательны")
+		return
+	}
+
+	user, err := h.service.CreateUser(r.Context(), h.actor(r), req.Email, req.Password, req.Role)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка управления пользователями")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+// ChangeRole godoc
+// @Summary Изменение роли пользователя
+// @Tags admin
+// @Accept json
+// @Param id path int true "ID пользователя"
+// @Param role body object{role=string} true "Новая роль"
+// @Success 204 "Роль изменена"
+// @Failure 400 {object} apperrors.Problem "Неизвестная роль"
+// @Failure 404 {object} apperrors.Problem "Пользователь не найден"
+// @Router /api/admin/users/{id}/role [put]
+func (h *UserHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w, r)
+		return
+	}
+
+	if err := h.service.ChangeRole(r.Context(), h.actor(r), id, req.Role); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка управления пользователями")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DisableUser godoc
+// @Summary Отключение учётной записи
+// @Description Отключает учётную запись и завершает её сессии
+// @Tags admin
+// @Param id path int true "ID пользователя"
+// @Success 204 "Учётная запись отключена"
+// @Failure 404 {object} apperrors.Problem "Пользователь не найден"
+// @Router /api/admin/users/{id}/disable [post]
+func (h *UserHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, false)
+}
+
+// EnableUser godoc
+// @Summary Включение учётной записи
+// @Tags admin
+// @Param id path int true "ID пользователя"
+// @Success 204 "Учётная запись включена"
+// @Failure 404 {object} apperrors.Problem "Пользователь не найден"
+// @Router /api/admin/users/{id}/enable [post]
+func (h *UserHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, true)
+}
+
+func (h *UserHandler) setActive(w http.ResponseWriter, r *http.Request, active bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	if err := h.service.SetActive(r.Context(), h.actor(r), id, active); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка управления пользователями")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForceLogout godoc
+// @Summary Принудительный выход пользователя
+// @Description Завершает все сессии пользователя
+// @Tags admin
+// @Param id path int true "ID пользователя"
+// @Success 204 "Сессии завершены"
+// @Failure 404 {object} apperrors.Problem "Пользователь не найден"
+// @Router /api/admin/users/{id}/logout [post]
+func (h *UserHandler) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	if err := h.service.ForceLogout(r.Context(), h.actor(r), id); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка управления пользователями")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnlockUser godoc
+// @Summary Снятие блокировки входа
+// @Description Сбрасывает счётчик неудачных попыток входа и временную блокировку учётной записи
+// @Tags admin
+// @Param id path int true "ID пользователя"
+// @Success 204 "Блокировка снята"
+// @Failure 404 {object} apperrors.Problem "Пользователь не найден"
+// @Router /api/admin/users/{id}/unlock [post]
+func (h *UserHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	if err := h.service.Unlock(r.Context(), h.actor(r), id); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка управления пользователями")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteUser godoc
+// @Summary Удаление пользователя
+// @Tags admin
+// @Param id path int true "ID пользователя"
+// @Success 204 "Пользователь удалён"
+// @Failure 404 {object} apperrors.Problem "Пользователь не найден"
+// @Router /api/admin/users/{id} [delete]
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, r, "Некорректный ID")
+		return
+	}
+
+	if err := h.service.DeleteUser(r.Context(), h.actor(r), id); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка управления пользователями")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// actor возвращает email администратора, выполняющего запрос
+func (h *UserHandler) actor(r *http.Request) string {
+	email, _ := middleware.GetEmailFromContext(r.Context())
+	return email
+}
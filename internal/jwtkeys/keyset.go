@@ -0,0 +1,123 @@
+package jwtkeys
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"rcoi/config"
+)
+
+// Назначение токена (claim token_use): каждая точка проверки принимает только свой тип
+const (
+	UseAccess  = "access"
+	UseRefresh = "refresh"
+	// UseMFA — промежуточный токен между вводом пароля и кода второго фактора
+	UseMFA = "mfa"
+	// UseOIDCState — состояние входа через SSO (state, nonce, PKCE), хранимое в cookie браузера
+	UseOIDCState = "oidc_state"
+)
+
+// key — ключ подписи, идентифицируемый kid. У ключа только для проверки signing == nil.
+type key struct {
+	id      string
+	method  jwt.SigningMethod
+	signing any
+	verify  any
+}
+
+// KeySet — набор ключей JWT. Новые токены подписываются активным ключом,
+// а принимаются токены, подписанные любым загруженным: так ротация ключей
+// не обрывает уже выданные токены.
+type KeySet struct {
+	active   *key
+	keys     map[string]*key
+	issuer   string
+	audience string
+}
+
+// Load собирает набор ключей из конфигурации.
+// JWT_SECRET задаёт HS256-ключ без kid (прежняя схема), JWT_KEYS_DIR — каталог
+// с ключами RSA и Ed25519, JWT_ACTIVE_KID — ключ, которым подписываются новые токены.
+func Load(cfg config.AuthConfig) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*key), issuer: cfg.TokenIssuer, audience: cfg.TokenAudience}
+
+	if cfg.JWTSecret != "" {
+		secret := []byte(cfg.JWTSecret)
+		ks.keys[""] = &key{method: jwt.SigningMethodHS256, signing: secret, verify: secret}
+	}
+
+	if cfg.JWTKeysDir != "" {
+		if err := ks.loadDir(cfg.JWTKeysDir); err != nil {
+			return nil, err
+		}
+	}
+
+	active, ok := ks.keys[cfg.JWTActiveKeyID]
+	switch {
+	case !ok && cfg.JWTActiveKeyID == "":
+		return nil, errors.New("не задан ни JWT_SECRET, ни JWT_ACTIVE_KID")
+	case !ok:
+		return nil, fmt.Errorf("активный ключ %q не найден в %s", cfg.JWTActiveKeyID, cfg.JWTKeysDir)
+	case active.signing == nil:
+		return nil, fmt.Errorf("для активного ключа %q нет закрытой части", cfg.JWTActiveKeyID)
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+// Sign подписывает claims активным ключом и проставляет его kid в заголовок
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	if ks.active.id != "" {
+		token.Header["kid"] = ks.active.id
+	}
+	return token.SignedString(ks.active.signing)
+}
+
+// Issuer возвращает значение claim iss для выпускаемых токенов
+func (ks *KeySet) Issuer() string {
+	return ks.issuer
+}
+
+// Audience возвращает аудиторию токена данного назначения: access-токены адресованы
+// API и сторонним сервисам, остальные принимает только сам издатель
+func (ks *KeySet) Audience(use string) string {
+	if use == UseAccess {
+		return ks.audience
+	}
+	return ks.issuer
+}
+
+// Parse проверяет подпись по kid из заголовка, издателя, аудиторию и назначение
+// токена и возвращает claims. Алгоритм токена обязан совпадать с алгоритмом ключа.
+func (ks *KeySet) Parse(tokenString, use string) (jwt.MapClaims, error) {
+	parser := jwt.NewParser(jwt.WithIssuer(ks.issuer), jwt.WithAudience(ks.Audience(use)), jwt.WithExpirationRequired())
+	token, err := parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		k, ok := ks.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("неизвестный ключ подписи %q", kid)
+		}
+		if token.Method.Alg() != k.method.Alg() {
+			return nil, errors.New("неверный метод подписи")
+		}
+		return k.verify, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, errors.New("неверный или просроченный токен")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("неверные claims")
+	}
+
+	if tokenUse, _ := claims["token_use"].(string); tokenUse != use {
+		return nil, errors.New("неверный тип токена")
+	}
+
+	return claims, nil
+}
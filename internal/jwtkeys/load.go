@@ -0,0 +1,104 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const minRSABits = 2048
+
+// loadDir читает ключи из каталога: <kid>.pem — закрытый ключ (PKCS#8 или PKCS#1),
+// <kid>.pub.pem — открытый ключ выведенного из оборота kid, который ещё нужен для проверки
+func (ks *KeySet) loadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения каталога ключей: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".pem") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		var k *key
+		if kid, ok := strings.CutSuffix(name, ".pub.pem"); ok {
+			k, err = parsePublicKey(kid, data)
+		} else {
+			k, err = parsePrivateKey(strings.TrimSuffix(name, ".pem"), data)
+		}
+		if err != nil {
+			return fmt.Errorf("ключ %s: %w", name, err)
+		}
+
+		// Закрытый ключ содержит и открытую часть, поэтому имеет приоритет
+		if existing, ok := ks.keys[k.id]; ok && existing.signing != nil {
+			continue
+		}
+		ks.keys[k.id] = k
+	}
+
+	return nil
+}
+
+func parsePrivateKey(kid string, data []byte) (*key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("не найден PEM-блок")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		rsaKey, rsaErr := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if rsaErr != nil {
+			return nil, err
+		}
+		parsed = rsaKey
+	}
+
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("длина RSA-ключа меньше %d бит", minRSABits)
+		}
+		return &key{id: kid, method: jwt.SigningMethodRS256, signing: private, verify: &private.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &key{id: kid, method: jwt.SigningMethodEdDSA, signing: private, verify: private.Public()}, nil
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип ключа %T", parsed)
+	}
+}
+
+func parsePublicKey(kid string, data []byte) (*key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("не найден PEM-блок")
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch public := parsed.(type) {
+	case *rsa.PublicKey:
+		return &key{id: kid, method: jwt.SigningMethodRS256, verify: public}, nil
+	case ed25519.PublicKey:
+		return &key{id: kid, method: jwt.SigningMethodEdDSA, verify: public}, nil
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип ключа %T", parsed)
+	}
+}
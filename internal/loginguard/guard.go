@@ -0,0 +1,135 @@
+package loginguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/config"
+)
+
+// Прогрессивная задержка: начиная с delayAfter-й неудачи следующая попытка
+// разрешается не раньше чем через baseDelay, удваиваясь до maxDelay
+const (
+	delayAfter = 3
+	baseDelay  = time.Second
+	maxDelay   = 30 * time.Second
+)
+
+// ErrTooManyAttempts — общая причина отказа; конкретный срок содержит *LockedError
+var ErrTooManyAttempts = errors.New("слишком много неудачных попыток входа")
+
+// LockedError сообщает, когда можно повторить попытку входа
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s, повторите через %s", ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockedError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// Guard ограничивает попытки входа по учётной записи и по IP-адресу
+type Guard struct {
+	store  Store
+	cfg    config.LockoutConfig
+	logger *zap.Logger
+}
+
+func NewGuard(store Store, cfg config.LockoutConfig, logger *zap.Logger) *Guard {
+	return &Guard{store: store, cfg: cfg, logger: logger}
+}
+
+// Check возвращает *LockedError, если попытка входа сейчас не разрешена
+func (g *Guard) Check(ctx context.Context, email, ip string) error {
+	now := time.Now()
+	for _, key := range g.keys(email, ip) {
+		e, err := g.store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if e.LockedUntil.After(now) {
+			return &LockedError{RetryAfter: e.LockedUntil.Sub(now)}
+		}
+
+		if e.Failures >= delayAfter && now.Sub(e.LastFailure) <= g.cfg.FailureWindow {
+			if next := e.LastFailure.Add(delay(e.Failures)); next.After(now) {
+				return &LockedError{RetryAfter: next.Sub(now)}
+			}
+		}
+	}
+	return nil
+}
+
+// Fail учитывает неудачную попытку и блокирует ключи, превысившие порог
+func (g *Guard) Fail(ctx context.Context, email, ip string) error {
+	for _, key := range g.keys(email, ip) {
+		e, err := g.store.RegisterFailure(ctx, key, g.cfg.FailureWindow)
+		if err != nil {
+			return err
+		}
+
+		if e.Failures < g.threshold(key) || e.LockedUntil.After(time.Now()) {
+			continue
+		}
+
+		if err := g.store.Lock(ctx, key, g.cfg.LockoutDuration); err != nil {
+			return err
+		}
+		g.logger.Warn("Вход временно заблокирован после неудачных попыток",
+			zap.String("event", "login_lockout"),
+			zap.String("key", key),
+			zap.Int("failures", e.Failures),
+			zap.Duration("duration", g.cfg.LockoutDuration),
+		)
+	}
+	return nil
+}
+
+// Succeed сбрасывает счётчик учётной записи после успешного входа.
+// Счётчик IP не сбрасывается: иначе перебор можно было бы обнулять входом в свою учётную запись.
+func (g *Guard) Succeed(ctx context.Context, email string) error {
+	return g.store.Reset(ctx, accountKey(email))
+}
+
+// Unlock снимает блокировку учётной записи
+func (g *Guard) Unlock(ctx context.Context, email string) error {
+	return g.store.Reset(ctx, accountKey(email))
+}
+
+func (g *Guard) keys(email, ip string) []string {
+	keys := make([]string, 0, 2)
+	if g.cfg.MaxAccountFailures > 0 {
+		keys = append(keys, accountKey(email))
+	}
+	if g.cfg.MaxIPFailures > 0 && ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+	return keys
+}
+
+func (g *Guard) threshold(key string) int {
+	if strings.HasPrefix(key, "ip:") {
+		return g.cfg.MaxIPFailures
+	}
+	return g.cfg.MaxAccountFailures
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func delay(failures int) time.Duration {
+	d := baseDelay
+	for i := delayAfter; i < failures && d < maxDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxDelay)
+}
@@ -0,0 +1,131 @@
+package loginguard
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/config"
+)
+
+func newTestGuard(store Store, accountLimit, ipLimit int) *Guard {
+	return NewGuard(store, config.LockoutConfig{
+		MaxAccountFailures: accountLimit,
+		MaxIPFailures:      ipLimit,
+		FailureWindow:      15 * time.Minute,
+		LockoutDuration:    15 * time.Minute,
+	}, zap.NewNop())
+}
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{delayAfter, baseDelay},
+		{delayAfter + 1, 2 * baseDelay},
+		{delayAfter + 2, 4 * baseDelay},
+		{delayAfter + 10, maxDelay},
+	}
+	for _, tt := range tests {
+		if got := delay(tt.failures); got != tt.want {
+			t.Errorf("delay(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestGuardProgressiveDelay(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGuard(NewMemoryStore(), 10, 0)
+
+	for i := 1; i < delayAfter; i++ {
+		g.Fail(ctx, "user@example.com", "203.0.113.7")
+		if err := g.Check(ctx, "user@example.com", "203.0.113.7"); err != nil {
+			t.Fatalf("после %d неудач вход задержан: %v", i, err)
+		}
+	}
+
+	g.Fail(ctx, "user@example.com", "203.0.113.7")
+	err := g.Check(ctx, "USER@example.com", "203.0.113.7")
+	var locked *LockedError
+	if !errors.As(err, &locked) || !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("после %d неудач Check = %v, ожидается задержка", delayAfter, err)
+	}
+	if locked.RetryAfter <= 0 || locked.RetryAfter > baseDelay {
+		t.Fatalf("RetryAfter = %s, want (0, %s]", locked.RetryAfter, baseDelay)
+	}
+
+	if err := g.Check(ctx, "other@example.com", "203.0.113.7"); err != nil {
+		t.Fatalf("задержка учётной записи затронула другую: %v", err)
+	}
+}
+
+func TestGuardLockout(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	g := newTestGuard(store, 5, 0)
+
+	for i := 0; i < 5; i++ {
+		if err := g.Fail(ctx, "user@example.com", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e, _ := store.Get(ctx, accountKey("user@example.com"))
+	if time.Until(e.LockedUntil) < 14*time.Minute {
+		t.Fatalf("учётная запись не заблокирована на LockoutDuration: %v", e.LockedUntil)
+	}
+	var locked *LockedError
+	if err := g.Check(ctx, "user@example.com", ""); !errors.As(err, &locked) || locked.RetryAfter < 14*time.Minute {
+		t.Fatalf("Check = %v, ожидается блокировка", err)
+	}
+
+	if err := g.Unlock(ctx, "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Check(ctx, "user@example.com", ""); err != nil {
+		t.Fatalf("после Unlock вход запрещён: %v", err)
+	}
+}
+
+func TestGuardIPCounterSurvivesSuccess(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGuard(NewMemoryStore(), 0, 3)
+
+	// Перебор разных учётных записей с одного адреса
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		g.Fail(ctx, email, "203.0.113.7")
+	}
+	// Успешный вход в свою учётную запись не обнуляет счётчик IP
+	g.Succeed(ctx, "mine@example.com")
+
+	if err := g.Check(ctx, "mine@example.com", "203.0.113.7"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("Check с заблокированного IP = %v", err)
+	}
+	if err := g.Check(ctx, "mine@example.com", "198.51.100.1"); err != nil {
+		t.Fatalf("блокировка IP затронула другой адрес: %v", err)
+	}
+}
+
+func TestGuardFailureWindow(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	g := newTestGuard(store, 10, 0)
+
+	for i := 0; i < delayAfter; i++ {
+		g.Fail(ctx, "user@example.com", "")
+	}
+	// Последняя неудача давно: счётчик не задерживает вход и начинается заново
+	e := store.entries[accountKey("user@example.com")]
+	e.LastFailure = time.Now().Add(-time.Hour)
+	store.entries[accountKey("user@example.com")] = e
+
+	if err := g.Check(ctx, "user@example.com", ""); err != nil {
+		t.Fatalf("устаревшие неудачи задерживают вход: %v", err)
+	}
+	if e, _ := store.RegisterFailure(ctx, accountKey("user@example.com"), 15*time.Minute); e.Failures != 1 {
+		t.Fatalf("после окна счёт продолжился с %d", e.Failures)
+	}
+}
@@ -0,0 +1,65 @@
+package loginguard
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore хранит счётчики в памяти процесса; реплики не видят счётчики друг друга
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries[key], nil
+}
+
+func (s *MemoryStore) RegisterFailure(_ context.Context, key string, window time.Duration) (Entry, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Заодно убираем устаревшие счётчики без действующей блокировки
+	for k, e := range s.entries {
+		if now.Sub(e.LastFailure) > window && !e.LockedUntil.After(now) {
+			delete(s.entries, k)
+		}
+	}
+
+	e := s.entries[key]
+	if now.Sub(e.LastFailure) > window {
+		e.Failures = 0
+	}
+	e.Failures++
+	e.LastFailure = now
+	s.entries[key] = e
+
+	return e, nil
+}
+
+func (s *MemoryStore) Lock(_ context.Context, key string, duration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.entries[key]
+	e.LockedUntil = time.Now().Add(duration)
+	s.entries[key] = e
+	return nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
@@ -0,0 +1,76 @@
+package loginguard
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore хранит счётчики в таблице login_attempts, общей для всех реплик
+type PostgresStore struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresStore(db *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Get(ctx context.Context, key string) (Entry, error) {
+	var e Entry
+	var lockedUntil *time.Time
+	query := `SELECT failures, last_failure_at, locked_until FROM login_attempts WHERE key = $1`
+	err := s.db.QueryRow(ctx, query, key).Scan(&e.Failures, &e.LastFailure, &lockedUntil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Entry{}, nil
+	}
+	if lockedUntil != nil {
+		e.LockedUntil = *lockedUntil
+	}
+	return e, err
+}
+
+func (s *PostgresStore) RegisterFailure(ctx context.Context, key string, window time.Duration) (Entry, error) {
+	var e Entry
+	var lockedUntil *time.Time
+	query := `
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_attempts.last_failure_at < NOW() - make_interval(secs => $2) THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failure_at = NOW()
+		RETURNING failures, last_failure_at, locked_until
+	`
+	err := s.db.QueryRow(ctx, query, key, window.Seconds()).Scan(&e.Failures, &e.LastFailure, &lockedUntil)
+	if err != nil {
+		return Entry{}, err
+	}
+	if lockedUntil != nil {
+		e.LockedUntil = *lockedUntil
+	}
+
+	// Заодно убираем устаревшие счётчики без действующей блокировки
+	query = `
+		DELETE FROM login_attempts
+		WHERE last_failure_at < NOW() - make_interval(secs => $1)
+		  AND (locked_until IS NULL OR locked_until < NOW())
+	`
+	_, err = s.db.Exec(ctx, query, window.Seconds())
+	return e, err
+}
+
+func (s *PostgresStore) Lock(ctx context.Context, key string, duration time.Duration) error {
+	query := `UPDATE login_attempts SET locked_until = NOW() + make_interval(secs => $2) WHERE key = $1`
+	_, err := s.db.Exec(ctx, query, key, duration.Seconds())
+	return err
+}
+
+func (s *PostgresStore) Reset(ctx context.Context, key string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM login_attempts WHERE key = $1`, key)
+	return err
+}
@@ -0,0 +1,39 @@
+package loginguard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Entry — состояние счётчика неудачных попыток по одному ключу
+type Entry struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+// Store хранит счётчики неудачных попыток входа
+type Store interface {
+	// Get возвращает состояние счётчика; для неизвестного ключа — нулевое
+	Get(ctx context.Context, key string) (Entry, error)
+	// RegisterFailure увеличивает счётчик; если последняя неудача была раньше window,
+	// счёт начинается заново
+	RegisterFailure(ctx context.Context, key string, window time.Duration) (Entry, error)
+	Lock(ctx context.Context, key string, duration time.Duration) error
+	Reset(ctx context.Context, key string) error
+}
+
+// NewStore создаёт хранилище счётчиков в соответствии с конфигурацией
+func NewStore(driver string, db *pgxpool.Pool) (Store, error) {
+	switch driver {
+	case "", "postgres":
+		return NewPostgresStore(db), nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер счётчиков входа: %s", driver)
+	}
+}
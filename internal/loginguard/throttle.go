@@ -0,0 +1,52 @@
+package loginguard
+
+import (
+	"context"
+	"time"
+)
+
+// Throttle ограничивает частоту действий без входа, например запросов писем со ссылками,
+// по адресу email и по IP. Счётчики хранятся в том же Store, что и попытки входа,
+// поэтому ограничение общее для всех реплик.
+type Throttle struct {
+	store    Store
+	name     string
+	perEmail int
+	perIP    int
+	window   time.Duration
+}
+
+// NewThrottle создаёт ограничитель: не больше perEmail действий на один email и perIP с одного IP,
+// пока между действиями проходит меньше window; 0 отключает соответствующий счётчик.
+// name отделяет счётчики ограничителя от счётчиков входа.
+func NewThrottle(store Store, name string, perEmail, perIP int, window time.Duration) *Throttle {
+	return &Throttle{store: store, name: name, perEmail: perEmail, perIP: perIP, window: window}
+}
+
+// Allow учитывает действие и сообщает, укладывается ли оно в лимиты.
+// Отклонённые действия тоже считаются: непрерывный поток запросов не дождётся сброса счётчика.
+func (t *Throttle) Allow(ctx context.Context, email, ip string) (bool, error) {
+	allowed := true
+	for key, limit := range t.limits(email, ip) {
+		e, err := t.store.RegisterFailure(ctx, key, t.window)
+		if err != nil {
+			return false, err
+		}
+		if e.Failures > limit {
+			allowed = false
+		}
+	}
+	return allowed, nil
+}
+
+// limits возвращает включённые счётчики и их лимиты
+func (t *Throttle) limits(email, ip string) map[string]int {
+	limits := make(map[string]int, 2)
+	if t.perEmail > 0 {
+		limits[t.name+":"+accountKey(email)] = t.perEmail
+	}
+	if t.perIP > 0 && ip != "" {
+		limits[t.name+":ip:"+ip] = t.perIP
+	}
+	return limits
+}
@@ -0,0 +1,45 @@
+package loginguard
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	ctx := context.Background()
+	throttle := NewThrottle(NewMemoryStore(), "mail", 2, 3, time.Hour)
+
+	allow := func(email, ip string) bool {
+		t.Helper()
+		ok, err := throttle.Allow(ctx, email, ip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	if !allow("a@example.com", "203.0.113.1") || !allow("A@example.com ", "203.0.113.2") {
+		t.Fatal("первые письма на адрес отклонены")
+	}
+	if allow("a@example.com", "203.0.113.3") {
+		t.Fatal("третье письмо на тот же адрес пропущено")
+	}
+
+	// С одного IP: b и c проходят, а четвёртый запрос превышает лимит, хотя адреса разные
+	if !allow("b@example.com", "198.51.100.1") || !allow("c@example.com", "198.51.100.1") || !allow("d@example.com", "198.51.100.1") {
+		t.Fatal("письма на разные адреса с одного IP отклонены до лимита")
+	}
+	if allow("e@example.com", "198.51.100.1") {
+		t.Fatal("запрос сверх лимита IP пропущен")
+	}
+}
+
+func TestThrottleDisabled(t *testing.T) {
+	throttle := NewThrottle(NewMemoryStore(), "mail", 0, 0, time.Hour)
+	for i := 0; i < 10; i++ {
+		if ok, err := throttle.Allow(context.Background(), "a@example.com", "203.0.113.1"); err != nil || !ok {
+			t.Fatalf("отключённый ограничитель отклонил запрос: %v, %v", ok, err)
+		}
+	}
+}
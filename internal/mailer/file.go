@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type fileMailer struct {
+	from   string
+	dir    string
+	logger *zap.Logger
+}
+
+// NewFileMailer пишет письма в журнал и, если задан dir, сохраняет их в .eml файлы.
+// Используется в разработке и тестах вместо настоящей отправки.
+func NewFileMailer(from, dir string, logger *zap.Logger) Mailer {
+	return &fileMailer{from: from, dir: dir, logger: logger}
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg Message) error {
+	m.logger.Info("Письмо",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body))
+
+	if m.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102150405.000000000"), filepath.Base(msg.To))
+	return os.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, msg), 0o644)
+}
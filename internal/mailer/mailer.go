@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/config"
+)
+
+// Message — простое текстовое письмо
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer отправляет письма пользователям
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New создаёт почтовый транспорт в соответствии с конфигурацией
+func New(cfg config.MailConfig, logger *zap.Logger) (Mailer, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return NewFileMailer(cfg.From, "", logger), nil
+	case "file":
+		return NewFileMailer(cfg.From, cfg.FileDir, logger), nil
+	case "smtp":
+		return NewSMTPMailer(cfg), nil
+	default:
+		return nil, fmt.Errorf("неизвестный почтовый драйвер: %s", cfg.Driver)
+	}
+}
+
+// buildMessage формирует письмо в формате RFC 5322
+func buildMessage(from string, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+
+	return buf.Bytes()
+}
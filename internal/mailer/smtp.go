@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"strconv"
+
+	"rcoi/config"
+)
+
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer отправляет письма через SMTP-сервер (STARTTLS используется, если сервер его поддерживает)
+func NewSMTPMailer(cfg config.MailConfig) Mailer {
+	var auth smtp.Auth
+	if cfg.SMTPUser != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return &smtpMailer{
+		addr: net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		auth: auth,
+		from: cfg.From,
+	}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, buildMessage(m.from, msg))
+}
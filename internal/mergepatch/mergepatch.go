@@ -0,0 +1,43 @@
+// Package mergepatch применяет JSON Merge Patch (RFC 7386)
+package mergepatch
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ContentType — тип тела запроса PATCH
+const ContentType = "application/merge-patch+json"
+
+// Apply применяет patch к документу target и возвращает результат.
+// Ключ со значением null удаляет поле, объекты сливаются рекурсивно, остальные значения заменяются.
+func Apply(target, patch []byte) ([]byte, error) {
+	var targetDoc, patchDoc any
+	if err := json.Unmarshal(target, &targetDoc); err != nil {
+		return nil, fmt.Errorf("исходный документ: %w", err)
+	}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return nil, fmt.Errorf("патч: %w", err)
+	}
+	return json.Marshal(merge(targetDoc, patchDoc))
+}
+
+func merge(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = make(map[string]any, len(patchObj))
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = merge(targetObj[key], value)
+	}
+	return targetObj
+}
@@ -1,78 +1,152 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
-	"rcoi/internal/utils"
+	"rcoi/internal/apperrors"
+	"rcoi/internal/denylist"
+	"rcoi/internal/jwtkeys"
+	"rcoi/internal/services"
 )
 
 // Ключи контекста
 type ContextKey string
 
 const (
-	UserEmailKey ContextKey = "user_email"
-	UserRoleKey  ContextKey = "user_role"
+	UserEmailKey       ContextKey = "user_email"
+	UserRoleKey        ContextKey = "user_role"
+	UserPermissionsKey ContextKey = "user_permissions"
+	UserIDKey          ContextKey = "user_id"
+	SessionIDKey       ContextKey = "session_id"
+	APIKeyIDKey        ContextKey = "api_key_id"
 )
 
-// AuthMiddleware проверяет JWT токен и добавляет email и роль в контекст
-func AuthMiddleware(logger *zap.Logger) mux.MiddlewareFunc {
+// APIKeyHeader — заголовок, в котором машинные клиенты передают API-ключ
+const APIKeyHeader = "X-API-Key"
+
+// AuthMiddleware проверяет JWT токен или API-ключ из заголовка X-API-Key,
+// отклоняет отозванные токены и добавляет email, роль и права в контекст
+func AuthMiddleware(keys *jwtkeys.KeySet, revoked denylist.Store, apiKeys services.APIKeyService, logger *zap.Logger) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rawKey := r.Header.Get(APIKeyHeader); rawKey != "" {
+				key, err := apiKeys.Authenticate(r.Context(), rawKey)
+				if err != nil {
+					if errors.Is(err, services.ErrInvalidAPIKey) {
+						apperrors.Write(w, r, apperrors.New(apperrors.CodeTokenInvalid, "Неверный или отозванный API-ключ"))
+						return
+					}
+					logger.Error("Ошибка проверки API-ключа", zap.Error(err))
+					apperrors.Write(w, r, apperrors.Wrap(err, apperrors.CodeInternal, "Ошибка проверки API-ключа"))
+					return
+				}
+
+				// У ключа нет пользователя: в email попадает метка ключа, чтобы действия были видны в журналах
+				ctx := SetEmailToContext(r.Context(), "api-key:"+key.Prefix)
+				ctx = SetRoleToContext(ctx, "api_key")
+				ctx = SetPermissionsToContext(ctx, key.Scopes)
+				ctx = SetAPIKeyIDToContext(ctx, key.ID)
+
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				http.Error(w, "Отсутствует токен", http.StatusUnauthorized)
+				apperrors.Write(w, r, apperrors.Unauthorized("Отсутствует токен"))
 				return
 			}
 
 			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 			if tokenString == authHeader {
-				http.Error(w, "Неверный формат токена", http.StatusUnauthorized)
+				apperrors.Write(w, r, apperrors.New(apperrors.CodeTokenInvalid, "Неверный формат токена"))
 				return
 			}
 
-			claims, err := utils.ParseToken(tokenString, logger)
+			claims, err := keys.Parse(tokenString, jwtkeys.UseAccess)
 			if err != nil {
-				http.Error(w, "Неверный или просроченный токен", http.StatusUnauthorized)
+				apperrors.Write(w, r, apperrors.New(apperrors.CodeTokenInvalid, "Неверный или просроченный токен"))
+				return
+			}
+
+			jti, ok := claims["jti"].(string)
+			if !ok || jti == "" {
+				apperrors.Write(w, r, apperrors.New(apperrors.CodeTokenInvalid, "Токен без идентификатора"))
+				return
+			}
+
+			denied, err := revoked.Contains(r.Context(), jti)
+			if err != nil {
+				logger.Error("Ошибка проверки отзыва токена", zap.Error(err))
+				apperrors.Write(w, r, apperrors.Wrap(err, apperrors.CodeInternal, "Ошибка проверки токена"))
+				return
+			}
+			if denied {
+				apperrors.Write(w, r, apperrors.New(apperrors.CodeTokenRevoked, "Токен отозван"))
 				return
 			}
 
 			email, ok := claims["email"].(string)
 			if !ok {
-				http.Error(w, "Некорректный email в токене", http.StatusUnauthorized)
+				apperrors.Write(w, r, apperrors.New(apperrors.CodeTokenInvalid, "Некорректный email в токене"))
 				return
 			}
 
 			role, ok := claims["role"].(string)
 			if !ok {
-				http.Error(w, "Некорректная роль в токене", http.StatusUnauthorized)
+				apperrors.Write(w, r, apperrors.New(apperrors.CodeTokenInvalid, "Некорректная роль в токене"))
 				return
 			}
 
+			var permissions []string
+			if list, ok := claims["permissions"].([]interface{}); ok {
+				for _, p := range list {
+					if perm, ok := p.(string); ok {
+						permissions = append(permissions, perm)
+					}
+				}
+			}
+
 			// Используем функции-хелперы для добавления данных в контекст
 			ctx := SetEmailToContext(r.Context(), email)
 			ctx = SetRoleToContext(ctx, role)
+			ctx = SetPermissionsToContext(ctx, permissions)
+
+			if sub, ok := claims["sub"].(string); ok {
+				if userID, err := strconv.Atoi(sub); err == nil {
+					ctx = SetUserIDToContext(ctx, userID)
+				}
+			}
+			if sessionID, ok := claims["sid"].(string); ok {
+				ctx = SetSessionIDToContext(ctx, sessionID)
+			}
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-// RoleMiddleware проверяет роль пользователя
-func RoleMiddleware(allowedRole string) mux.MiddlewareFunc {
+// RequirePermission пропускает запрос, только если у пользователя есть все указанные права
+func RequirePermission(required ...string) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			role, ok := GetRoleFromContext(r.Context())
+			permissions, ok := GetPermissionsFromContext(r.Context())
 			if !ok {
-				http.Error(w, "Роль не найдена в токене", http.StatusUnauthorized)
+				apperrors.Write(w, r, apperrors.Unauthorized("Права не найдены в токене"))
 				return
 			}
 
-			if role != allowedRole {
-				http.Error(w, "Доступ запрещён", http.StatusForbidden)
-				return
+			for _, perm := range required {
+				if !slices.Contains(permissions, perm) {
+					apperrors.Write(w, r, apperrors.New(apperrors.CodeForbidden, "Доступ запрещён"))
+					return
+				}
 			}
 
 			next.ServeHTTP(w, r)
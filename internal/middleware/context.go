@@ -19,3 +19,39 @@ func GetRoleFromContext(ctx context.Context) (string, bool) {
 	role, ok := ctx.Value(UserRoleKey).(string)
 	return role, ok
 }
+
+func SetPermissionsToContext(ctx context.Context, permissions []string) context.Context {
+	return context.WithValue(ctx, UserPermissionsKey, permissions)
+}
+
+func GetPermissionsFromContext(ctx context.Context) ([]string, bool) {
+	permissions, ok := ctx.Value(UserPermissionsKey).([]string)
+	return permissions, ok
+}
+
+func SetUserIDToContext(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, UserIDKey, userID)
+}
+
+func GetUserIDFromContext(ctx context.Context) (int, bool) {
+	userID, ok := ctx.Value(UserIDKey).(int)
+	return userID, ok
+}
+
+func SetSessionIDToContext(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, SessionIDKey, sessionID)
+}
+
+func GetSessionIDFromContext(ctx context.Context) (string, bool) {
+	sessionID, ok := ctx.Value(SessionIDKey).(string)
+	return sessionID, ok
+}
+
+func SetAPIKeyIDToContext(ctx context.Context, keyID int) context.Context {
+	return context.WithValue(ctx, APIKeyIDKey, keyID)
+}
+
+func GetAPIKeyIDFromContext(ctx context.Context) (int, bool) {
+	keyID, ok := ctx.Value(APIKeyIDKey).(int)
+	return keyID, ok
+}
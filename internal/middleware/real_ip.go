@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// RealIP подставляет в r.RemoteAddr адрес клиента из X-Forwarded-For или X-Real-IP,
+// если запрос пришёл от доверенного прокси. От остальных заголовки не принимаются:
+// иначе клиент подделал бы адрес и обошёл ограничения по IP.
+func RealIP(trusted []*net.IPNet) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(trusted) > 0 && isTrusted(trusted, remoteHost(r.RemoteAddr)) {
+				if ip := forwardedFor(trusted, r.Header); ip != "" {
+					r.RemoteAddr = ip
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedFor идёт по X-Forwarded-For справа налево и возвращает первый адрес не из цепочки
+// доверенных прокси: левее него значения мог записать сам клиент
+func forwardedFor(trusted []*net.IPNet, header http.Header) string {
+	hops := strings.Split(strings.Join(header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		if !isTrusted(trusted, ip.String()) {
+			return ip.String()
+		}
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+func remoteHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+func isTrusted(trusted []*net.IPNet, host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	trusted := []*net.IPNet{proxies}
+
+	tests := []struct {
+		name       string
+		trusted    []*net.IPNet
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"без доверенных прокси заголовок игнорируется", nil, "10.0.0.5:4000", "203.0.113.7", "", "10.0.0.5:4000"},
+		{"клиент напрямую подделывает заголовок", trusted, "198.51.100.9:4000", "203.0.113.7", "", "198.51.100.9:4000"},
+		{"адрес от доверенного прокси", trusted, "10.0.0.5:4000", "203.0.113.7", "", "203.0.113.7"},
+		{"подделка левее адреса, записанного прокси", trusted, "10.0.0.5:4000", "1.2.3.4, 203.0.113.7", "", "203.0.113.7"},
+		{"цепочка доверенных прокси", trusted, "10.0.0.5:4000", "203.0.113.7, 10.0.0.9", "", "203.0.113.7"},
+		{"X-Real-IP от доверенного прокси", trusted, "10.0.0.5:4000", "", "203.0.113.8", "203.0.113.8"},
+		{"некорректный заголовок", trusted, "10.0.0.5:4000", "not-an-ip", "", "10.0.0.5:4000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := RealIP(tt.trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			r := httptest.NewRequest(http.MethodPost, "/login", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Fatalf("RemoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
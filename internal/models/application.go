@@ -9,4 +9,15 @@ type Application struct {
 	Filename    string    `json:"filename,omitempty"`
 	URL         string    `json:"url,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
+	// Version увеличивается при каждом изменении и передаётся в ETag
+	Version int `json:"version"`
+}
+
+// ApplicationInput — данные приложения от клиента; при создании приходят формой, при изменении — JSON.
+// FileName — имя загруженного файла, если URL не указан.
+type ApplicationInput struct {
+	Title       string `json:"title" form:"title" validate:"required,max=255"`
+	Description string `json:"description" form:"description" validate:"max=5000"`
+	URL         string `json:"url" form:"url" validate:"max=500,url"`
+	FileName    string `json:"-" form:"file" validate:"max=255"`
 }
@@ -8,3 +8,9 @@ type Document struct {
 	Filename  string    `json:"filename"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// DocumentInput — поля формы загрузки документа
+type DocumentInput struct {
+	Title    string `form:"title" validate:"required,max=255"`
+	FileName string `form:"file" validate:"max=255"`
+}
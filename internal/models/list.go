@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ErrInvalidListQuery возвращается при недопустимой сортировке или курсоре
+var ErrInvalidListQuery = errors.New("некорректные параметры списка")
+
+// ListQuery — общие параметры постраничной выборки, сортировки и фильтрации списков.
+// Если задан Cursor, используется keyset-пагинация и Offset игнорируется.
+type ListQuery struct {
+	Limit  int
+	Offset int
+	Cursor string
+
+	Sort string // имя поля из белого списка репозитория
+	Desc bool
+
+	CreatedFrom *time.Time // включительно
+	CreatedTo   *time.Time // не включительно
+	Search      string     // подстрока поля поиска сущности без учёта регистра
+}
+
+// Page — страница результатов списка
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// TOTP — состояние второго фактора пользователя
+type TOTP struct {
+	Secret   string
+	Enabled  bool
+	LastStep int64
+}
+
+// MFAStatus — сведения о втором факторе для самого пользователя
+type MFAStatus struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
@@ -1,9 +1,28 @@
 package models
 
+import "time"
+
 type User struct {
-	ID           int    `json:"id"`
-	Email        string `json:"email"`
-	Password     string `json:"-"`
-	RefreshToken string `json:"refresh_token,omitempty"`
-	Role         string `json:"role"`
+	ID            int       `json:"id"`
+	Email         string    `json:"email"`
+	Password      string    `json:"-"`
+	Role          string    `json:"role"`
+	IsActive      bool      `json:"is_active"`
+	EmailVerified bool      `json:"email_verified"`
+	MFAEnabled    bool      `json:"mfa_enabled"`
+	FullName      string    `json:"full_name"`
+	Position      string    `json:"position"`
+	OrgUnit       string    `json:"org_unit"`
+	Phone         string    `json:"phone"`
+	AvatarKey     string    `json:"-"`
+	AvatarURL     string    `json:"avatar_url,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ProfileUpdate — изменяемые пользователем поля профиля; nil означает «не менять»
+type ProfileUpdate struct {
+	FullName *string `json:"full_name"`
+	Position *string `json:"position"`
+	OrgUnit  *string `json:"org_unit"`
+	Phone    *string `json:"phone"`
 }
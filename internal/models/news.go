@@ -2,10 +2,83 @@ package models
 
 import "time"
 
+// NewsStatus — этап жизненного цикла новости
+type NewsStatus string
+
+const (
+	NewsDraft     NewsStatus = "draft"
+	NewsInReview  NewsStatus = "in_review"
+	NewsScheduled NewsStatus = "scheduled"
+	NewsPublished NewsStatus = "published"
+	NewsArchived  NewsStatus = "archived"
+)
+
+// Editable — можно ли менять текст новости в этом статусе. Опубликованную или запланированную
+// новость сначала возвращают на рецензию, иначе правка попала бы к читателям без проверки.
+func (s NewsStatus) Editable() bool {
+	return s == NewsDraft || s == NewsInReview
+}
+
+// NeedsReviewer — в этот статус новость переводит только рецензент с правом news:publish
+func (s NewsStatus) NeedsReviewer() bool {
+	return s == NewsScheduled || s == NewsPublished || s == NewsArchived
+}
+
 type News struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	// Slug — адрес новости на публичном сайте; задаётся при создании и не меняется
+	Slug    string     `json:"slug"`
+	Content string     `json:"content"`
+	Status  NewsStatus `json:"status"`
+	// PublishAt — плановое время публикации, а после публикации — фактическое
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// UnpublishAt — время, когда планировщик переведёт новость в архив
+	UnpublishAt *time.Time `json:"unpublish_at,omitempty"`
+	AuthorID    *int       `json:"author_id,omitempty"`
+	ReviewerID  *int       `json:"reviewer_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	// Version увеличивается при каждом изменении и передаётся в ETag
+	Version int `json:"version"`
+}
+
+// IsPublic — видна ли новость читателям без прав редактора в момент now
+func (n *News) IsPublic(now time.Time) bool {
+	return n.Status == NewsPublished && (n.UnpublishAt == nil || n.UnpublishAt.After(now))
+}
+
+// PublicNews — опубликованная новость для анонимных читателей, без служебных полей
+type PublicNews struct {
+	ID      int    `json:"id"`
+	Slug    string `json:"slug"`
+	Title   string `json:"title"`
+	Excerpt string `json:"excerpt"`
+	// Content отдаётся только при запросе одной новости
+	Content     string    `json:"content,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewsInput — данные новости от клиента
+type NewsInput struct {
+	Title   string `json:"title" validate:"required,max=255"`
+	Content string `json:"content" validate:"required"`
+}
+
+// NewsTransition — запрос на смену статуса новости
+type NewsTransition struct {
+	Status NewsStatus `json:"status" validate:"required,oneof=draft in_review scheduled published archived"`
+	// PublishAt обязателен для статуса scheduled
+	PublishAt *time.Time `json:"publish_at"`
+	// UnpublishAt можно задать при планировании или публикации
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
+
+// NewsFilter — ограничения списка новостей
+type NewsFilter struct {
+	// PublishedOnly оставляет только новости, видимые читателям
+	PublishedOnly bool
+	// Status — только новости в этом статусе; пусто — любые
+	Status NewsStatus
 }
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Права доступа, проверяемые middleware.RequirePermission
+const (
+	PermNewsWrite          = "news:write"
+	PermNewsDelete         = "news:delete"
+	PermNewsPublish        = "news:publish"
+	PermDocumentsWrite     = "documents:write"
+	PermDocumentsDelete    = "documents:delete"
+	PermApplicationsWrite  = "applications:write"
+	PermApplicationsDelete = "applications:delete"
+	PermUsersManage        = "users:manage"
+	PermRolesManage        = "roles:manage"
+	PermAPIKeysManage      = "api_keys:manage"
+)
+
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Session — сессия пользователя на одном устройстве, к которой привязан refresh-токен
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     int       `json:"user_id"`
+	TokenHash  string    `json:"-"`
+	AccessJTI  string    `json:"-"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
@@ -0,0 +1,60 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const hashPrefixLen = 5
+
+// BreachedList — офлайн-список утёкших паролей в формате диапазонов Have I Been Pwned:
+// каталог с файлами <PREFIX>.txt, где PREFIX — первые 5 символов SHA-1 пароля (HEX, верхний регистр),
+// а каждая строка — оставшиеся 35 символов хеша и число утечек через двоеточие ("SUFFIX:COUNT").
+// Для проверки пароля читается только один файл с его префиксом.
+type BreachedList struct {
+	dir string
+}
+
+func OpenBreachedList(dir string) (*BreachedList, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("список утёкших паролей: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("список утёкших паролей: %s не является каталогом", dir)
+	}
+	return &BreachedList{dir: dir}, nil
+}
+
+// Contains сообщает, встречается ли пароль в списке; отсутствие файла префикса означает «не встречается»
+func (l *BreachedList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hashPrefixLen], hash[hashPrefixLen:]
+
+	f, err := os.Open(filepath.Join(l.dir, prefix+".txt"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		candidate, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		// Файлы с заполнением (padding) содержат строки с нулевым счётчиком
+		if strings.EqualFold(candidate, suffix) && count != "0" {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,138 @@
+package passwordpolicy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"rcoi/config"
+)
+
+// MaxBytes — ограничение bcrypt: более длинные пароли не хешируются
+const MaxBytes = 72
+
+// ViolationError перечисляет все нарушенные правила политики
+type ViolationError struct {
+	Violations []string
+}
+
+func (e *ViolationError) Error() string {
+	return "Ошибка пароля: " + strings.Join(e.Violations, "; ")
+}
+
+// Policy — требования к паролям: длина, классы символов, утечки и история
+type Policy struct {
+	cfg      config.PasswordConfig
+	breached *BreachedList
+}
+
+// New собирает политику из конфигурации; список утечек подключается, если задан PASSWORD_BREACHED_DIR
+func New(cfg config.PasswordConfig) (*Policy, error) {
+	if cfg.MinLength < 1 {
+		return nil, fmt.Errorf("минимальная длина пароля должна быть положительной: %d", cfg.MinLength)
+	}
+
+	p := &Policy{cfg: cfg}
+	if cfg.BreachedDir != "" {
+		list, err := OpenBreachedList(cfg.BreachedDir)
+		if err != nil {
+			return nil, err
+		}
+		p.breached = list
+	}
+	return p, nil
+}
+
+// HistorySize — сколько последних паролей, включая текущий, нельзя использовать снова
+func (p *Policy) HistorySize() int {
+	return p.cfg.HistorySize
+}
+
+// Validate проверяет пароль по всем правилам сразу и возвращает *ViolationError
+// со списком нарушений; прочие ошибки означают сбой чтения списка утечек
+func (p *Policy) Validate(password string) error {
+	var violations []string
+
+	if utf8.RuneCountInString(password) < p.cfg.MinLength {
+		violations = append(violations, fmt.Sprintf("Пароль должен быть не менее %d символов", p.cfg.MinLength))
+	}
+	if len(password) > MaxBytes {
+		violations = append(violations, fmt.Sprintf("Пароль не должен быть длиннее %d байт", MaxBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol, hasNonLatin bool
+	var rejected []string
+	for _, r := range password {
+		if r > unicode.MaxASCII && !p.cfg.AllowUnicode {
+			hasNonLatin = true
+			continue
+		}
+
+		switch {
+		case unicode.IsLetter(r):
+			hasUpper = hasUpper || unicode.IsUpper(r)
+			hasLower = hasLower || unicode.IsLower(r)
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case r == ' ' || unicode.IsPunct(r) || unicode.IsSymbol(r):
+			if p.cfg.AllowedSymbols != "" && !strings.ContainsRune(p.cfg.AllowedSymbols, r) {
+				rejected = appendRejected(rejected, r)
+				continue
+			}
+			hasSymbol = true
+		default:
+			rejected = appendRejected(rejected, r)
+		}
+	}
+
+	if hasNonLatin {
+		violations = append(violations, "Пароль должен содержать только латинские буквы, цифры и символы")
+	}
+	if len(rejected) > 0 {
+		violations = append(violations, "Пароль содержит недопустимые символы: "+strings.Join(rejected, ", "))
+	}
+	if p.cfg.RequireUpper && !hasUpper {
+		violations = append(violations, "Пароль должен содержать хотя бы одну заглавную букву")
+	}
+	if p.cfg.RequireLower && !hasLower {
+		violations = append(violations, "Пароль должен содержать хотя бы одну строчную букву")
+	}
+	if p.cfg.RequireDigit && !hasDigit {
+		violations = append(violations, "Пароль должен содержать хотя бы одну цифру")
+	}
+	if p.cfg.RequireSymbol && !hasSymbol {
+		violations = append(violations, "Пароль должен содержать хотя бы один специальный символ")
+	}
+
+	if p.breached != nil {
+		found, err := p.breached.Contains(password)
+		if err != nil {
+			return err
+		}
+		if found {
+			violations = append(violations, "Пароль встречается в утечках данных, выберите другой")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// Reused возвращает нарушение «пароль уже использовался»
+func (p *Policy) Reused() error {
+	return &ViolationError{Violations: []string{
+		fmt.Sprintf("Пароль совпадает с одним из %d последних паролей", p.cfg.HistorySize),
+	}}
+}
+
+// appendRejected добавляет символ в список недопустимых; %q делает видимыми пробел и управляющие символы
+func appendRejected(rejected []string, r rune) []string {
+	if quoted := fmt.Sprintf("%q", r); !slices.Contains(rejected, quoted) {
+		return append(rejected, quoted)
+	}
+	return rejected
+}
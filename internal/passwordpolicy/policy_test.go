@@ -0,0 +1,133 @@
+package passwordpolicy
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"rcoi/config"
+)
+
+func strictConfig() config.PasswordConfig {
+	return config.PasswordConfig{
+		MinLength:     10,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		HistorySize:   5,
+	}
+}
+
+func violations(t *testing.T, p *Policy, password string) []string {
+	t.Helper()
+	err := p.Validate(password)
+	if err == nil {
+		return nil
+	}
+	var violation *ViolationError
+	if !errors.As(err, &violation) {
+		t.Fatalf("Validate(%q) вернул не *ViolationError: %v", password, err)
+	}
+	return violation.Violations
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      func(*config.PasswordConfig)
+		password string
+		want     []string
+	}{
+		{"подходящий пароль", nil, "Correct-horse-1", nil},
+		{"все нарушения сразу", nil, "abc", []string{"не менее 10", "заглавную", "цифру", "специальный"}},
+		{"длиннее лимита bcrypt", nil, "Aa1!" + strings.Repeat("x", MaxBytes), []string{"72 байт"}},
+		{"кириллица запрещена", nil, "Пароль-Correct-1", []string{"только латинские"}},
+		{"кириллица разрешена", func(c *config.PasswordConfig) { c.AllowUnicode = true }, "Пароль-надёжный-1", nil},
+		{"длина считается в символах", func(c *config.PasswordConfig) {
+			c.AllowUnicode = true
+			c.RequireUpper, c.RequireDigit, c.RequireSymbol = false, false, false
+		}, "пароль-дом", nil},
+		{"символ вне списка", func(c *config.PasswordConfig) { c.AllowedSymbols = "!@#" }, "Correct horse-1!", []string{"недопустимые символы: ' ', '-'"}},
+		{"управляющий символ", nil, "Correct-horse-1\t", []string{`недопустимые символы: '\t'`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := strictConfig()
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+			p, err := New(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := violations(t, p, tt.password)
+			if len(got) != len(tt.want) {
+				t.Fatalf("нарушения = %q, want %d: %q", got, len(tt.want), tt.want)
+			}
+			for _, fragment := range tt.want {
+				if !slices.ContainsFunc(got, func(v string) bool { return strings.Contains(v, fragment) }) {
+					t.Errorf("нет нарушения с %q среди %q", fragment, got)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateBreached(t *testing.T) {
+	dir := t.TempDir()
+	sum := sha1.Sum([]byte("Correct-horse-1"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	// Строка с нулевым счётчиком — заполнение, а не утечка
+	padding := strings.Repeat("0", len(hash)-hashPrefixLen) + ":0\n"
+	content := padding + strings.ToLower(hash[hashPrefixLen:]) + ":42\n"
+	if err := os.WriteFile(filepath.Join(dir, hash[:hashPrefixLen]+".txt"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := strictConfig()
+	cfg.BreachedDir = dir
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := violations(t, p, "Correct-horse-1"); len(got) != 1 || !strings.Contains(got[0], "утечках") {
+		t.Fatalf("пароль из утечки: нарушения = %q", got)
+	}
+	if got := violations(t, p, "Correct-horse-2"); got != nil {
+		t.Fatalf("пароль вне утечки: нарушения = %q", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(config.PasswordConfig{MinLength: 0}); err == nil {
+		t.Error("принята нулевая минимальная длина")
+	}
+	cfg := strictConfig()
+	cfg.BreachedDir = filepath.Join(t.TempDir(), "missing")
+	if _, err := New(cfg); err == nil {
+		t.Error("принят несуществующий каталог утечек")
+	}
+}
+
+func TestReused(t *testing.T) {
+	p, err := New(strictConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var violation *ViolationError
+	if err := p.Reused(); !errors.As(err, &violation) || !strings.Contains(violation.Violations[0], "5 последних") {
+		t.Fatalf("Reused() = %v", err)
+	}
+	if p.HistorySize() != 5 {
+		t.Fatalf("HistorySize() = %d", p.HistorySize())
+	}
+}
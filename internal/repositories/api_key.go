@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"rcoi/internal/models"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetAll(ctx context.Context) ([]*models.APIKey, error)
+	GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	Revoke(ctx context.Context, id int) error
+	Touch(ctx context.Context, id int) error
+}
+
+type apiKeyRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) APIKeyRepository {
+	return &apiKeyRepo{db: db}
+}
+
+const apiKeySelect = `
+	SELECT k.id, k.name, k.prefix, k.key_hash, k.created_by, k.created_at, k.expires_at, k.last_used_at, k.revoked_at,
+	       COALESCE(array_agg(s.permission ORDER BY s.permission) FILTER (WHERE s.permission IS NOT NULL), '{}')
+	FROM api_keys k
+	LEFT JOIN api_key_scopes s ON s.api_key_id = k.id
+`
+
+func (r *apiKeyRepo) Create(ctx context.Context, key *models.APIKey) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	if err := tx.QueryRow(ctx, query, key.Name, key.Prefix, key.KeyHash, key.CreatedBy, key.ExpiresAt).
+		Scan(&key.ID, &key.CreatedAt); err != nil {
+		return dbError(err)
+	}
+
+	if len(key.Scopes) > 0 {
+		query = `INSERT INTO api_key_scopes (api_key_id, permission) SELECT $1, unnest($2::text[]) ON CONFLICT DO NOTHING`
+		if _, err := tx.Exec(ctx, query, key.ID, key.Scopes); err != nil {
+			return dbError(err)
+		}
+	}
+
+	return dbError(tx.Commit(ctx))
+}
+
+func (r *apiKeyRepo) GetAll(ctx context.Context) ([]*models.APIKey, error) {
+	rows, err := r.db.Query(ctx, apiKeySelect+` GROUP BY k.id ORDER BY k.created_at DESC`)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, dbError(err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, dbError(rows.Err())
+}
+
+// GetActiveByHash ищет неотозванный и неистёкший ключ, выпущенный активным пользователем.
+// В Scopes попадают только права, которые есть у роли этого пользователя сейчас:
+// снятые с администратора права перестают действовать и для его ключей.
+func (r *apiKeyRepo) GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `
+		SELECT k.id, k.name, k.prefix, k.key_hash, k.created_by, k.created_at, k.expires_at, k.last_used_at, k.revoked_at,
+		       COALESCE(array_agg(s.permission ORDER BY s.permission) FILTER (WHERE s.permission IS NOT NULL), '{}')
+		FROM api_keys k
+		JOIN users u ON u.email = k.created_by AND u.is_active
+		LEFT JOIN api_key_scopes s ON s.api_key_id = k.id
+		     AND s.permission IN (SELECT permission FROM role_permissions WHERE role = u.role)
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > NOW())
+		GROUP BY k.id
+	`
+	return scanAPIKey(r.db.QueryRow(ctx, query, keyHash))
+}
+
+func (r *apiKeyRepo) Revoke(ctx context.Context, id int) error {
+	return execAffected(ctx, r.db, `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+}
+
+// Touch обновляет время последнего использования ключа
+func (r *apiKeyRepo) Touch(ctx context.Context, id int) error {
+	_, err := r.db.Exec(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, id)
+	return dbError(err)
+}
+
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	var k models.APIKey
+	err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.KeyHash, &k.CreatedBy, &k.CreatedAt,
+		&k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.Scopes)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	return &k, nil
+}
@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"rcoi/internal/models"
 )
@@ -9,15 +11,27 @@ import (
 type ApplicationRepository interface {
 	Create(ctx context.Context, app *models.Application) error
 	GetByID(ctx context.Context, id int) (*models.Application, error)
-	GetAll(ctx context.Context) ([]*models.Application, error)
+	GetAll(ctx context.Context, q models.ListQuery) (*models.Page[*models.Application], error)
 	Update(ctx context.Context, app *models.Application) error
-	Delete(ctx context.Context, id int) error
+	Delete(ctx context.Context, id, version int) error
 }
 
 type applicationRepo struct {
 	db *pgxpool.Pool
 }
 
+var applicationListSpec = listSpec{
+	table:   "applications",
+	columns: "id, title, description, filename, url, created_at, version",
+	sortFields: map[string]sortField{
+		"id":         {column: "id", sqlType: "int"},
+		"title":      {column: "title", sqlType: "text"},
+		"created_at": {column: "created_at", sqlType: "timestamp"},
+	},
+	defaultSort:  "created_at",
+	searchColumn: "title",
+}
+
 func NewApplicationRepository(db *pgxpool.Pool) ApplicationRepository {
 	return &applicationRepo{db: db}
 }
@@ -26,59 +40,61 @@ func (r *applicationRepo) Create(ctx context.Context, app *models.Application) e
 	query := `
 		INSERT INTO applications (title, description, filename, url) 
 		VALUES ($1, $2, $3, $4) 
-		RETURNING id, created_at
+		RETURNING id, created_at, version
 	`
-	return r.db.QueryRow(ctx, query, app.Title, app.Description, app.Filename, app.URL).
-		Scan(&app.ID, &app.CreatedAt)
+	return dbError(r.db.QueryRow(ctx, query, app.Title, app.Description, app.Filename, app.URL).
+		Scan(&app.ID, &app.CreatedAt, &app.Version))
 }
 
 func (r *applicationRepo) GetByID(ctx context.Context, id int) (*models.Application, error) {
 	app := &models.Application{}
 	query := `
-		SELECT id, title, description, filename, url, created_at 
+		SELECT id, title, description, filename, url, created_at, version
 		FROM applications 
 		WHERE id = $1
 	`
 	err := r.db.QueryRow(ctx, query, id).
-		Scan(&app.ID, &app.Title, &app.Description, &app.Filename, &app.URL, &app.CreatedAt)
-	return app, err
-}
-
-func (r *applicationRepo) GetAll(ctx context.Context) ([]*models.Application, error) {
-	query := `
-		SELECT id, title, description, filename, url, created_at 
-		FROM applications 
-		ORDER BY created_at DESC
-	`
-	rows, err := r.db.Query(ctx, query)
+		Scan(&app.ID, &app.Title, &app.Description, &app.Filename, &app.URL, &app.CreatedAt, &app.Version)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
-	defer rows.Close()
+	return app, nil
+}
 
-	var apps []*models.Application
-	for rows.Next() {
+func (r *applicationRepo) GetAll(ctx context.Context, q models.ListQuery) (*models.Page[*models.Application], error) {
+	return queryPage(ctx, r.db, applicationListSpec, q, func(rows pgx.Rows, extra ...any) (*models.Application, error) {
 		var a models.Application
-		if err := rows.Scan(&a.ID, &a.Title, &a.Description, &a.Filename, &a.URL, &a.CreatedAt); err != nil {
+		dest := append([]any{&a.ID, &a.Title, &a.Description, &a.Filename, &a.URL, &a.CreatedAt, &a.Version}, extra...)
+		if err := rows.Scan(dest...); err != nil {
 			return nil, err
 		}
-		apps = append(apps, &a)
-	}
-	return apps, nil
+		return &a, nil
+	})
 }
 
+// Update меняет приложение версии app.Version и записывает в app новую версию.
+// Новый файл загружается только при создании, поэтому app.Filename либо прежний, либо пустой.
+// Если приложение успели изменить, возвращается ErrVersionMismatch.
 func (r *applicationRepo) Update(ctx context.Context, app *models.Application) error {
 	query := `
 		UPDATE applications 
-		SET title = $1, description = $2, filename = $3, url = $4
-		WHERE id = $5
+		SET title = $1, description = $2, url = $3, filename = $4, version = version + 1
+		WHERE id = $5 AND version = $6
+		RETURNING created_at, version
 	`
-	_, err := r.db.Exec(ctx, query, app.Title, app.Description, app.Filename, app.URL, app.ID)
-	return err
+	err := r.db.QueryRow(ctx, query, app.Title, app.Description, app.URL, app.Filename, app.ID, app.Version).
+		Scan(&app.CreatedAt, &app.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return missingOrStale(ctx, r.db, "applications", app.ID)
+	}
+	return dbError(err)
 }
 
-func (r *applicationRepo) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM applications WHERE id = $1`
-	_, err := r.db.Exec(ctx, query, id)
+// Delete удаляет приложение версии version
+func (r *applicationRepo) Delete(ctx context.Context, id, version int) error {
+	err := execAffected(ctx, r.db, `DELETE FROM applications WHERE id = $1 AND version = $2`, id, version)
+	if errors.Is(err, ErrNotFound) {
+		return missingOrStale(ctx, r.db, "applications", id)
+	}
 	return err
 }
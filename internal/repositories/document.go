@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"rcoi/internal/models"
 )
@@ -9,7 +10,7 @@ import (
 type DocumentRepository interface {
 	Create(ctx context.Context, doc *models.Document) error
 	GetByID(ctx context.Context, id int) (*models.Document, error)
-	GetAll(ctx context.Context) ([]*models.Document, error)
+	GetAll(ctx context.Context, q models.ListQuery) (*models.Page[*models.Document], error)
 	Delete(ctx context.Context, id int) error
 }
 
@@ -17,43 +18,48 @@ type documentRepo struct {
 	db *pgxpool.Pool
 }
 
+var documentListSpec = listSpec{
+	table:   "documents",
+	columns: "id, title, filename, created_at",
+	sortFields: map[string]sortField{
+		"id":         {column: "id", sqlType: "int"},
+		"title":      {column: "title", sqlType: "text"},
+		"created_at": {column: "created_at", sqlType: "timestamp"},
+	},
+	defaultSort:  "created_at",
+	searchColumn: "title",
+}
+
 func NewDocumentRepository(db *pgxpool.Pool) DocumentRepository {
 	return &documentRepo{db: db}
 }
 
 func (r *documentRepo) Create(ctx context.Context, doc *models.Document) error {
 	query := `INSERT INTO documents (title, filename) VALUES ($1, $2) RETURNING id, created_at`
-	return r.db.QueryRow(ctx, query, doc.Title, doc.Filename).Scan(&doc.ID, &doc.CreatedAt)
+	return dbError(r.db.QueryRow(ctx, query, doc.Title, doc.Filename).Scan(&doc.ID, &doc.CreatedAt))
 }
 
 func (r *documentRepo) GetByID(ctx context.Context, id int) (*models.Document, error) {
 	doc := &models.Document{}
 	query := `SELECT id, title, filename, created_at FROM documents WHERE id = $1`
 	err := r.db.QueryRow(ctx, query, id).Scan(&doc.ID, &doc.Title, &doc.Filename, &doc.CreatedAt)
-	return doc, err
-}
-
-func (r *documentRepo) GetAll(ctx context.Context) ([]*models.Document, error) {
-	query := `SELECT id, title, filename, created_at FROM documents ORDER BY created_at DESC`
-	rows, err := r.db.Query(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
-	defer rows.Close()
+	return doc, nil
+}
 
-	var docs []*models.Document
-	for rows.Next() {
+func (r *documentRepo) GetAll(ctx context.Context, q models.ListQuery) (*models.Page[*models.Document], error) {
+	return queryPage(ctx, r.db, documentListSpec, q, func(rows pgx.Rows, extra ...any) (*models.Document, error) {
 		var d models.Document
-		if err := rows.Scan(&d.ID, &d.Title, &d.Filename, &d.CreatedAt); err != nil {
+		dest := append([]any{&d.ID, &d.Title, &d.Filename, &d.CreatedAt}, extra...)
+		if err := rows.Scan(dest...); err != nil {
 			return nil, err
 		}
-		docs = append(docs, &d)
-	}
-	return docs, nil
+		return &d, nil
+	})
 }
 
 func (r *documentRepo) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM documents WHERE id = $1`
-	_, err := r.db.Exec(ctx, query, id)
-	return err
+	return execAffected(ctx, r.db, `DELETE FROM documents WHERE id = $1`, id)
 }
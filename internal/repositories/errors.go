@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Ошибки репозиториев не зависят от драйвера: сервисы проверяют их через errors.Is
+var (
+	ErrNotFound   = errors.New("запись не найдена")
+	ErrConflict   = errors.New("запись с такими данными уже существует")
+	ErrForeignKey = errors.New("нарушена ссылка на связанную запись")
+	// ErrVersionMismatch — запись существует, но её версия отличается от ожидаемой
+	ErrVersionMismatch = errors.New("запись изменена другим запросом")
+)
+
+// Коды ошибок PostgreSQL (SQLSTATE)
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// dbError переводит ошибки pgx и PostgreSQL в ошибки пакета.
+// Для нарушений ограничений исходная ошибка остаётся в цепочке, чтобы в журнале было видно имя ограничения.
+func dbError(err error) error {
+	if err == nil || errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) || errors.Is(err, ErrForeignKey) {
+		return err
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return fmt.Errorf("%w: %w", ErrConflict, err)
+		case pgForeignKeyViolation:
+			return fmt.Errorf("%w: %w", ErrForeignKey, err)
+		}
+	}
+	return err
+}
+
+// missingOrStale выясняет, почему условное изменение записи id не затронуло ни одной строки:
+// записи нет (ErrNotFound) или у неё уже другая версия (ErrVersionMismatch)
+func missingOrStale(ctx context.Context, db *pgxpool.Pool, table string, id int) error {
+	var exists bool
+	if err := db.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM "+table+" WHERE id = $1)", id).Scan(&exists); err != nil {
+		return dbError(err)
+	}
+	if exists {
+		return ErrVersionMismatch
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestDBError(t *testing.T) {
+	unique := &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "users_email_key"}
+	foreignKey := &pgconn.PgError{Code: pgForeignKeyViolation, ConstraintName: "news_author_id_fkey"}
+	other := &pgconn.PgError{Code: "42P01"}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"nil", nil, nil},
+		{"no rows", pgx.ErrNoRows, ErrNotFound},
+		{"wrapped no rows", fmt.Errorf("scan: %w", pgx.ErrNoRows), ErrNotFound},
+		{"unique violation", unique, ErrConflict},
+		{"foreign key violation", foreignKey, ErrForeignKey},
+		{"other postgres error", other, other},
+		{"already mapped", ErrNotFound, ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dbError(tt.err)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("dbError() = %v, want nil", got)
+				}
+				return
+			}
+			if !errors.Is(got, tt.want) {
+				t.Fatalf("dbError() = %v, want errors.Is %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDBErrorKeepsPostgresErrorForLog(t *testing.T) {
+	pgErr := &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "users_email_key"}
+
+	err := dbError(pgErr)
+	var got *pgconn.PgError
+	if !errors.As(err, &got) || got.ConstraintName != "users_email_key" {
+		t.Fatalf("исходная ошибка PostgreSQL потеряна: %v", err)
+	}
+	if again := dbError(err); again != err {
+		t.Fatalf("повторный dbError изменил ошибку: %v", again)
+	}
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// execAffected выполняет запрос и возвращает ErrNotFound, если ни одна строка не изменена
+func execAffected(ctx context.Context, db *pgxpool.Pool, query string, args ...any) error {
+	tag, err := db.Exec(ctx, query, args...)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IdentityRepository хранит привязку учётных записей внешних провайдеров к пользователям
+type IdentityRepository interface {
+	Lookup(ctx context.Context, issuer, subject, email string) (int, error)
+	Link(ctx context.Context, issuer, subject string, userID int, email string) error
+}
+
+type identityRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewIdentityRepository(db *pgxpool.Pool) IdentityRepository {
+	return &identityRepo{db: db}
+}
+
+// Lookup возвращает ID пользователя, привязанного к учётной записи провайдера, и отмечает вход
+func (r *identityRepo) Lookup(ctx context.Context, issuer, subject, email string) (int, error) {
+	query := `
+		UPDATE user_identities SET last_login_at = NOW(), email = $3
+		WHERE issuer = $1 AND subject = $2
+		RETURNING user_id
+	`
+	var userID int
+	err := r.db.QueryRow(ctx, query, issuer, subject, email).Scan(&userID)
+	return userID, dbError(err)
+}
+
+func (r *identityRepo) Link(ctx context.Context, issuer, subject string, userID int, email string) error {
+	query := `INSERT INTO user_identities (issuer, subject, user_id, email) VALUES ($1, $2, $3, $4)`
+	_, err := r.db.Exec(ctx, query, issuer, subject, userID, email)
+	return dbError(err)
+}
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"rcoi/internal/models"
+)
+
+// sortField — поле, по которому разрешена сортировка
+type sortField struct {
+	column  string
+	sqlType string // тип для приведения значения из курсора
+}
+
+// listSpec описывает таблицу для постраничной выборки
+type listSpec struct {
+	table       string
+	columns     string
+	sortFields  map[string]sortField
+	defaultSort string
+	// searchColumn — колонка, по которой ищет ListQuery.Search: title у контента, email у пользователей
+	searchColumn string
+	// where — постоянное условие выборки с параметрами $1..$N из whereArgs; пусто — все строки
+	where     string
+	whereArgs []any
+}
+
+// cursor хранит позицию последней выданной строки для keyset-пагинации
+type cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("%w: неверный курсор", models.ErrInvalidListQuery)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("%w: неверный курсор", models.ErrInvalidListQuery)
+	}
+	return c, nil
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// queryPage выполняет выборку страницы по спецификации таблицы.
+// scan должен считать колонки spec.columns и затем extra.
+func queryPage[T any](ctx context.Context, db *pgxpool.Pool, spec listSpec, q models.ListQuery, scan func(rows pgx.Rows, extra ...any) (T, error)) (*models.Page[T], error) {
+	sortName, desc := q.Sort, q.Desc
+	if sortName == "" {
+		sortName, desc = spec.defaultSort, true
+	}
+	field, ok := spec.sortFields[sortName]
+	if !ok {
+		return nil, fmt.Errorf("%w: сортировка по полю %q не поддерживается", models.ErrInvalidListQuery, sortName)
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = models.DefaultListLimit
+	}
+	if limit > models.MaxListLimit {
+		limit = models.MaxListLimit
+	}
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	var where []string
+	if spec.where != "" {
+		args = append(args, spec.whereArgs...)
+		where = append(where, spec.where)
+	}
+	if q.CreatedFrom != nil {
+		where = append(where, "created_at >= "+arg(*q.CreatedFrom))
+	}
+	if q.CreatedTo != nil {
+		where = append(where, "created_at < "+arg(*q.CreatedTo))
+	}
+	if q.Search != "" {
+		where = append(where, spec.searchColumn+" ILIKE '%' || "+arg(escapeLike(q.Search))+" || '%'")
+	}
+
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	page := &models.Page[T]{Items: make([]T, 0), Limit: limit}
+
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM "+spec.table+whereSQL, args...).Scan(&page.Total); err != nil {
+		return nil, dbError(err)
+	}
+
+	direction, cmp := "ASC", ">"
+	if desc {
+		direction, cmp = "DESC", "<"
+	}
+
+	offset := 0
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sort != sortName || c.Desc != desc {
+			return nil, fmt.Errorf("%w: курсор не соответствует сортировке", models.ErrInvalidListQuery)
+		}
+		cond := fmt.Sprintf("(%s, id) %s (%s::%s, %s)", field.column, cmp, arg(c.Value), field.sqlType, arg(c.ID))
+		if whereSQL == "" {
+			whereSQL = " WHERE " + cond
+		} else {
+			whereSQL += " AND " + cond
+		}
+	} else if q.Offset > 0 {
+		offset = q.Offset
+		page.Offset = offset
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s, %s::text, id FROM %s%s ORDER BY %s %s, id %s LIMIT %d OFFSET %d",
+		spec.columns, field.column, spec.table, whereSQL, field.column, direction, direction, limit+1, offset,
+	)
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	var last cursor
+	for rows.Next() {
+		if len(page.Items) == limit {
+			page.NextCursor = encodeCursor(last)
+			break
+		}
+
+		last = cursor{Sort: sortName, Desc: desc}
+		item, err := scan(rows, &last.Value, &last.ID)
+		if err != nil {
+			return nil, dbError(err)
+		}
+		page.Items = append(page.Items, item)
+	}
+
+	return page, dbError(rows.Err())
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"rcoi/internal/models"
+)
+
+type MFARepository interface {
+	GetTOTP(ctx context.Context, userID int) (*models.TOTP, error)
+	SetPendingSecret(ctx context.Context, userID int, secret string) error
+	Enable(ctx context.Context, userID int, step int64, codeHashes []string) error
+	Disable(ctx context.Context, userID int) error
+	UseStep(ctx context.Context, userID int, step int64) error
+	ConsumeRecoveryCode(ctx context.Context, userID int, codeHash string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	CountRecoveryCodes(ctx context.Context, userID int) (int, error)
+}
+
+type mfaRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewMFARepository(db *pgxpool.Pool) MFARepository {
+	return &mfaRepo{db: db}
+}
+
+func (r *mfaRepo) GetTOTP(ctx context.Context, userID int) (*models.TOTP, error) {
+	t := &models.TOTP{}
+	query := `SELECT COALESCE(totp_secret, ''), mfa_enabled, totp_last_step FROM users WHERE id = $1`
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&t.Secret, &t.Enabled, &t.LastStep); err != nil {
+		return nil, dbError(err)
+	}
+	return t, nil
+}
+
+// SetPendingSecret сохраняет секрет до подтверждения; для уже подключённого TOTP возвращает ErrNotFound
+func (r *mfaRepo) SetPendingSecret(ctx context.Context, userID int, secret string) error {
+	query := `UPDATE users SET totp_secret = $2, totp_last_step = 0 WHERE id = $1 AND NOT mfa_enabled`
+	return execAffected(ctx, r.db, query, userID, secret)
+}
+
+// Enable включает TOTP и заменяет коды восстановления
+func (r *mfaRepo) Enable(ctx context.Context, userID int, step int64, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE users SET mfa_enabled = TRUE, totp_last_step = $2
+		WHERE id = $1 AND totp_secret IS NOT NULL AND NOT mfa_enabled
+	`
+	tag, err := tx.Exec(ctx, query, userID, step)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return dbError(err)
+	}
+
+	return dbError(tx.Commit(ctx))
+}
+
+func (r *mfaRepo) Disable(ctx context.Context, userID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE users SET totp_secret = NULL, mfa_enabled = FALSE, totp_last_step = 0 WHERE id = $1`
+	tag, err := tx.Exec(ctx, query, userID)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return dbError(err)
+	}
+
+	return dbError(tx.Commit(ctx))
+}
+
+// UseStep фиксирует принятый временной шаг TOTP; шаг не новее последнего даёт ErrNotFound
+func (r *mfaRepo) UseStep(ctx context.Context, userID int, step int64) error {
+	query := `UPDATE users SET totp_last_step = $2 WHERE id = $1 AND totp_last_step < $2`
+	return execAffected(ctx, r.db, query, userID, step)
+}
+
+// ConsumeRecoveryCode гасит неиспользованный код восстановления или возвращает ErrNotFound
+func (r *mfaRepo) ConsumeRecoveryCode(ctx context.Context, userID int, codeHash string) error {
+	query := `
+		UPDATE mfa_recovery_codes SET used_at = NOW()
+		WHERE id = (
+			SELECT id FROM mfa_recovery_codes
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+			LIMIT 1
+		)
+	`
+	return execAffected(ctx, r.db, query, userID, codeHash)
+}
+
+func (r *mfaRepo) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return dbError(err)
+	}
+
+	return dbError(tx.Commit(ctx))
+}
+
+func (r *mfaRepo) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	err := r.db.QueryRow(ctx, query, userID).Scan(&count)
+	return count, dbError(err)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return dbError(err)
+	}
+
+	if len(codeHashes) == 0 {
+		return nil
+	}
+	query := `INSERT INTO mfa_recovery_codes (user_id, code_hash) SELECT $1, unnest($2::text[])`
+	_, err := tx.Exec(ctx, query, userID, codeHashes)
+	return dbError(err)
+}
@@ -2,65 +2,200 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"rcoi/internal/models"
+	"time"
 )
 
 type NewsRepository interface {
 	Create(ctx context.Context, news *models.News) error
 	GetByID(ctx context.Context, id int) (*models.News, error)
-	GetAll(ctx context.Context) ([]*models.News, error)
+	GetAll(ctx context.Context, q models.ListQuery, filter models.NewsFilter) (*models.Page[*models.News], error)
 	Update(ctx context.Context, news *models.News) error
-	Delete(ctx context.Context, id int) error
+	SetStatus(ctx context.Context, news *models.News) error
+	// GetPublished и GetPublishedBySlug возвращают только новости, видимые читателям
+	GetPublished(ctx context.Context, q models.ListQuery) (*models.Page[*models.News], error)
+	GetPublishedBySlug(ctx context.Context, slug string) (*models.News, error)
+	// LastModified — время последнего события, которое могло изменить набор или содержимое
+	// опубликованных новостей: правки или смены статуса, удаления, истечения unpublish_at.
+	// Нулевое, если таких событий не было.
+	LastModified(ctx context.Context) (time.Time, error)
+	Delete(ctx context.Context, id, version int) error
+	// PublishDue публикует запланированные новости, время которых наступило,
+	// и архивирует опубликованные, у которых истёк unpublish_at
+	PublishDue(ctx context.Context) (published, archived int64, err error)
 }
 
 type newsRepo struct {
 	db *pgxpool.Pool
 }
 
+const newsColumns = "id, title, slug, content, status, publish_at, unpublish_at, author_id, reviewer_id, created_at, updated_at, version"
+
+// newsPublicCondition — новости, которые видят читатели; совпадает с models.News.IsPublic
+const newsPublicCondition = "status = 'published' AND (unpublish_at IS NULL OR unpublish_at > NOW())"
+
+var newsListSpec = listSpec{
+	table:   "news",
+	columns: newsColumns,
+	sortFields: map[string]sortField{
+		"id":         {column: "id", sqlType: "int"},
+		"title":      {column: "title", sqlType: "text"},
+		"created_at": {column: "created_at", sqlType: "timestamp"},
+		"updated_at": {column: "updated_at", sqlType: "timestamp"},
+	},
+	defaultSort:  "created_at",
+	searchColumn: "title",
+}
+
+// publicNewsListSpec — список опубликованных новостей; publish_at у них всегда заполнен
+var publicNewsListSpec = listSpec{
+	table:   "news",
+	columns: newsColumns,
+	sortFields: map[string]sortField{
+		"published_at": {column: "publish_at", sqlType: "timestamptz"},
+		"title":        {column: "title", sqlType: "text"},
+	},
+	defaultSort:  "published_at",
+	searchColumn: "title",
+	where:        newsPublicCondition,
+}
+
 func NewNewsRepository(db *pgxpool.Pool) NewsRepository {
 	return &newsRepo{db: db}
 }
 
+// newsFields — адреса полей новости в порядке newsColumns
+func newsFields(n *models.News) []any {
+	return []any{&n.ID, &n.Title, &n.Slug, &n.Content, &n.Status, &n.PublishAt, &n.UnpublishAt,
+		&n.AuthorID, &n.ReviewerID, &n.CreatedAt, &n.UpdatedAt, &n.Version}
+}
+
 func (r *newsRepo) Create(ctx context.Context, news *models.News) error {
-	query := `INSERT INTO news (title, content) VALUES ($1, $2) RETURNING id, created_at, updated_at`
-	return r.db.QueryRow(ctx, query, news.Title, news.Content).Scan(&news.ID, &news.CreatedAt, &news.UpdatedAt)
+	query := `INSERT INTO news (title, slug, content, author_id) VALUES ($1, $2, $3, $4) RETURNING ` + newsColumns
+	return dbError(r.db.QueryRow(ctx, query, news.Title, news.Slug, news.Content, news.AuthorID).Scan(newsFields(news)...))
 }
 
 func (r *newsRepo) GetByID(ctx context.Context, id int) (*models.News, error) {
 	news := &models.News{}
-	query := `SELECT id, title, content, created_at, updated_at FROM news WHERE id = $1`
-	err := r.db.QueryRow(ctx, query, id).Scan(&news.ID, &news.Title, &news.Content, &news.CreatedAt, &news.UpdatedAt)
-	return news, err
+	query := `SELECT ` + newsColumns + ` FROM news WHERE id = $1`
+	if err := r.db.QueryRow(ctx, query, id).Scan(newsFields(news)...); err != nil {
+		return nil, dbError(err)
+	}
+	return news, nil
 }
 
-func (r *newsRepo) GetAll(ctx context.Context) ([]*models.News, error) {
-	query := `SELECT id, title, content, created_at, updated_at FROM news ORDER BY created_at DESC`
-	rows, err := r.db.Query(ctx, query)
-	if err != nil {
-		return nil, err
+func (r *newsRepo) GetAll(ctx context.Context, q models.ListQuery, filter models.NewsFilter) (*models.Page[*models.News], error) {
+	spec := newsListSpec
+	switch {
+	case filter.PublishedOnly:
+		spec.where = newsPublicCondition
+	case filter.Status != "":
+		spec.where, spec.whereArgs = "status = $1", []any{filter.Status}
 	}
-	defer rows.Close()
-
-	var newsList []*models.News
-	for rows.Next() {
-		var n models.News
-		if err := rows.Scan(&n.ID, &n.Title, &n.Content, &n.CreatedAt, &n.UpdatedAt); err != nil {
-			return nil, err
-		}
-		newsList = append(newsList, &n)
+
+	return queryPage(ctx, r.db, spec, q, scanNews)
+}
+
+func (r *newsRepo) GetPublished(ctx context.Context, q models.ListQuery) (*models.Page[*models.News], error) {
+	return queryPage(ctx, r.db, publicNewsListSpec, q, scanNews)
+}
+
+func (r *newsRepo) GetPublishedBySlug(ctx context.Context, slug string) (*models.News, error) {
+	news := &models.News{}
+	query := `SELECT ` + newsColumns + ` FROM news WHERE slug = $1 AND ` + newsPublicCondition
+	if err := r.db.QueryRow(ctx, query, slug).Scan(newsFields(news)...); err != nil {
+		return nil, dbError(err)
 	}
-	return newsList, nil
+	return news, nil
 }
 
+func (r *newsRepo) LastModified(ctx context.Context) (time.Time, error) {
+	// Новость с истёкшим unpublish_at пропадает из выдачи сразу, ещё до того, как
+	// планировщик переведёт её в архив и обновит updated_at
+	query := `
+		SELECT GREATEST(
+			(SELECT MAX(updated_at) FROM news),
+			(SELECT MAX(unpublish_at) FROM news WHERE unpublish_at <= NOW()),
+			(SELECT last_deleted_at FROM news_changes)
+		)`
+	var last *time.Time
+	if err := r.db.QueryRow(ctx, query).Scan(&last); err != nil || last == nil {
+		return time.Time{}, dbError(err)
+	}
+	return *last, nil
+}
+
+func scanNews(rows pgx.Rows, extra ...any) (*models.News, error) {
+	var n models.News
+	if err := rows.Scan(append(newsFields(&n), extra...)...); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// Update сохраняет новость, если её версия всё ещё равна news.Version, и записывает в news новую версию.
+// Если новость успели изменить, возвращается ErrVersionMismatch.
 func (r *newsRepo) Update(ctx context.Context, news *models.News) error {
-	query := `UPDATE news SET title = $1, content = $2, updated_at = NOW() WHERE id = $3`
-	_, err := r.db.Exec(ctx, query, news.Title, news.Content, news.ID)
-	return err
+	query := `
+		UPDATE news SET title = $1, content = $2, updated_at = NOW(), version = version + 1
+		WHERE id = $3 AND version = $4
+		RETURNING ` + newsColumns
+	err := r.db.QueryRow(ctx, query, news.Title, news.Content, news.ID, news.Version).Scan(newsFields(news)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return missingOrStale(ctx, r.db, "news", news.ID)
+	}
+	return dbError(err)
+}
+
+// SetStatus сохраняет статус, время публикации и рецензента новости версии news.Version
+func (r *newsRepo) SetStatus(ctx context.Context, news *models.News) error {
+	query := `
+		UPDATE news SET status = $1, publish_at = $2, unpublish_at = $3, reviewer_id = $4,
+		                updated_at = NOW(), version = version + 1
+		WHERE id = $5 AND version = $6
+		RETURNING ` + newsColumns
+	err := r.db.QueryRow(ctx, query, news.Status, news.PublishAt, news.UnpublishAt, news.ReviewerID, news.ID, news.Version).
+		Scan(newsFields(news)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return missingOrStale(ctx, r.db, "news", news.ID)
+	}
+	return dbError(err)
 }
 
-func (r *newsRepo) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM news WHERE id = $1`
-	_, err := r.db.Exec(ctx, query, id)
+// Delete удаляет новость версии version и запоминает время удаления для LastModified
+func (r *newsRepo) Delete(ctx context.Context, id, version int) error {
+	// Строка news_changes одна, поэтому UPDATE затрагивает её только если новость удалена
+	query := `
+		WITH deleted AS (DELETE FROM news WHERE id = $1 AND version = $2 RETURNING id)
+		UPDATE news_changes SET last_deleted_at = NOW() WHERE EXISTS (SELECT 1 FROM deleted)`
+	err := execAffected(ctx, r.db, query, id, version)
+	if errors.Is(err, ErrNotFound) {
+		return missingOrStale(ctx, r.db, "news", id)
+	}
 	return err
 }
+
+// Оба запроса можно безопасно выполнять одновременно с нескольких реплик:
+// строка меняется только один раз, пока её статус соответствует условию.
+func (r *newsRepo) PublishDue(ctx context.Context) (published, archived int64, err error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE news SET status = 'published', updated_at = NOW(), version = version + 1
+		WHERE status = 'scheduled' AND publish_at <= NOW()
+	`)
+	if err != nil {
+		return 0, 0, dbError(err)
+	}
+	published = tag.RowsAffected()
+
+	tag, err = r.db.Exec(ctx, `
+		UPDATE news SET status = 'archived', updated_at = NOW(), version = version + 1
+		WHERE status = 'published' AND unpublish_at <= NOW()
+	`)
+	if err != nil {
+		return published, 0, dbError(err)
+	}
+	return published, tag.RowsAffected(), nil
+}
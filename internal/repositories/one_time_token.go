@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OneTimeTokenRepository хранит хеши одноразовых токенов из писем
+// (сброс пароля, подтверждение email)
+type OneTimeTokenRepository interface {
+	Create(ctx context.Context, userID int, tokenHash string, ttl time.Duration) error
+	Peek(ctx context.Context, tokenHash string) (int, error)
+	Consume(ctx context.Context, tokenHash string) (int, error)
+}
+
+type oneTimeTokenRepo struct {
+	db    *pgxpool.Pool
+	table string
+}
+
+func NewPasswordResetRepository(db *pgxpool.Pool) OneTimeTokenRepository {
+	return &oneTimeTokenRepo{db: db, table: "password_reset_tokens"}
+}
+
+func NewEmailVerificationRepository(db *pgxpool.Pool) OneTimeTokenRepository {
+	return &oneTimeTokenRepo{db: db, table: "email_verification_tokens"}
+}
+
+// Create сохраняет новый токен и аннулирует ранее выданные токены пользователя
+func (r *oneTimeTokenRepo) Create(ctx context.Context, userID int, tokenHash string, ttl time.Duration) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM `+r.table+` WHERE user_id = $1`, userID); err != nil {
+		return dbError(err)
+	}
+
+	query := `INSERT INTO ` + r.table + ` (user_id, token_hash, expires_at) VALUES ($1, $2, NOW() + make_interval(secs => $3))`
+	if _, err := tx.Exec(ctx, query, userID, tokenHash, ttl.Seconds()); err != nil {
+		return dbError(err)
+	}
+
+	return dbError(tx.Commit(ctx))
+}
+
+// Peek возвращает владельца действующего токена, не погашая его
+func (r *oneTimeTokenRepo) Peek(ctx context.Context, tokenHash string) (int, error) {
+	query := `SELECT user_id FROM ` + r.table + ` WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()`
+	var userID int
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(&userID)
+	return userID, dbError(err)
+}
+
+// Consume помечает действующий токен использованным и возвращает ID пользователя.
+// Если токен не найден, просрочен или уже использован, возвращается ErrNotFound.
+func (r *oneTimeTokenRepo) Consume(ctx context.Context, tokenHash string) (int, error) {
+	query := `
+		UPDATE ` + r.table + `
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`
+	var userID int
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(&userID)
+	return userID, dbError(err)
+}
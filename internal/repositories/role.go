@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"rcoi/internal/models"
+)
+
+type RoleRepository interface {
+	Create(ctx context.Context, role *models.Role) error
+	GetByName(ctx context.Context, name string) (*models.Role, error)
+	GetAll(ctx context.Context) ([]*models.Role, error)
+	GetPermissions(ctx context.Context, role string) ([]string, error)
+	SetPermissions(ctx context.Context, role string, permissions []string) error
+	Delete(ctx context.Context, name string) error
+	GetAllPermissions(ctx context.Context) ([]*models.Permission, error)
+}
+
+type roleRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRoleRepository(db *pgxpool.Pool) RoleRepository {
+	return &roleRepo{db: db}
+}
+
+func (r *roleRepo) Create(ctx context.Context, role *models.Role) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO roles (name, description) VALUES ($1, $2) RETURNING created_at`
+	if err := tx.QueryRow(ctx, query, role.Name, role.Description).Scan(&role.CreatedAt); err != nil {
+		return dbError(err)
+	}
+
+	if err := insertRolePermissions(ctx, tx, role.Name, role.Permissions); err != nil {
+		return dbError(err)
+	}
+
+	return dbError(tx.Commit(ctx))
+}
+
+func (r *roleRepo) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	role := &models.Role{}
+	query := `SELECT name, description, created_at FROM roles WHERE name = $1`
+	if err := r.db.QueryRow(ctx, query, name).Scan(&role.Name, &role.Description, &role.CreatedAt); err != nil {
+		return nil, dbError(err)
+	}
+
+	permissions, err := r.GetPermissions(ctx, name)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	role.Permissions = permissions
+
+	return role, nil
+}
+
+func (r *roleRepo) GetAll(ctx context.Context) ([]*models.Role, error) {
+	query := `
+		SELECT r.name, r.description, r.created_at,
+		       COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role = r.name
+		GROUP BY r.name
+		ORDER BY r.name
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	var roles []*models.Role
+	for rows.Next() {
+		var role models.Role
+		if err := rows.Scan(&role.Name, &role.Description, &role.CreatedAt, &role.Permissions); err != nil {
+			return nil, dbError(err)
+		}
+		roles = append(roles, &role)
+	}
+	return roles, dbError(rows.Err())
+}
+
+func (r *roleRepo) GetPermissions(ctx context.Context, role string) ([]string, error) {
+	query := `SELECT permission FROM role_permissions WHERE role = $1 ORDER BY permission`
+	rows, err := r.db.Query(ctx, query, role)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	values, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	return values, dbError(err)
+}
+
+func (r *roleRepo) SetPermissions(ctx context.Context, role string, permissions []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `SELECT 1 FROM roles WHERE name = $1 FOR UPDATE`, role)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM role_permissions WHERE role = $1`, role); err != nil {
+		return dbError(err)
+	}
+
+	if err := insertRolePermissions(ctx, tx, role, permissions); err != nil {
+		return dbError(err)
+	}
+
+	return dbError(tx.Commit(ctx))
+}
+
+func (r *roleRepo) Delete(ctx context.Context, name string) error {
+	return execAffected(ctx, r.db, `DELETE FROM roles WHERE name = $1`, name)
+}
+
+func (r *roleRepo) GetAllPermissions(ctx context.Context) ([]*models.Permission, error) {
+	rows, err := r.db.Query(ctx, `SELECT name, description FROM permissions ORDER BY name`)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	var permissions []*models.Permission
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.Name, &p.Description); err != nil {
+			return nil, dbError(err)
+		}
+		permissions = append(permissions, &p)
+	}
+	return permissions, dbError(rows.Err())
+}
+
+func insertRolePermissions(ctx context.Context, tx pgx.Tx, role string, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+	query := `INSERT INTO role_permissions (role, permission) SELECT $1, unnest($2::text[]) ON CONFLICT DO NOTHING`
+	_, err := tx.Exec(ctx, query, role, permissions)
+	return dbError(err)
+}
@@ -0,0 +1,208 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"rcoi/internal/models"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session, ttl time.Duration) error
+	GetByID(ctx context.Context, id string) (*models.Session, error)
+	GetActiveByUser(ctx context.Context, userID int) ([]*models.Session, error)
+	GetAccessJTIs(ctx context.Context, userID int) ([]string, error)
+	GetAccessJTIsByRole(ctx context.Context, role string) ([]string, error)
+	Rotate(ctx context.Context, session *models.Session, oldHash, consumedJTI string, ttl time.Duration) (string, error)
+	GetConsumed(ctx context.Context, jti string) (string, error)
+	Revoke(ctx context.Context, id string) (string, error)
+	RevokeForUser(ctx context.Context, userID int, id string) (string, error)
+	RevokeAllForUser(ctx context.Context, userID int, exceptID string) ([]string, error)
+}
+
+type sessionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSessionRepository(db *pgxpool.Pool) SessionRepository {
+	return &sessionRepo{db: db}
+}
+
+const activeSession = `revoked_at IS NULL AND expires_at > NOW()`
+
+func (r *sessionRepo) Create(ctx context.Context, s *models.Session, ttl time.Duration) error {
+	query := `
+		INSERT INTO sessions (id, user_id, token_hash, access_jti, user_agent, ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW() + make_interval(secs => $7))
+		RETURNING created_at, last_used_at, expires_at
+	`
+	return dbError(r.db.QueryRow(ctx, query, s.ID, s.UserID, s.TokenHash, s.AccessJTI, s.UserAgent, s.IP, ttl.Seconds()).
+		Scan(&s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt))
+}
+
+// GetByID возвращает действующую сессию или ErrNotFound
+func (r *sessionRepo) GetByID(ctx context.Context, id string) (*models.Session, error) {
+	s := &models.Session{}
+	query := `
+		SELECT id, user_id, token_hash, user_agent, ip, created_at, last_used_at, expires_at
+		FROM sessions
+		WHERE id = $1 AND ` + activeSession
+	err := r.db.QueryRow(ctx, query, id).
+		Scan(&s.ID, &s.UserID, &s.TokenHash, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	return s, nil
+}
+
+func (r *sessionRepo) GetActiveByUser(ctx context.Context, userID int) ([]*models.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip, created_at, last_used_at, expires_at
+		FROM sessions
+		WHERE user_id = $1 AND ` + activeSession + `
+		ORDER BY last_used_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*models.Session, 0)
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt); err != nil {
+			return nil, dbError(err)
+		}
+		sessions = append(sessions, &s)
+	}
+	return sessions, dbError(rows.Err())
+}
+
+// GetAccessJTIs возвращает jti последних access-токенов действующих сессий пользователя
+func (r *sessionRepo) GetAccessJTIs(ctx context.Context, userID int) ([]string, error) {
+	query := `SELECT access_jti::text FROM sessions WHERE user_id = $1 AND access_jti IS NOT NULL AND ` + activeSession
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	values, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	return values, dbError(err)
+}
+
+// GetAccessJTIsByRole возвращает jti последних access-токенов действующих сессий всех пользователей с ролью role
+func (r *sessionRepo) GetAccessJTIsByRole(ctx context.Context, role string) ([]string, error) {
+	query := `
+		SELECT s.access_jti::text FROM sessions s JOIN users u ON u.id = s.user_id
+		WHERE u.role = $1 AND s.access_jti IS NOT NULL AND s.revoked_at IS NULL AND s.expires_at > NOW()`
+	rows, err := r.db.Query(ctx, query, role)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	values, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	return values, dbError(err)
+}
+
+// Rotate записывает в сессию новый хеш refresh-токена и jti access-токена, только если
+// текущий хеш совпадает с oldHash, и в той же транзакции помечает предъявленный токен
+// (consumedJTI) израсходованным. Возвращает jti прежнего access-токена сессии.
+// При несовпадении возвращается ErrNotFound.
+func (r *sessionRepo) Rotate(ctx context.Context, s *models.Session, oldHash, consumedJTI string, ttl time.Duration) (string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", dbError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	var prevAccessJTI string
+	query := `
+		SELECT COALESCE(access_jti::text, '')
+		FROM sessions
+		WHERE id = $1 AND token_hash = $2 AND ` + activeSession + `
+		FOR UPDATE
+	`
+	if err := tx.QueryRow(ctx, query, s.ID, oldHash).Scan(&prevAccessJTI); err != nil {
+		return "", dbError(err)
+	}
+
+	query = `
+		UPDATE sessions
+		SET token_hash = $2, access_jti = $3, user_agent = $4, ip = $5, last_used_at = NOW(),
+		    expires_at = NOW() + make_interval(secs => $6)
+		WHERE id = $1
+		RETURNING user_id, created_at, last_used_at, expires_at
+	`
+	err = tx.QueryRow(ctx, query, s.ID, s.TokenHash, s.AccessJTI, s.UserAgent, s.IP, ttl.Seconds()).
+		Scan(&s.UserID, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt)
+	if err != nil {
+		return "", dbError(err)
+	}
+
+	// Запись хранится, пока израсходованный токен мог бы оставаться действительным
+	query = `
+		INSERT INTO consumed_refresh_tokens (jti, session_id, expires_at)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3))
+	`
+	if _, err := tx.Exec(ctx, query, consumedJTI, s.ID, ttl.Seconds()); err != nil {
+		return "", dbError(err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM consumed_refresh_tokens WHERE expires_at <= NOW()`); err != nil {
+		return "", dbError(err)
+	}
+
+	return prevAccessJTI, dbError(tx.Commit(ctx))
+}
+
+// GetConsumed возвращает ID сессии, в которой токен с данным jti уже был израсходован,
+// или ErrNotFound, если такой токен не предъявлялся
+func (r *sessionRepo) GetConsumed(ctx context.Context, jti string) (string, error) {
+	var sessionID string
+	query := `SELECT session_id FROM consumed_refresh_tokens WHERE jti = $1 AND expires_at > NOW()`
+	err := r.db.QueryRow(ctx, query, jti).Scan(&sessionID)
+	return sessionID, dbError(err)
+}
+
+// Revoke отзывает сессию и возвращает jti её последнего access-токена
+func (r *sessionRepo) Revoke(ctx context.Context, id string) (string, error) {
+	var accessJTI string
+	query := `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id = $1 AND ` + activeSession + `
+		RETURNING COALESCE(access_jti::text, '')
+	`
+	err := r.db.QueryRow(ctx, query, id).Scan(&accessJTI)
+	return accessJTI, dbError(err)
+}
+
+func (r *sessionRepo) RevokeForUser(ctx context.Context, userID int, id string) (string, error) {
+	var accessJTI string
+	query := `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND ` + activeSession + `
+		RETURNING COALESCE(access_jti::text, '')
+	`
+	err := r.db.QueryRow(ctx, query, id, userID).Scan(&accessJTI)
+	return accessJTI, dbError(err)
+}
+
+// RevokeAllForUser отзывает все сессии пользователя, кроме exceptID (если он задан),
+// и возвращает jti их последних access-токенов
+func (r *sessionRepo) RevokeAllForUser(ctx context.Context, userID int, exceptID string) ([]string, error) {
+	query := `
+		WITH revoked AS (
+			UPDATE sessions SET revoked_at = NOW()
+			WHERE user_id = $1 AND id::text <> $2 AND revoked_at IS NULL
+			RETURNING access_jti
+		)
+		SELECT access_jti::text FROM revoked WHERE access_jti IS NOT NULL
+	`
+	rows, err := r.db.Query(ctx, query, userID, exceptID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	values, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	return values, dbError(err)
+}
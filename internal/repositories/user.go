@@ -2,55 +2,189 @@ package repositories
 
 import (
 	"context"
-	"database/sql"
-	"errors"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"rcoi/internal/models"
 )
 
 type UserRepository interface {
-	CreateUser(ctx context.Context, email, password string) error
+	Create(ctx context.Context, user *models.User) error
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
-	UpdateRefreshToken(ctx context.Context, email, refreshToken string) error
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	GetAll(ctx context.Context, q models.ListQuery) (*models.Page[*models.User], error)
+	UpdatePassword(ctx context.Context, id int, password string, keepHistory int) error
+	GetPasswordHistory(ctx context.Context, id int, limit int) ([]string, error)
+	UpdateRole(ctx context.Context, id int, role string) error
+	SetEmailVerified(ctx context.Context, id int) error
+	SetActive(ctx context.Context, id int, active bool) error
+	Delete(ctx context.Context, id int) error
+	UpdateProfile(ctx context.Context, id int, p models.ProfileUpdate) error
+	SetAvatar(ctx context.Context, id int, key string) (string, error)
 }
 
 type userRepo struct {
 	db *pgxpool.Pool
 }
 
+// userColumns — поля пользователя без пароля, порядок совпадает с userScanDest
+const userColumns = "id, email, role, is_active, email_verified, mfa_enabled, full_name, position, org_unit, phone, avatar_key, created_at"
+
+func userScanDest(u *models.User) []any {
+	return []any{&u.ID, &u.Email, &u.Role, &u.IsActive, &u.EmailVerified, &u.MFAEnabled,
+		&u.FullName, &u.Position, &u.OrgUnit, &u.Phone, &u.AvatarKey, &u.CreatedAt}
+}
+
+var userListSpec = listSpec{
+	table:   "users",
+	columns: userColumns,
+	sortFields: map[string]sortField{
+		"id":         {column: "id", sqlType: "int"},
+		"email":      {column: "email", sqlType: "text"},
+		"created_at": {column: "created_at", sqlType: "timestamp"},
+	},
+	defaultSort:  "created_at",
+	searchColumn: "email",
+}
+
 func NewUserRepository(db *pgxpool.Pool) UserRepository {
 	return &userRepo{db: db}
 }
 
-func (r *userRepo) CreateUser(ctx context.Context, email, password string) error {
-	_, err := r.db.Exec(ctx, "INSERT INTO users (email, password) VALUES ($1, $2)", email, password)
-	return err
+func (r *userRepo) Create(ctx context.Context, user *models.User) error {
+	query := `
+		INSERT INTO users (email, password, role, is_active, email_verified)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	return dbError(r.db.QueryRow(ctx, query, user.Email, user.Password, user.Role, user.IsActive, user.EmailVerified).
+		Scan(&user.ID, &user.CreatedAt))
 }
 
 func (r *userRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	var refreshToken sql.NullString
 
-	err := r.db.QueryRow(ctx, "SELECT id, email, password, refresh_token, role FROM users WHERE email = $1", email).
-		Scan(&user.ID, &user.Email, &user.Password, &refreshToken, &user.Role)
+	err := r.db.QueryRow(ctx, "SELECT password, "+userColumns+" FROM users WHERE email = $1", email).
+		Scan(append([]any{&user.Password}, userScanDest(&user)...)...)
 
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("пользователь не найден")
+		return nil, dbError(err)
+	}
+
+	return &user, nil
+}
+
+func (r *userRepo) GetByID(ctx context.Context, id int) (*models.User, error) {
+	user := &models.User{}
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	err := r.db.QueryRow(ctx, query, id).Scan(userScanDest(user)...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	return user, nil
+}
+
+func (r *userRepo) GetAll(ctx context.Context, q models.ListQuery) (*models.Page[*models.User], error) {
+	return queryPage(ctx, r.db, userListSpec, q, func(rows pgx.Rows, extra ...any) (*models.User, error) {
+		var u models.User
+		dest := append(userScanDest(&u), extra...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
 		}
-		return nil, err
+		return &u, nil
+	})
+}
+
+// UpdatePassword меняет пароль, переносит прежний хеш в историю и оставляет в ней keepHistory последних записей
+func (r *userRepo) UpdatePassword(ctx context.Context, id int, password string, keepHistory int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO password_history (user_id, password_hash) SELECT id, password FROM users WHERE id = $1`
+	tag, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
 	}
 
-	if refreshToken.Valid {
-		user.RefreshToken = refreshToken.String
-	} else {
-		user.RefreshToken = ""
+	if _, err := tx.Exec(ctx, "UPDATE users SET password = $1 WHERE id = $2", password, id); err != nil {
+		return dbError(err)
 	}
 
-	return &user, nil
+	query = `
+		DELETE FROM password_history
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM password_history WHERE user_id = $1 ORDER BY id DESC LIMIT $2
+		)
+	`
+	if _, err := tx.Exec(ctx, query, id, max(keepHistory, 0)); err != nil {
+		return dbError(err)
+	}
+
+	return dbError(tx.Commit(ctx))
+}
+
+// GetPasswordHistory возвращает хеши текущего и прежних паролей, начиная с последнего, не более limit
+func (r *userRepo) GetPasswordHistory(ctx context.Context, id int, limit int) ([]string, error) {
+	query := `
+		SELECT password FROM (
+			SELECT password, 0 AS ord, 0 AS hid FROM users WHERE id = $1
+			UNION ALL
+			SELECT password_hash, 1, id FROM password_history WHERE user_id = $1
+		) h
+		ORDER BY ord, hid DESC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, id, limit)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	values, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	return values, dbError(err)
+}
+
+func (r *userRepo) UpdateRole(ctx context.Context, id int, role string) error {
+	return execAffected(ctx, r.db, "UPDATE users SET role = $1 WHERE id = $2", role, id)
+}
+
+func (r *userRepo) SetEmailVerified(ctx context.Context, id int) error {
+	return execAffected(ctx, r.db, "UPDATE users SET email_verified = TRUE WHERE id = $1", id)
+}
+
+func (r *userRepo) SetActive(ctx context.Context, id int, active bool) error {
+	return execAffected(ctx, r.db, "UPDATE users SET is_active = $1 WHERE id = $2", active, id)
+}
+
+func (r *userRepo) Delete(ctx context.Context, id int) error {
+	return execAffected(ctx, r.db, "DELETE FROM users WHERE id = $1", id)
+}
+
+// UpdateProfile меняет только переданные (не nil) поля профиля
+func (r *userRepo) UpdateProfile(ctx context.Context, id int, p models.ProfileUpdate) error {
+	query := `
+		UPDATE users SET
+			full_name = COALESCE($2, full_name),
+			position = COALESCE($3, position),
+			org_unit = COALESCE($4, org_unit),
+			phone = COALESCE($5, phone)
+		WHERE id = $1
+	`
+	return execAffected(ctx, r.db, query, id, p.FullName, p.Position, p.OrgUnit, p.Phone)
 }
 
-func (r *userRepo) UpdateRefreshToken(ctx context.Context, email, refreshToken string) error {
-	_, err := r.db.Exec(ctx, "UPDATE users SET refresh_token = $1 WHERE email = $2", refreshToken, email)
-	return err
+// SetAvatar сохраняет ключ нового аватара и возвращает ключ прежнего для удаления из хранилища
+func (r *userRepo) SetAvatar(ctx context.Context, id int, key string) (string, error) {
+	query := `
+		UPDATE users u SET avatar_key = $2
+		FROM (SELECT id, avatar_key FROM users WHERE id = $1 FOR UPDATE) prev
+		WHERE u.id = prev.id
+		RETURNING prev.avatar_key
+	`
+	var prevKey string
+	err := r.db.QueryRow(ctx, query, id, key).Scan(&prevKey)
+	return prevKey, dbError(err)
 }
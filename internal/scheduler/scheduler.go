@@ -0,0 +1,64 @@
+// Package scheduler выполняет периодические фоновые задачи внутри процесса
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job — периодическая задача; ошибка логируется, следующий запуск выполняется по расписанию
+type Job func(ctx context.Context) error
+
+// Scheduler запускает задачи в отдельных горутинах до вызова Stop.
+// Задачи должны быть безопасны для одновременного запуска на нескольких репликах.
+type Scheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	logger *zap.Logger
+}
+
+func New(logger *zap.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{ctx: ctx, cancel: cancel, logger: logger}
+}
+
+// Every запускает job сразу и затем каждые interval
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.run(name, job)
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) run(name string, job Job) {
+	defer func() {
+		if p := recover(); p != nil {
+			s.logger.Error("Паника в фоновой задаче", zap.String("job", name), zap.Any("panic", p))
+		}
+	}()
+
+	if err := job(s.ctx); err != nil && s.ctx.Err() == nil {
+		s.logger.Error("Ошибка фоновой задачи", zap.String("job", name), zap.Error(err))
+	}
+}
+
+// Stop отменяет контекст задач и ждёт завершения текущих запусков
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+const (
+	apiKeyPrefix = "rcoi_"
+	// apiKeyTouchInterval ограничивает частоту записи last_used_at для активно используемых ключей
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	ErrAPIKeyNotFound      = errors.New("API-ключ не найден")
+	ErrInvalidAPIKey       = errors.New("неверный или отозванный API-ключ")
+	ErrAPIKeyNameRequired  = errors.New("название ключа обязательно")
+	ErrAPIKeyScopeRequired = errors.New("ключу нужно хотя бы одно право")
+	ErrAPIKeyExpiresAt     = errors.New("срок действия ключа должен быть в будущем")
+	ErrAPIKeyScopeDenied   = errors.New("нельзя выдать ключу право, которого нет у вас")
+	// Ключ с этим правом выпускал бы новые ключи в обход MFA администратора
+	// и переживал бы отключение или понижение того, кто его выпустил
+	ErrAPIKeyScopeForbidden = errors.New("API-ключу нельзя выдать право управления ключами")
+)
+
+// NewAPIKey — параметры выпуска ключа; ExpiresAt == nil означает бессрочный ключ
+type NewAPIKey struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyService interface {
+	GetAll(ctx context.Context) ([]*models.APIKey, error)
+	Issue(ctx context.Context, actor string, actorPermissions []string, input NewAPIKey) (*models.APIKey, string, error)
+	Revoke(ctx context.Context, actor string, id int) error
+	Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error)
+}
+
+type apiKeyService struct {
+	repo   repositories.APIKeyRepository
+	logger *zap.Logger
+}
+
+func NewAPIKeyService(repo repositories.APIKeyRepository, logger *zap.Logger) APIKeyService {
+	return &apiKeyService{repo: repo, logger: logger}
+}
+
+func (s *apiKeyService) GetAll(ctx context.Context) ([]*models.APIKey, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// Issue выпускает ключ и возвращает его открытое значение — повторно получить его нельзя.
+// Права ключа не могут выходить за права выпускающего администратора и включать api_keys:manage.
+func (s *apiKeyService) Issue(ctx context.Context, actor string, actorPermissions []string, input NewAPIKey) (*models.APIKey, string, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, "", ErrAPIKeyNameRequired
+	}
+	if len(input.Scopes) == 0 {
+		return nil, "", ErrAPIKeyScopeRequired
+	}
+	for _, scope := range input.Scopes {
+		if scope == models.PermAPIKeysManage {
+			return nil, "", ErrAPIKeyScopeForbidden
+		}
+		if !slices.Contains(actorPermissions, scope) {
+			return nil, "", ErrAPIKeyScopeDenied
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, "", ErrAPIKeyExpiresAt
+	}
+
+	token, _, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	rawKey := apiKeyPrefix + token
+
+	key := &models.APIKey{
+		Name:      name,
+		Prefix:    rawKey[:len(apiKeyPrefix)+6],
+		KeyHash:   hashOpaqueToken(rawKey),
+		Scopes:    input.Scopes,
+		CreatedBy: actor,
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		if errors.Is(err, repositories.ErrForeignKey) {
+			return nil, "", ErrUnknownPermission
+		}
+		return nil, "", err
+	}
+
+	s.logger.Info("Администратор выпустил API-ключ",
+		zap.String("admin", actor), zap.Int("api_key_id", key.ID), zap.String("name", key.Name), zap.Strings("scopes", key.Scopes))
+	return key, rawKey, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, actor string, id int) error {
+	if err := s.repo.Revoke(ctx, id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+
+	s.logger.Info("Администратор отозвал API-ключ", zap.String("admin", actor), zap.Int("api_key_id", id))
+	return nil
+}
+
+// Authenticate возвращает действующий ключ по его открытому значению и отмечает время использования.
+// Права ключа ограничены текущими правами выпустившего его администратора, см. GetActiveByHash.
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetActiveByHash(ctx, hashOpaqueToken(rawKey))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > apiKeyTouchInterval {
+		// Ошибка записи времени использования не должна отклонять запрос
+		if err := s.repo.Touch(ctx, key.ID); err != nil {
+			s.logger.Warn("Не удалось обновить время использования API-ключа", zap.Int("api_key_id", key.ID), zap.Error(err))
+		}
+	}
+	return key, nil
+}
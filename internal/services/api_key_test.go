@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+type fakeAPIKeyRepo struct {
+	repositories.APIKeyRepository
+	created []*models.APIKey
+}
+
+func (f *fakeAPIKeyRepo) Create(ctx context.Context, key *models.APIKey) error {
+	key.ID = len(f.created) + 1
+	f.created = append(f.created, key)
+	return nil
+}
+
+func TestIssueAPIKeyScopes(t *testing.T) {
+	adminPermissions := []string{models.PermNewsWrite, models.PermAPIKeysManage}
+
+	tests := []struct {
+		name   string
+		scopes []string
+		want   error
+	}{
+		{"право администратора", []string{models.PermNewsWrite}, nil},
+		{"управление ключами", []string{models.PermNewsWrite, models.PermAPIKeysManage}, ErrAPIKeyScopeForbidden},
+		{"чужое право", []string{models.PermUsersManage}, ErrAPIKeyScopeDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAPIKeyRepo{}
+			s := NewAPIKeyService(repo, zap.NewNop())
+
+			_, rawKey, err := s.Issue(context.Background(), "admin@example.com", adminPermissions, NewAPIKey{Name: "CI", Scopes: tt.scopes})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Issue() error = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil && (len(repo.created) != 0 || rawKey != "") {
+				t.Fatal("ключ выпущен несмотря на ошибку")
+			}
+		})
+	}
+}
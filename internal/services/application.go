@@ -2,74 +2,114 @@ package services
 
 import (
 	"context"
+	"errors"
 	"io"
 	"mime/multipart"
-	"os"
-	"path/filepath"
-	"time"
 
 	"rcoi/internal/models"
 	"rcoi/internal/repositories"
+	"rcoi/internal/storage"
 )
 
+var ErrApplicationNotFound = errors.New("приложение не найдено")
+
 type ApplicationService interface {
 	CreateApplication(ctx context.Context, app *models.Application, file multipart.File, fileHeader *multipart.FileHeader) error
 	GetApplicationByID(ctx context.Context, id int) (*models.Application, error)
-	GetAllApplications(ctx context.Context) ([]*models.Application, error)
+	GetAllApplications(ctx context.Context, q models.ListQuery) (*models.Page[*models.Application], error)
+	OpenApplicationFile(ctx context.Context, app *models.Application) (io.ReadCloser, *storage.ObjectInfo, error)
+	// UpdateApplication сохраняет приложение версии app.Version; при успехе app.Version — новая версия.
+	// app.Filename — файл этой версии: если задан url, файл снимается с приложения и удаляется.
 	UpdateApplication(ctx context.Context, app *models.Application) error
-	DeleteApplication(ctx context.Context, id int) error
+	DeleteApplication(ctx context.Context, id, version int) error
 }
 
 type applicationService struct {
-	repo repositories.ApplicationRepository
+	repo  repositories.ApplicationRepository
+	files storage.Backend
 }
 
-func NewApplicationService(repo repositories.ApplicationRepository) ApplicationService {
-	return &applicationService{repo: repo}
+func NewApplicationService(repo repositories.ApplicationRepository, files storage.Backend) ApplicationService {
+	return &applicationService{repo: repo, files: files}
 }
 
 // CreateApplication позволяет загружать файл или сохранять URL
 func (s *applicationService) CreateApplication(ctx context.Context, app *models.Application, file multipart.File, fileHeader *multipart.FileHeader) error {
 	if file != nil && fileHeader != nil {
-		filename := time.Now().Format("20060102150405") + "_" + filepath.Base(fileHeader.Filename)
-		path := filepath.Join("uploads", filename)
+		filename := storage.UniqueKey(fileHeader.Filename)
 
-		dst, err := os.Create(path)
-		if err != nil {
+		if err := s.files.Put(ctx, filename, file, fileHeader.Size, fileHeader.Header.Get("Content-Type")); err != nil {
 			return err
 		}
-		defer dst.Close()
+		app.Filename = filename
+	}
 
-		if _, err = io.Copy(dst, file); err != nil {
-			return err
+	if err := s.repo.Create(ctx, app); err != nil {
+		if app.Filename != "" {
+			_ = s.files.Delete(ctx, app.Filename)
 		}
-		app.Filename = filename
+		return err
 	}
 
-	return s.repo.Create(ctx, app)
+	return nil
 }
 
 func (s *applicationService) GetApplicationByID(ctx context.Context, id int) (*models.Application, error) {
-	return s.repo.GetByID(ctx, id)
+	app, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapApplicationError(err)
+	}
+	return app, nil
+}
+
+func (s *applicationService) GetAllApplications(ctx context.Context, q models.ListQuery) (*models.Page[*models.Application], error) {
+	return s.repo.GetAll(ctx, q)
 }
 
-func (s *applicationService) GetAllApplications(ctx context.Context) ([]*models.Application, error) {
-	return s.repo.GetAll(ctx)
+func (s *applicationService) OpenApplicationFile(ctx context.Context, app *models.Application) (io.ReadCloser, *storage.ObjectInfo, error) {
+	return s.files.Get(ctx, app.Filename)
 }
 
+// UpdateApplication хранит у приложения либо файл, либо url: иначе клиенты получали бы ссылку,
+// а файл оставался бы в хранилище без возможности его скачать
 func (s *applicationService) UpdateApplication(ctx context.Context, app *models.Application) error {
-	return s.repo.Update(ctx, app)
+	var replaced string
+	if app.URL != "" && app.Filename != "" {
+		replaced, app.Filename = app.Filename, ""
+	}
+
+	// Проверка версии гарантирует, что удаляемый файл принадлежит именно сохраняемой версии
+	if err := s.repo.Update(ctx, app); err != nil {
+		app.Filename = replaced
+		return mapApplicationError(err)
+	}
+
+	if replaced != "" {
+		_ = s.files.Delete(ctx, replaced)
+	}
+	return nil
 }
 
-func (s *applicationService) DeleteApplication(ctx context.Context, id int) error {
-	app, err := s.repo.GetByID(ctx, id)
+func (s *applicationService) DeleteApplication(ctx context.Context, id, version int) error {
+	app, err := s.GetApplicationByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	if err := s.repo.Delete(ctx, id, version); err != nil {
+		return mapApplicationError(err)
+	}
+
 	if app.Filename != "" {
-		_ = os.Remove(filepath.Join("uploads", app.Filename))
+		_ = s.files.Delete(ctx, app.Filename)
 	}
 
-	return s.repo.Delete(ctx, id)
+	return nil
+}
+
+func mapApplicationError(err error) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrApplicationNotFound
+	}
+	return mapVersionError(err)
 }
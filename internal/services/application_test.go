@@ -0,0 +1,73 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+	"rcoi/internal/storage"
+)
+
+type fakeApplicationRepo struct {
+	repositories.ApplicationRepository
+	saved *models.Application
+	err   error
+}
+
+func (f *fakeApplicationRepo) Update(ctx context.Context, app *models.Application) error {
+	if f.err != nil {
+		return f.err
+	}
+	saved := *app
+	f.saved = &saved
+	app.Version++
+	return nil
+}
+
+func TestUpdateApplicationKeepsFileOrURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		repoErr      error
+		wantFilename string
+		wantFile     bool
+	}{
+		{"url заменяет файл", "https://example.org/app", nil, "", false},
+		{"без url файл остаётся", "", nil, "app.zip", true},
+		{"ошибка сохранения не трогает файл", "https://example.org/app", repositories.ErrVersionMismatch, "app.zip", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			files, err := storage.NewLocalBackend(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := files.Put(ctx, "app.zip", bytes.NewReader([]byte("PK")), 2, "application/zip"); err != nil {
+				t.Fatal(err)
+			}
+			repo := &fakeApplicationRepo{err: tt.repoErr}
+
+			app := &models.Application{ID: 1, Title: "Приложение", URL: tt.url, Filename: "app.zip", Version: 3}
+			err = NewApplicationService(repo, files).UpdateApplication(ctx, app)
+			if !errors.Is(err, mapApplicationError(tt.repoErr)) {
+				t.Fatalf("error = %v, want %v", err, tt.repoErr)
+			}
+
+			if app.Filename != tt.wantFilename {
+				t.Errorf("Filename = %q, want %q", app.Filename, tt.wantFilename)
+			}
+			if repo.saved != nil && repo.saved.Filename != tt.wantFilename {
+				t.Errorf("в БД записан файл %q, want %q", repo.saved.Filename, tt.wantFilename)
+			}
+			_, err = files.Stat(ctx, "app.zip")
+			if exists := err == nil; exists != tt.wantFile {
+				t.Errorf("файл в хранилище = %v, want %v", exists, tt.wantFile)
+			}
+		})
+	}
+}
@@ -2,54 +2,96 @@ package services
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
-	"os"
+	"net/mail"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
+	"rcoi/config"
+	"rcoi/internal/denylist"
+	"rcoi/internal/jwtkeys"
+	"rcoi/internal/loginguard"
+	"rcoi/internal/models"
+	"rcoi/internal/passwordpolicy"
 	"rcoi/internal/repositories"
 )
 
 type AuthService interface {
 	RegisterUser(ctx context.Context, email, password string) error
-	Login(ctx context.Context, email, password string) (string, string, error)
-	RefreshToken(ctx context.Context, oldRefreshToken string) (string, string, error)
-	Logout(ctx context.Context, email string) error
+	Login(ctx context.Context, email, password string, meta SessionMeta) (*LoginResult, error)
+	CompleteMFA(ctx context.Context, mfaToken, code string, meta SessionMeta) (*LoginResult, error)
+	LoginExternal(ctx context.Context, user *models.User, meta SessionMeta) (*LoginResult, error)
+	RefreshToken(ctx context.Context, oldRefreshToken string, meta SessionMeta) (string, string, error)
+	Logout(ctx context.Context, sessionID string) error
 }
 
-type authService struct {
-	repo   repositories.UserRepository
-	logger *zap.Logger
+// SessionMeta — сведения об устройстве, с которого выполняется вход
+type SessionMeta struct {
+	UserAgent string
+	IP        string
 }
 
-type Claims struct {
-	Email string `json:"email"`
-	Role  string `json:"role"`
-	jwt.RegisteredClaims
+// LoginResult — итог входа: пара токенов либо, если нужен второй фактор,
+// MFA-токен, который обменивается на пару токенов через /login/mfa
+type LoginResult struct {
+	AccessToken  string
+	RefreshToken string
+	MFAToken     string
 }
 
-func NewAuthService(repo repositories.UserRepository, logger *zap.Logger) AuthService {
-	return &authService{repo: repo, logger: logger}
+// mfaChallengeTTL — сколько действует MFA-токен между вводом пароля и кода
+const mfaChallengeTTL = 5 * time.Minute
+
+var (
+	ErrInvalidEmail      = errors.New("некорректный email")
+	ErrEmailExists       = errors.New("email уже используется")
+	ErrEmailNotVerified  = errors.New("email не подтверждён")
+	ErrRefreshTokenReuse = errors.New("повторное использование refresh-токена")
+	ErrInvalidMFAToken   = errors.New("MFA-токен недействителен или истёк")
+	// ErrInvalidCredentials не уточняет, что именно неверно, чтобы не раскрывать зарегистрированные email
+	ErrInvalidCredentials  = errors.New("неверный email или пароль")
+	ErrAccountDisabled     = errors.New("учётная запись отключена")
+	ErrInvalidRefreshToken = errors.New("refresh-токен недействителен или отозван")
+)
+
+type authService struct {
+	repo     repositories.UserRepository
+	roles    repositories.RoleRepository
+	sessions repositories.SessionRepository
+	revoked  denylist.Store
+	keys     *jwtkeys.KeySet
+	guard    *loginguard.Guard
+	verifier EmailVerificationService
+	mfa      MFAService
+	policy   *passwordpolicy.Policy
+	cfg      config.AuthConfig
+	logger   *zap.Logger
 }
 
-var cachedSecretKey []byte
-var once sync.Once
+// Claims — содержимое JWT. SessionID (sid) объединяет все refresh-токены одной сессии
+// в семейство, а ID (jti) уникален для каждого выпущенного токена.
+// TokenUse отличает access-токены от refresh-токенов; refresh-токен не несёт данных пользователя.
+type Claims struct {
+	Email         string   `json:"email,omitempty"`
+	Role          string   `json:"role,omitempty"`
+	Permissions   []string `json:"permissions,omitempty"`
+	EmailVerified bool     `json:"email_verified,omitempty"`
+	SessionID     string   `json:"sid,omitempty"`
+	TokenUse      string   `json:"token_use"`
+	// MFAEnrollmentRequired — права не выданы, пока пользователь не подключит TOTP
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
+	jwt.RegisteredClaims
+}
 
-func GetSecretKey(logger *zap.Logger) ([]byte, error) {
-	var err error
-	once.Do(func() {
-		key := os.Getenv("JWT_SECRET")
-		if key == "" {
-			logger.Fatal("Ошибка: переменная JWT_SECRET не установлена")
-			err = errors.New("переменная окружения JWT_SECRET отсутствует")
-			return
-		}
-		cachedSecretKey = []byte(key)
-	})
-	return cachedSecretKey, err
+func NewAuthService(repo repositories.UserRepository, roles repositories.RoleRepository, sessions repositories.SessionRepository, revoked denylist.Store, keys *jwtkeys.KeySet, guard *loginguard.Guard, verifier EmailVerificationService, mfa MFAService, policy *passwordpolicy.Policy, cfg config.AuthConfig, logger *zap.Logger) AuthService {
+	return &authService{repo: repo, roles: roles, sessions: sessions, revoked: revoked, keys: keys, guard: guard, verifier: verifier, mfa: mfa, policy: policy, cfg: cfg, logger: logger}
 }
 
 func hashPassword(password string) (string, error) {
@@ -61,130 +103,376 @@ func checkPassword(hashedPwd, plainPwd string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPwd), []byte(plainPwd)) == nil
 }
 
-func generateToken(email, role string, expiry time.Duration, logger *zap.Logger) (string, error) {
-	key, err := GetSecretKey(logger)
+// dummyPasswordHash проверяется вместо хеша неизвестного пользователя: вход с незарегистрированным
+// email занимает столько же времени, сколько с неверным паролем, и не выдаёт, есть ли такой email
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, err := hashPassword("rcoi-dummy-password")
 	if err != nil {
-		return "", err
+		panic(err)
 	}
+	return hash
+})
 
+// generateToken подписывает токен назначения use; данные пользователя и права
+// попадают только в access-токен
+func (s *authService) generateToken(use string, user *models.User, permissions []string, sessionID, jti string, expiry time.Duration) (string, error) {
+	now := time.Now()
 	claims := Claims{
-		Email: email,
-		Role:  role,
+		SessionID: sessionID,
+		TokenUse:  use,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			Issuer:    s.keys.Issuer(),
+			Audience:  jwt.ClaimStrings{s.keys.Audience(use)},
+			Subject:   strconv.Itoa(user.ID),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if use == jwtkeys.UseAccess {
+		claims.Email = user.Email
+		claims.Role = user.Role
+		claims.Permissions = permissions
+		claims.EmailVerified = user.EmailVerified
+		claims.MFAEnrollmentRequired = s.mfaEnrollmentRequired(user)
+	}
+
+	return s.keys.Sign(claims)
+}
 
-	return token.SignedString(key)
+// validEmail принимает только голый адрес: без имени, угловых скобок и лишних пробелов
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
 }
 
-func parseToken(tokenString string, logger *zap.Logger) (jwt.MapClaims, error) {
-	key, err := GetSecretKey(logger)
+func (s *authService) RegisterUser(ctx context.Context, email, password string) error {
+	if !validEmail(email) {
+		return ErrInvalidEmail
+	}
+
+	hashedPassword, err := newPasswordHash(s.policy, password)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("неверный метод подписи")
+	user := &models.User{Email: email, Password: hashedPassword, Role: "user", IsActive: true}
+	if err := s.repo.Create(ctx, user); err != nil {
+		if mapUserError(err) == ErrUserExists {
+			return ErrEmailExists
 		}
-		return key, nil
-	})
-
-	if err != nil || !token.Valid {
-		return nil, errors.New("неверный или просроченный токен")
+		return err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, errors.New("неверные claims")
+	// Ошибка отправки не отменяет регистрацию: письмо можно запросить повторно
+	if err := s.verifier.SendVerification(ctx, user); err != nil {
+		s.logger.Warn("Не удалось отправить письмо подтверждения", zap.String("email", email), zap.Error(err))
 	}
 
-	return claims, nil
+	return nil
 }
 
-func (s *authService) RegisterUser(ctx context.Context, email, password string) error {
-	hashedPassword, err := hashPassword(password)
-	if err != nil {
-		s.logger.Error("Ошибка хеширования пароля", zap.Error(err))
-		return err
+func (s *authService) Login(ctx context.Context, email, password string, meta SessionMeta) (*LoginResult, error) {
+	if err := s.guard.Check(ctx, email, meta.IP); err != nil {
+		s.logger.Warn("Попытка входа отклонена ограничителем", zap.String("email", email), zap.String("ip", meta.IP), zap.Error(err))
+		return nil, err
 	}
-	return s.repo.CreateUser(ctx, email, hashedPassword)
-}
 
-func (s *authService) Login(ctx context.Context, email, password string) (string, string, error) {
 	user, err := s.repo.GetUserByEmail(ctx, email)
 	if err != nil {
-		s.logger.Warn("Пользователь не найден", zap.String("email", email), zap.Error(err))
-		return "", "", errors.New("пользователь не найден")
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return nil, err
+		}
+		s.logger.Warn("Пользователь не найден", zap.String("email", email))
+		checkPassword(dummyPasswordHash(), password)
+		s.registerFailure(ctx, email, meta)
+		return nil, ErrInvalidCredentials
 	}
 
 	if !checkPassword(user.Password, password) {
 		s.logger.Warn("Неверный пароль", zap.String("email", email))
-		return "", "", errors.New("неверный пароль")
+		s.registerFailure(ctx, email, meta)
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.completeLogin(ctx, user, meta)
+}
+
+// LoginExternal выполняет вход пользователя, которого уже аутентифицировал внешний провайдер (SSO):
+// пароль не проверяется, но остальные условия входа и второй фактор — те же, что у Login
+func (s *authService) LoginExternal(ctx context.Context, user *models.User, meta SessionMeta) (*LoginResult, error) {
+	return s.completeLogin(ctx, user, meta)
+}
+
+// completeLogin выдаёт MFA-токен, если подключён второй фактор, иначе сразу открывает сессию
+func (s *authService) completeLogin(ctx context.Context, user *models.User, meta SessionMeta) (*LoginResult, error) {
+	if err := s.checkCanLogin(user); err != nil {
+		return nil, err
+	}
+
+	// Счётчик попыток не сбрасывается до ввода кода, иначе коды можно было бы перебирать бесконечно
+	if user.MFAEnabled {
+		mfaToken, err := s.generateToken(jwtkeys.UseMFA, user, nil, "", uuid.NewString(), mfaChallengeTTL)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{MFAToken: mfaToken}, nil
 	}
 
-	accessToken, err := generateToken(email, user.Role, time.Hour, s.logger)
+	return s.startSession(ctx, user, meta)
+}
+
+// CompleteMFA завершает вход по MFA-токену из Login и коду второго фактора
+func (s *authService) CompleteMFA(ctx context.Context, mfaToken, code string, meta SessionMeta) (*LoginResult, error) {
+	claims, err := s.keys.Parse(mfaToken, jwtkeys.UseMFA)
 	if err != nil {
-		return "", "", err
+		return nil, ErrInvalidMFAToken
 	}
 
-	refreshToken, err := generateToken(email, user.Role, time.Hour*24*7, s.logger)
+	subject, _ := claims["sub"].(string)
+	userID, err := strconv.Atoi(subject)
 	if err != nil {
-		return "", "", err
+		return nil, ErrInvalidMFAToken
 	}
 
-	err = s.repo.UpdateRefreshToken(ctx, email, refreshToken)
+	user, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
-		return "", "", err
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
 	}
 
-	return accessToken, refreshToken, nil
+	if err := s.guard.Check(ctx, user.Email, meta.IP); err != nil {
+		s.logger.Warn("Ввод кода отклонён ограничителем", zap.String("email", user.Email), zap.String("ip", meta.IP), zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.checkCanLogin(user); err != nil {
+		return nil, err
+	}
+
+	if err := s.mfa.Verify(ctx, user.ID, code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			s.logger.Warn("Неверный код второго фактора", zap.String("email", user.Email))
+			s.registerFailure(ctx, user.Email, meta)
+		}
+		return nil, err
+	}
+
+	return s.startSession(ctx, user, meta)
 }
 
-func (s *authService) RefreshToken(ctx context.Context, oldRefreshToken string) (string, string, error) {
-	claims, err := parseToken(oldRefreshToken, s.logger)
+// checkCanLogin проверяет, что учётной записи разрешён вход
+func (s *authService) checkCanLogin(user *models.User) error {
+	if !user.IsActive {
+		s.logger.Warn("Попытка входа в отключённую учётную запись", zap.String("email", user.Email))
+		return ErrAccountDisabled
+	}
+
+	if !user.EmailVerified && s.cfg.UnverifiedLogin == "deny" {
+		s.logger.Warn("Попытка входа с неподтверждённым email", zap.String("email", user.Email))
+		return ErrEmailNotVerified
+	}
+
+	return nil
+}
+
+// startSession создаёт сессию нового устройства после успешной аутентификации
+func (s *authService) startSession(ctx context.Context, user *models.User, meta SessionMeta) (*LoginResult, error) {
+	if err := s.guard.Succeed(ctx, user.Email); err != nil {
+		s.logger.Error("Ошибка сброса счётчика попыток входа", zap.String("email", user.Email), zap.Error(err))
+	}
+
+	sessionID := uuid.NewString()
+	tokens, err := s.issueTokens(ctx, user, sessionID)
 	if err != nil {
-		return "", "", errors.New("неверный или просроченный refresh-токен")
+		return nil, err
 	}
 
-	email, ok := claims["email"].(string)
-	if !ok {
-		return "", "", errors.New("неверный токен")
+	session := &models.Session{
+		ID:        sessionID,
+		UserID:    user.ID,
+		TokenHash: hashOpaqueToken(tokens.refresh),
+		AccessJTI: tokens.accessJTI,
+		UserAgent: meta.UserAgent,
+		IP:        meta.IP,
+	}
+	if err := s.sessions.Create(ctx, session, s.cfg.RefreshTokenTTL); err != nil {
+		return nil, err
 	}
 
-	user, err := s.repo.GetUserByEmail(ctx, email)
+	return &LoginResult{AccessToken: tokens.access, RefreshToken: tokens.refresh}, nil
+}
+
+// registerFailure учитывает неудачную попытку входа; сбой хранилища счётчиков не меняет ответ клиенту
+func (s *authService) registerFailure(ctx context.Context, email string, meta SessionMeta) {
+	if err := s.guard.Fail(ctx, email, meta.IP); err != nil {
+		s.logger.Error("Ошибка учёта неудачной попытки входа", zap.String("email", email), zap.Error(err))
+	}
+}
+
+func (s *authService) RefreshToken(ctx context.Context, oldRefreshToken string, meta SessionMeta) (string, string, error) {
+	claims, err := s.keys.Parse(oldRefreshToken, jwtkeys.UseRefresh)
 	if err != nil {
-		return "", "", errors.New("пользователь не найден")
+		return "", "", ErrInvalidRefreshToken
 	}
 
-	if user.RefreshToken != oldRefreshToken {
-		s.logger.Warn("Несоответствие refresh-токена", zap.String("email", email))
-		return "", "", errors.New("refresh-токен не совпадает")
+	sessionID, _ := claims["sid"].(string)
+	jti, _ := claims["jti"].(string)
+	if sessionID == "" || uuid.Validate(jti) != nil {
+		return "", "", ErrInvalidRefreshToken
 	}
 
-	newAccessToken, err := generateToken(email, user.Role, time.Hour, s.logger)
+	// Токен уже обменивался: им пользуется кто-то кроме владельца сессии
+	familyID, err := s.sessions.GetConsumed(ctx, jti)
+	if err == nil {
+		s.revokeFamily(ctx, familyID, jti, claims, meta)
+		return "", "", ErrRefreshTokenReuse
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return "", "", err
+	}
+
+	session, err := s.sessions.GetByID(ctx, sessionID)
 	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return "", "", ErrInvalidRefreshToken
+		}
 		return "", "", err
 	}
 
-	newRefreshToken, err := generateToken(email, user.Role, time.Hour*24*7, s.logger)
+	oldHash := hashOpaqueToken(oldRefreshToken)
+	if subtle.ConstantTimeCompare([]byte(oldHash), []byte(session.TokenHash)) != 1 {
+		s.logger.Warn("Несоответствие refresh-токена", zap.String("session_id", sessionID), zap.Int("user_id", session.UserID))
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	user, err := s.repo.GetByID(ctx, session.UserID)
 	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return "", "", ErrInvalidRefreshToken
+		}
 		return "", "", err
 	}
 
-	err = s.repo.UpdateRefreshToken(ctx, email, newRefreshToken)
+	if !user.IsActive {
+		return "", "", ErrAccountDisabled
+	}
+
+	if !user.EmailVerified && s.cfg.UnverifiedLogin == "deny" {
+		return "", "", ErrEmailNotVerified
+	}
+
+	tokens, err := s.issueTokens(ctx, user, sessionID)
 	if err != nil {
 		return "", "", err
 	}
 
-	return newAccessToken, newRefreshToken, nil
+	rotated := &models.Session{
+		ID:        sessionID,
+		TokenHash: hashOpaqueToken(tokens.refresh),
+		AccessJTI: tokens.accessJTI,
+		UserAgent: meta.UserAgent,
+		IP:        meta.IP,
+	}
+	prevAccessJTI, err := s.sessions.Rotate(ctx, rotated, oldHash, jti, s.cfg.RefreshTokenTTL)
+	if err != nil {
+		// Параллельный запрос успел обменять этот же токен
+		if errors.Is(err, repositories.ErrNotFound) {
+			s.revokeFamily(ctx, sessionID, jti, claims, meta)
+			return "", "", ErrRefreshTokenReuse
+		}
+		return "", "", err
+	}
+
+	// У сессии остаётся один действующий access-токен — выданный сейчас.
+	// Ротация уже зафиксирована, поэтому сбой denylist не должен лишать клиента новых токенов.
+	if err := denyAccessTokens(ctx, s.revoked, prevAccessJTI); err != nil {
+		s.logger.Error("Ошибка отзыва прежнего access-токена", zap.String("session_id", sessionID), zap.Error(err))
+	}
+
+	return tokens.access, tokens.refresh, nil
 }
 
-func (s *authService) Logout(ctx context.Context, email string) error {
-	return s.repo.UpdateRefreshToken(ctx, email, "")
+// revokeFamily отзывает сессию, к которой относится повторно предъявленный refresh-токен
+func (s *authService) revokeFamily(ctx context.Context, sessionID, jti string, claims jwt.MapClaims, meta SessionMeta) {
+	subject, _ := claims["sub"].(string)
+	s.logger.Warn("Событие безопасности: повторное использование refresh-токена, сессия отозвана",
+		zap.String("event", "refresh_token_reuse"),
+		zap.String("session_id", sessionID),
+		zap.String("jti", jti),
+		zap.String("user_id", subject),
+		zap.String("ip", meta.IP),
+		zap.String("user_agent", meta.UserAgent),
+	)
+
+	accessJTI, err := s.sessions.Revoke(ctx, sessionID)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			s.logger.Error("Ошибка отзыва сессии", zap.String("session_id", sessionID), zap.Error(err))
+		}
+		return
+	}
+	if err := denyAccessTokens(ctx, s.revoked, accessJTI); err != nil {
+		s.logger.Error("Ошибка отзыва access-токена сессии", zap.String("session_id", sessionID), zap.Error(err))
+	}
+}
+
+// tokenPair — выпущенные токены сессии и jti access-токена для последующего отзыва
+type tokenPair struct {
+	access    string
+	refresh   string
+	accessJTI string
+}
+
+// issueTokens выпускает пару токенов сессии с актуальными правами роли пользователя
+func (s *authService) issueTokens(ctx context.Context, user *models.User, sessionID string) (*tokenPair, error) {
+	permissions, err := s.roles.GetPermissions(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пока email не подтверждён, в режиме restrict доступно только чтение
+	if !user.EmailVerified && s.cfg.UnverifiedLogin == "restrict" {
+		permissions = nil
+	}
+
+	// Роли с обязательной 2FA получают права только после подключения TOTP
+	if s.mfaEnrollmentRequired(user) {
+		permissions = nil
+	}
+
+	tokens := &tokenPair{accessJTI: uuid.NewString()}
+
+	tokens.access, err = s.generateToken(jwtkeys.UseAccess, user, permissions, sessionID, tokens.accessJTI, s.cfg.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens.refresh, err = s.generateToken(jwtkeys.UseRefresh, user, nil, sessionID, uuid.NewString(), s.cfg.RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// mfaEnrollmentRequired сообщает, что роль пользователя требует TOTP, а он ещё не подключён
+func (s *authService) mfaEnrollmentRequired(user *models.User) bool {
+	return !user.MFAEnabled && slices.Contains(s.cfg.MFARequiredRoles, user.Role)
+}
+
+// Logout завершает текущую сессию и отзывает её access-токен
+func (s *authService) Logout(ctx context.Context, sessionID string) error {
+	accessJTI, err := s.sessions.Revoke(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return denyAccessTokens(ctx, s.revoked, accessJTI)
 }
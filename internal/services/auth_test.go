@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/config"
+	"rcoi/internal/loginguard"
+	"rcoi/internal/models"
+)
+
+func TestLoginUnknownEmailCostsLikeWrongPassword(t *testing.T) {
+	hash, err := hashPassword("Correct-password-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &fakeUserRepo{byID: map[int]*models.User{
+		1: {ID: 1, Email: "known@example.com", Password: hash, Role: "user", IsActive: true},
+	}}
+	// Счётчики отключены, чтобы задержки ограничителя не влияли на замер
+	guard := loginguard.NewGuard(loginguard.NewMemoryStore(), config.LockoutConfig{}, zap.NewNop())
+	s := NewAuthService(users, nil, nil, nil, nil, guard, nil, nil, nil, config.AuthConfig{}, zap.NewNop())
+
+	login := func(email string) time.Duration {
+		t.Helper()
+		start := time.Now()
+		_, err := s.Login(context.Background(), email, "Wrong-password-1", SessionMeta{IP: "203.0.113.7"})
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Login(%q) error = %v, want ErrInvalidCredentials", email, err)
+		}
+		return time.Since(start)
+	}
+
+	// Первый вызов считает фиктивный хеш; замеряем следующие
+	login("unknown@example.com")
+	known, unknown := login("known@example.com"), login("unknown@example.com")
+
+	if unknown < known/2 {
+		t.Fatalf("вход с неизвестным email занял %s против %s с неверным паролем: bcrypt пропущен", unknown, known)
+	}
+}
@@ -2,41 +2,37 @@ package services
 
 import (
 	"context"
+	"errors"
 	"io"
 	"mime/multipart"
-	"os"
-	"path/filepath"
 	"rcoi/internal/models"
 	"rcoi/internal/repositories"
-	"time"
+	"rcoi/internal/storage"
 )
 
+var ErrDocumentNotFound = errors.New("документ не найден")
+
 type DocumentService interface {
 	UploadDocument(ctx context.Context, title string, file multipart.File, fileHeader *multipart.FileHeader) (*models.Document, error)
 	GetDocumentByID(ctx context.Context, id int) (*models.Document, error)
-	GetAllDocuments(ctx context.Context) ([]*models.Document, error)
+	GetAllDocuments(ctx context.Context, q models.ListQuery) (*models.Page[*models.Document], error)
+	OpenDocumentFile(ctx context.Context, doc *models.Document) (io.ReadCloser, *storage.ObjectInfo, error)
 	DeleteDocument(ctx context.Context, id int) error
 }
 
 type documentService struct {
-	repo repositories.DocumentRepository
+	repo  repositories.DocumentRepository
+	files storage.Backend
 }
 
-func NewDocumentService(repo repositories.DocumentRepository) DocumentService {
-	return &documentService{repo: repo}
+func NewDocumentService(repo repositories.DocumentRepository, files storage.Backend) DocumentService {
+	return &documentService{repo: repo, files: files}
 }
 
 func (s *documentService) UploadDocument(ctx context.Context, title string, file multipart.File, fileHeader *multipart.FileHeader) (*models.Document, error) {
-	filename := time.Now().Format("20060102150405") + "_" + filepath.Base(fileHeader.Filename)
-	path := filepath.Join("uploads", filename)
-
-	dst, err := os.Create(path)
-	if err != nil {
-		return nil, err
-	}
-	defer dst.Close()
+	filename := storage.UniqueKey(fileHeader.Filename)
 
-	if _, err = io.Copy(dst, file); err != nil {
+	if err := s.files.Put(ctx, filename, file, fileHeader.Size, fileHeader.Header.Get("Content-Type")); err != nil {
 		return nil, err
 	}
 
@@ -46,6 +42,7 @@ func (s *documentService) UploadDocument(ctx context.Context, title string, file
 	}
 
 	if err := s.repo.Create(ctx, doc); err != nil {
+		_ = s.files.Delete(ctx, filename)
 		return nil, err
 	}
 
@@ -53,19 +50,38 @@ func (s *documentService) UploadDocument(ctx context.Context, title string, file
 }
 
 func (s *documentService) GetDocumentByID(ctx context.Context, id int) (*models.Document, error) {
-	return s.repo.GetByID(ctx, id)
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapDocumentError(err)
+	}
+	return doc, nil
 }
 
-func (s *documentService) GetAllDocuments(ctx context.Context) ([]*models.Document, error) {
-	return s.repo.GetAll(ctx)
+func (s *documentService) GetAllDocuments(ctx context.Context, q models.ListQuery) (*models.Page[*models.Document], error) {
+	return s.repo.GetAll(ctx, q)
+}
+
+func (s *documentService) OpenDocumentFile(ctx context.Context, doc *models.Document) (io.ReadCloser, *storage.ObjectInfo, error) {
+	return s.files.Get(ctx, doc.Filename)
 }
 
 func (s *documentService) DeleteDocument(ctx context.Context, id int) error {
-	doc, err := s.repo.GetByID(ctx, id)
+	doc, err := s.GetDocumentByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	os.Remove(filepath.Join("uploads", doc.Filename))
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return mapDocumentError(err)
+	}
+
+	_ = s.files.Delete(ctx, doc.Filename)
+	return nil
+}
+
+func mapDocumentError(err error) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrDocumentNotFound
+	}
+	return err
 }
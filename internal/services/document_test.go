@@ -0,0 +1,58 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/textproto"
+	"testing"
+
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+	"rcoi/internal/storage"
+)
+
+type fakeDocumentRepo struct {
+	repositories.DocumentRepository
+	err error
+}
+
+func (f *fakeDocumentRepo) Create(ctx context.Context, doc *models.Document) error {
+	return f.err
+}
+
+// memoryFile — содержимое загружаемого файла, как его отдаёт multipart
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error { return nil }
+
+func TestUploadDocumentKeysDoNotCollide(t *testing.T) {
+	files, err := storage.NewLocalBackend(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &multipart.FileHeader{Filename: "отчёт.pdf", Size: 4, Header: textproto.MIMEHeader{"Content-Type": {"application/pdf"}}}
+	upload := func(repo *fakeDocumentRepo) (*models.Document, error) {
+		return NewDocumentService(repo, files).UploadDocument(context.Background(), "Отчёт", memoryFile{bytes.NewReader([]byte("%PDF"))}, header)
+	}
+
+	doc, err := upload(&fakeDocumentRepo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Вторая загрузка того же файла в ту же секунду падает на записи в БД и удаляет свой объект
+	if _, err := upload(&fakeDocumentRepo{err: errors.New("db down")}); err == nil {
+		t.Fatal("ошибка БД не возвращена")
+	}
+
+	if _, err := files.Stat(context.Background(), doc.Filename); err != nil {
+		t.Fatalf("откат чужой загрузки удалил файл %q: %v", doc.Filename, err)
+	}
+	objects, err := files.List(context.Background(), "")
+	if err != nil || len(objects) != 1 {
+		t.Fatalf("в хранилище %d объектов, want 1: %v", len(objects), err)
+	}
+}
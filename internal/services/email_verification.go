@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/internal/loginguard"
+	"rcoi/internal/mailer"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+const emailVerificationTTL = 48 * time.Hour
+
+var ErrInvalidVerificationToken = errors.New("ссылка для подтверждения email недействительна или устарела")
+
+type EmailVerificationService interface {
+	SendVerification(ctx context.Context, user *models.User) error
+	Verify(ctx context.Context, token string) error
+	// Resend повторно отправляет письмо; ip — адрес клиента для ограничения частоты писем
+	Resend(ctx context.Context, email, ip string) error
+}
+
+type emailVerificationService struct {
+	users    repositories.UserRepository
+	tokens   repositories.OneTimeTokenRepository
+	mailer   mailer.Mailer
+	throttle *loginguard.Throttle
+	baseURL  string
+	logger   *zap.Logger
+}
+
+func NewEmailVerificationService(users repositories.UserRepository, tokens repositories.OneTimeTokenRepository, m mailer.Mailer, throttle *loginguard.Throttle, baseURL string, logger *zap.Logger) EmailVerificationService {
+	return &emailVerificationService{users: users, tokens: tokens, mailer: m, throttle: throttle, baseURL: baseURL, logger: logger}
+}
+
+// SendVerification выпускает новый токен подтверждения и отправляет его на email пользователя
+func (s *emailVerificationService) SendVerification(ctx context.Context, user *models.User) error {
+	token, hash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.tokens.Create(ctx, user.ID, hash, emailVerificationTTL); err != nil {
+		return err
+	}
+
+	link := s.baseURL + "/verify-email?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Подтверждение email",
+		Body: fmt.Sprintf("Для подтверждения адреса электронной почты перейдите по ссылке:\n\n%s\n\n"+
+			"Ссылка действует %d ч.", link, int(emailVerificationTTL.Hours())),
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		s.logger.Error("Ошибка отправки письма подтверждения", zap.String("email", user.Email), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (s *emailVerificationService) Verify(ctx context.Context, token string) error {
+	userID, err := s.tokens.Consume(ctx, hashOpaqueToken(token))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	if err := s.users.SetEmailVerified(ctx, userID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Email подтверждён", zap.Int("user_id", userID))
+	return nil
+}
+
+// Resend повторно отправляет письмо. Как и при сбросе пароля, результат
+// не раскрывает, зарегистрирован ли email.
+func (s *emailVerificationService) Resend(ctx context.Context, email, ip string) error {
+	allowed, err := s.throttle.Allow(ctx, email, ip)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		s.logger.Warn("Повторная отправка письма подтверждения отклонена ограничителем", zap.String("email", email), zap.String("ip", ip))
+		return nil
+	}
+
+	user, err := s.users.GetUserByEmail(ctx, email)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified || !user.IsActive {
+		return nil
+	}
+	return s.SendVerification(ctx, user)
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/internal/loginguard"
+	"rcoi/internal/models"
+)
+
+func TestResendVerificationThrottled(t *testing.T) {
+	users := &fakeUserRepo{byID: map[int]*models.User{
+		1: {ID: 1, Email: "new@example.com", IsActive: true},
+	}}
+	tokens := &fakeResetRepo{}
+	mail := &countingMailer{}
+	throttle := loginguard.NewThrottle(loginguard.NewMemoryStore(), "mail", 2, 3, time.Hour)
+	s := NewEmailVerificationService(users, tokens, mail, throttle, "https://example.org", zap.NewNop())
+
+	for i := 0; i < 3; i++ {
+		if err := s.Resend(context.Background(), "new@example.com", "203.0.113.7"); err != nil {
+			t.Fatalf("запрос %d: %v", i+1, err)
+		}
+	}
+	if mail.sent != 2 || tokens.created != 2 {
+		t.Fatalf("отправлено писем: %d, создано токенов: %d; want 2 и 2", mail.sent, tokens.created)
+	}
+
+	// С того же IP лимит исчерпан и для другого адреса
+	users.byID[2] = &models.User{ID: 2, Email: "other@example.com", IsActive: true}
+	if err := s.Resend(context.Background(), "other@example.com", "203.0.113.7"); err != nil {
+		t.Fatal(err)
+	}
+	if mail.sent != 2 {
+		t.Fatalf("отправлено писем: %d сверх лимита IP", mail.sent)
+	}
+}
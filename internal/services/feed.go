@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"rcoi/config"
+	"rcoi/internal/feed"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+// FeedService формирует ленты последних опубликованных новостей. Лента одна на формат:
+// у новостей нет тегов и рубрик, по которым можно было бы строить отдельные ленты.
+type FeedService interface {
+	// NewsFeed возвращает документ ленты и время её последнего изменения.
+	// Готовые ленты хранятся в памяти cfg.CacheTTL, чтобы частые опросы читателей не нагружали БД.
+	NewsFeed(ctx context.Context, format feed.Format) ([]byte, time.Time, error)
+}
+
+type cachedFeed struct {
+	body         []byte
+	lastModified time.Time
+	expires      time.Time
+}
+
+type feedService struct {
+	repo    repositories.NewsRepository
+	cfg     config.FeedConfig
+	siteURL string
+
+	mu    sync.Mutex
+	cache map[feed.Format]cachedFeed
+}
+
+// NewFeedService создаёт сервис лент; siteURL — адрес публичного сайта, на страницы которого ведут ссылки
+func NewFeedService(repo repositories.NewsRepository, cfg config.FeedConfig, siteURL string) FeedService {
+	return &feedService{
+		repo:    repo,
+		cfg:     cfg,
+		siteURL: strings.TrimRight(siteURL, "/"),
+		cache:   make(map[feed.Format]cachedFeed),
+	}
+}
+
+func (s *feedService) NewsFeed(ctx context.Context, format feed.Format) ([]byte, time.Time, error) {
+	// Лента строится под блокировкой: одновременные запросы после истечения кеша ждут одну выборку
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if cached, ok := s.cache[format]; ok && now.Before(cached.expires) {
+		return cached.body, cached.lastModified, nil
+	}
+
+	f, lastModified, err := s.buildNewsFeed(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	body, err := f.Render(format)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	s.cache[format] = cachedFeed{body: body, lastModified: lastModified, expires: now.Add(s.cfg.CacheTTL)}
+	return body, lastModified, nil
+}
+
+// buildNewsFeed собирает ленту и время для Last-Modified. Updated самой ленты берётся
+// только из её записей, а Last-Modified учитывает и удаления и снятия с публикации,
+// после которых записи пропадают из ленты, не оставляя более позднего UpdatedAt.
+func (s *feedService) buildNewsFeed(ctx context.Context) (*feed.Feed, time.Time, error) {
+	lastModified, err := s.repo.LastModified(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	page, err := s.repo.GetPublished(ctx, models.ListQuery{Limit: s.cfg.Limit})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	f := &feed.Feed{
+		Title:       s.cfg.Title,
+		Description: s.cfg.Description,
+		Link:        s.siteURL + "/news",
+		Language:    "ru",
+	}
+	for _, news := range page.Items {
+		public := toPublicNews(news, true)
+		// Ссылка строится по slug, который не меняется, поэтому она же служит постоянным идентификатором
+		link := s.siteURL + "/news/" + public.Slug
+		f.Items = append(f.Items, feed.Item{
+			ID:        link,
+			Title:     public.Title,
+			Link:      link,
+			Summary:   public.Excerpt,
+			Content:   public.Content,
+			Published: public.PublishedAt,
+			Updated:   public.UpdatedAt,
+		})
+		if public.UpdatedAt.After(f.Updated) {
+			f.Updated = public.UpdatedAt
+		}
+	}
+	if f.Updated.After(lastModified) {
+		lastModified = f.Updated
+	}
+	// У пустой ленты нет записей, поэтому её временем служит последнее изменение новостей
+	if f.Updated.IsZero() {
+		f.Updated = lastModified
+	}
+	if f.Updated.IsZero() {
+		f.Updated = time.Now()
+		lastModified = f.Updated
+	}
+	return f, lastModified, nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rcoi/config"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+type fakeNewsRepo struct {
+	repositories.NewsRepository
+	published    []*models.News
+	lastModified time.Time
+
+	saved                     []models.News
+	duePublished, dueArchived int64
+	dueErr                    error
+}
+
+func (f *fakeNewsRepo) GetPublished(ctx context.Context, q models.ListQuery) (*models.Page[*models.News], error) {
+	return &models.Page[*models.News]{Items: f.published, Total: len(f.published), Limit: q.Limit}, nil
+}
+
+func (f *fakeNewsRepo) LastModified(ctx context.Context) (time.Time, error) {
+	return f.lastModified, nil
+}
+
+func TestBuildNewsFeedUpdated(t *testing.T) {
+	older := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	newer := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+	// Позже всех записей: например, удалили новость или у неё истёк unpublish_at
+	deleted := time.Date(2026, 10, 10, 9, 0, 0, 0, time.UTC)
+
+	items := []*models.News{
+		{ID: 1, Slug: "first", Title: "Первая", PublishAt: &older, UpdatedAt: older},
+		{ID: 2, Slug: "second", Title: "Вторая", PublishAt: &newer, UpdatedAt: newer},
+	}
+
+	tests := []struct {
+		name             string
+		repo             *fakeNewsRepo
+		wantUpdated      time.Time
+		wantLastModified time.Time
+	}{
+		{"по записям", &fakeNewsRepo{published: items, lastModified: newer}, newer, newer},
+		{"удаление после записей", &fakeNewsRepo{published: items, lastModified: deleted}, newer, deleted},
+		{"пустая лента", &fakeNewsRepo{lastModified: deleted}, deleted, deleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewFeedService(tt.repo, config.FeedConfig{Title: "Новости", Limit: 20}, "https://example.org/").(*feedService)
+			f, lastModified, err := s.buildNewsFeed(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !f.Updated.Equal(tt.wantUpdated) {
+				t.Errorf("Updated = %v, want %v", f.Updated, tt.wantUpdated)
+			}
+			if !lastModified.Equal(tt.wantLastModified) {
+				t.Errorf("lastModified = %v, want %v", lastModified, tt.wantLastModified)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"go.uber.org/zap"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+const (
+	totpPeriod        = 30
+	totpSkew          = 1
+	totpQRSize        = 256
+	recoveryCodeCount = 10
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("двухфакторная аутентификация уже подключена")
+	ErrMFANotEnrolled    = errors.New("подключение двухфакторной аутентификации не начато")
+	ErrMFANotEnabled     = errors.New("двухфакторная аутентификация не подключена")
+	ErrInvalidMFACode    = errors.New("неверный код подтверждения")
+)
+
+// TOTPEnrollment — данные для добавления учётной записи в приложение-аутентификатор
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+	QRPNG  []byte `json:"qr_png"`
+}
+
+// MFAService — второй фактор (TOTP) и одноразовые коды восстановления.
+// code везде — шестизначный TOTP-код или код восстановления.
+type MFAService interface {
+	Status(ctx context.Context, userID int) (*models.MFAStatus, error)
+	BeginEnrollment(ctx context.Context, userID int) (*TOTPEnrollment, error)
+	ConfirmEnrollment(ctx context.Context, userID int, code string) ([]string, error)
+	RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error)
+	Disable(ctx context.Context, userID int, code string) error
+	Reset(ctx context.Context, actor string, userID int) error
+	Verify(ctx context.Context, userID int, code string) error
+}
+
+type mfaService struct {
+	repo   repositories.MFARepository
+	users  repositories.UserRepository
+	issuer string
+	logger *zap.Logger
+}
+
+func NewMFAService(repo repositories.MFARepository, users repositories.UserRepository, issuer string, logger *zap.Logger) MFAService {
+	return &mfaService{repo: repo, users: users, issuer: issuer, logger: logger}
+}
+
+func (s *mfaService) Status(ctx context.Context, userID int) (*models.MFAStatus, error) {
+	t, err := s.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+
+	count, err := s.repo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MFAStatus{Enabled: t.Enabled, RecoveryCodesLeft: count}, nil
+}
+
+// BeginEnrollment создаёт новый секрет; TOTP включается только после ConfirmEnrollment
+func (s *mfaService) BeginEnrollment(ctx context.Context, userID int) (*TOTPEnrollment, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.issuer,
+		AccountName: user.Email,
+		Period:      totpPeriod,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetPendingSecret(ctx, userID, key.Secret()); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	img, err := key.Image(totpQRSize, totpQRSize)
+	if err != nil {
+		return nil, err
+	}
+	var qr bytes.Buffer
+	if err := png.Encode(&qr, img); err != nil {
+		return nil, err
+	}
+
+	return &TOTPEnrollment{Secret: key.Secret(), URI: key.URL(), QRPNG: qr.Bytes()}, nil
+}
+
+// ConfirmEnrollment включает TOTP по первому коду из приложения и возвращает коды восстановления.
+// Коды показываются один раз: в БД хранятся только их хеши.
+func (s *mfaService) ConfirmEnrollment(ctx context.Context, userID int, code string) ([]string, error) {
+	t, err := s.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+	if t.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if t.Secret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+
+	step, ok := matchTOTP(t.Secret, normalizeMFACode(code), t.LastStep)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Enable(ctx, userID, step, hashes); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Подключена двухфакторная аутентификация", zap.Int("user_id", userID))
+	return codes, nil
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Коды восстановления выпущены заново", zap.Int("user_id", userID))
+	return codes, nil
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID int, code string) error {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+
+	if err := s.repo.Disable(ctx, userID); err != nil {
+		return mapUserError(err)
+	}
+
+	s.logger.Info("Двухфакторная аутентификация отключена пользователем", zap.Int("user_id", userID))
+	return nil
+}
+
+// Reset отключает второй фактор без кода — для пользователя, потерявшего устройство; actor — email администратора
+func (s *mfaService) Reset(ctx context.Context, actor string, userID int) error {
+	if err := s.repo.Disable(ctx, userID); err != nil {
+		return mapUserError(err)
+	}
+
+	s.logger.Info("Администратор сбросил двухфакторную аутентификацию",
+		zap.String("admin", actor), zap.Int("user_id", userID))
+	return nil
+}
+
+// Verify проверяет TOTP-код (каждый код принимается один раз) или гасит код восстановления
+func (s *mfaService) Verify(ctx context.Context, userID int, code string) error {
+	t, err := s.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return mapUserError(err)
+	}
+	if !t.Enabled {
+		return ErrMFANotEnabled
+	}
+
+	code = normalizeMFACode(code)
+	if len(code) == int(otp.DigitsSix) {
+		step, ok := matchTOTP(t.Secret, code, t.LastStep)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		// Параллельный запрос мог успеть принять тот же код
+		if err := s.repo.UseStep(ctx, userID, step); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrInvalidMFACode
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := s.repo.ConsumeRecoveryCode(ctx, userID, hashOpaqueToken(code)); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrInvalidMFACode
+		}
+		return err
+	}
+
+	s.logger.Warn("Использован код восстановления", zap.Int("user_id", userID))
+	return nil
+}
+
+// matchTOTP ищет код в окне ±totpSkew шагов, пропуская шаги не новее lastStep
+func matchTOTP(secret, code string, lastStep int64) (int64, bool) {
+	now := time.Now()
+	opts := totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+
+	for i := -totpSkew; i <= totpSkew; i++ {
+		t := now.Add(time.Duration(i*totpPeriod) * time.Second)
+		step := t.Unix() / totpPeriod
+		if step <= lastStep {
+			continue
+		}
+
+		expected, err := totp.GenerateCodeCustom(secret, t, opts)
+		if err == nil && subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// newRecoveryCodes возвращает коды вида "abcd-efgh" и их хеши
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.EncodeToString(buf))
+		codes[i] = raw[:4] + "-" + raw[4:]
+		hashes[i] = hashOpaqueToken(raw)
+	}
+	return codes, hashes, nil
+}
+
+// normalizeMFACode убирает пробелы и дефисы, которые пользователи вводят вместе с кодом
+func normalizeMFACode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"go.uber.org/zap"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+func totpCodeAt(t *testing.T, at time.Time) string {
+	t.Helper()
+	code, err := totp.GenerateCodeCustom(testTOTPSecret, at, totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestMatchTOTP(t *testing.T) {
+	// matchTOTP берёт время сам: не начинаем на границе шага, чтобы шаг не сменился посреди теста
+	if left := totpPeriod - time.Now().Unix()%totpPeriod; left < 2 {
+		time.Sleep(time.Duration(left) * time.Second)
+	}
+	now := time.Now()
+	currentStep := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"текущий код", totpCodeAt(t, now), 0, currentStep, true},
+		{"предыдущий шаг в пределах окна", totpCodeAt(t, now.Add(-totpPeriod*time.Second)), 0, currentStep - 1, true},
+		{"повтор уже принятого кода", totpCodeAt(t, now), currentStep, 0, false},
+		{"код старше принятого", totpCodeAt(t, now.Add(-totpPeriod*time.Second)), currentStep, 0, false},
+		{"код вне окна", totpCodeAt(t, now.Add(-3*totpPeriod*time.Second)), 0, 0, false},
+		{"чужой код", "000000", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := matchTOTP(testTOTPSecret, tt.code, tt.lastStep)
+			if ok != tt.wantOK || (ok && step != tt.wantStep) {
+				t.Fatalf("matchTOTP = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+type fakeMFARepo struct {
+	repositories.MFARepository
+	totp          models.TOTP
+	recoveryCodes map[string]bool
+}
+
+func (f *fakeMFARepo) GetTOTP(ctx context.Context, userID int) (*models.TOTP, error) {
+	t := f.totp
+	return &t, nil
+}
+
+// UseStep, как и в БД, принимает только шаг новее последнего принятого
+func (f *fakeMFARepo) UseStep(ctx context.Context, userID int, step int64) error {
+	if step <= f.totp.LastStep {
+		return repositories.ErrNotFound
+	}
+	f.totp.LastStep = step
+	return nil
+}
+
+func (f *fakeMFARepo) ConsumeRecoveryCode(ctx context.Context, userID int, codeHash string) error {
+	if !f.recoveryCodes[codeHash] {
+		return repositories.ErrNotFound
+	}
+	delete(f.recoveryCodes, codeHash)
+	return nil
+}
+
+func TestMFAVerifyRejectsReplay(t *testing.T) {
+	repo := &fakeMFARepo{totp: models.TOTP{Secret: testTOTPSecret, Enabled: true}}
+	s := NewMFAService(repo, nil, "rcoi", zap.NewNop())
+	code := totpCodeAt(t, time.Now())
+
+	if err := s.Verify(context.Background(), 1, code[:3]+" "+code[3:]); err != nil {
+		t.Fatalf("первое предъявление кода: %v", err)
+	}
+	if err := s.Verify(context.Background(), 1, code); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("повтор кода = %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestMFAVerifyRecoveryCodeOnce(t *testing.T) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &fakeMFARepo{totp: models.TOTP{Secret: testTOTPSecret, Enabled: true}, recoveryCodes: map[string]bool{hashes[0]: true}}
+	s := NewMFAService(repo, nil, "rcoi", zap.NewNop())
+
+	if err := s.Verify(context.Background(), 1, " "+codes[0]+" "); err != nil {
+		t.Fatalf("код восстановления %q не принят: %v", codes[0], err)
+	}
+	if err := s.Verify(context.Background(), 1, codes[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("повтор кода восстановления = %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestMFAVerifyNotEnabled(t *testing.T) {
+	s := NewMFAService(&fakeMFARepo{totp: models.TOTP{Secret: testTOTPSecret}}, nil, "rcoi", zap.NewNop())
+	if err := s.Verify(context.Background(), 1, totpCodeAt(t, time.Now())); !errors.Is(err, ErrMFANotEnabled) {
+		t.Fatalf("Verify без подключённой MFA = %v", err)
+	}
+}
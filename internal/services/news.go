@@ -2,17 +2,54 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"time"
+
 	"go.uber.org/zap"
 	"rcoi/internal/models"
 	"rcoi/internal/repositories"
+	"rcoi/internal/slug"
+)
+
+// slugAttempts — сколько раз пробовать сохранить новость, если slug уже занят
+const slugAttempts = 4
+
+var (
+	ErrNewsNotFound          = errors.New("новость не найдена")
+	ErrInvalidNewsStatus     = errors.New("неизвестный статус новости")
+	ErrInvalidNewsTransition = errors.New("новость нельзя перевести в этот статус из текущего")
+	ErrInvalidPublishAt      = errors.New("для планирования укажите время публикации в будущем")
+	ErrInvalidUnpublishAt    = errors.New("время снятия с публикации должно быть позже времени публикации")
+	ErrNewsNotEditable       = errors.New("изменять можно только черновик или новость на рецензии: сначала верните её на рецензию")
 )
 
+// newsTransitions — допустимые переходы между статусами новости
+var newsTransitions = map[models.NewsStatus][]models.NewsStatus{
+	models.NewsDraft:     {models.NewsInReview},
+	models.NewsInReview:  {models.NewsDraft, models.NewsScheduled, models.NewsPublished},
+	models.NewsScheduled: {models.NewsInReview, models.NewsPublished},
+	models.NewsPublished: {models.NewsArchived},
+	models.NewsArchived:  {models.NewsDraft},
+}
+
 type NewsService interface {
+	// CreateNews создаёт черновик новости и выбирает для неё свободный slug по названию
 	CreateNews(ctx context.Context, news *models.News) error
-	GetNewsByID(ctx context.Context, id int) (*models.News, error)
-	GetAllNews(ctx context.Context) ([]*models.News, error)
+	// GetNewsByID при publishedOnly возвращает ErrNewsNotFound для новостей, скрытых от читателей
+	GetNewsByID(ctx context.Context, id int, publishedOnly bool) (*models.News, error)
+	GetAllNews(ctx context.Context, q models.ListQuery, filter models.NewsFilter) (*models.Page[*models.News], error)
+	// UpdateNews сохраняет новость версии news.Version; при успехе news.Version — новая версия.
+	// news.Status — текущий статус новости: вне draft и in_review возвращается ErrNewsNotEditable.
 	UpdateNews(ctx context.Context, news *models.News) error
-	DeleteNews(ctx context.Context, id int) error
+	// TransitionNews переводит новость версии news.Version в статус change.Status;
+	// reviewerID сохраняется для статусов, которые выставляет рецензент
+	TransitionNews(ctx context.Context, news *models.News, change models.NewsTransition, reviewerID *int) error
+	DeleteNews(ctx context.Context, id, version int) error
+	// PublishDue публикует и снимает с публикации новости по расписанию; вызывается планировщиком
+	PublishDue(ctx context.Context) error
 }
 
 type newsService struct {
@@ -25,21 +62,101 @@ func NewNewsService(repo repositories.NewsRepository, logger *zap.Logger) NewsSe
 }
 
 func (s *newsService) CreateNews(ctx context.Context, news *models.News) error {
-	return s.repo.Create(ctx, news)
+	base := slug.Make(news.Title, "news")
+	news.Slug = base
+	for attempt := 1; ; attempt++ {
+		err := s.repo.Create(ctx, news)
+		if !errors.Is(err, repositories.ErrConflict) || attempt == slugAttempts {
+			return err
+		}
+
+		// Такая новость уже есть: добавляем к slug случайный суффикс
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return err
+		}
+		news.Slug = base + "-" + hex.EncodeToString(suffix)
+	}
 }
 
-func (s *newsService) GetNewsByID(ctx context.Context, id int) (*models.News, error) {
-	return s.repo.GetByID(ctx, id)
+func (s *newsService) GetNewsByID(ctx context.Context, id int, publishedOnly bool) (*models.News, error) {
+	news, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapNewsError(err)
+	}
+	if publishedOnly && !news.IsPublic(time.Now()) {
+		return nil, ErrNewsNotFound
+	}
+	return news, nil
 }
 
-func (s *newsService) GetAllNews(ctx context.Context) ([]*models.News, error) {
-	return s.repo.GetAll(ctx)
+func (s *newsService) GetAllNews(ctx context.Context, q models.ListQuery, filter models.NewsFilter) (*models.Page[*models.News], error) {
+	if _, ok := newsTransitions[filter.Status]; filter.Status != "" && !ok {
+		return nil, ErrInvalidNewsStatus
+	}
+	return s.repo.GetAll(ctx, q, filter)
 }
 
 func (s *newsService) UpdateNews(ctx context.Context, news *models.News) error {
-	return s.repo.Update(ctx, news)
+	// Статус проверен по той же версии, что сохраняется: смена статуса увеличивает версию
+	if !news.Status.Editable() {
+		return ErrNewsNotEditable
+	}
+	return mapNewsError(s.repo.Update(ctx, news))
+}
+
+func (s *newsService) TransitionNews(ctx context.Context, news *models.News, change models.NewsTransition, reviewerID *int) error {
+	if !slices.Contains(newsTransitions[news.Status], change.Status) {
+		return ErrInvalidNewsTransition
+	}
+
+	now := time.Now()
+	publishAt, unpublishAt := news.PublishAt, news.UnpublishAt
+	switch change.Status {
+	case models.NewsDraft, models.NewsInReview:
+		publishAt, unpublishAt = nil, nil
+	case models.NewsScheduled:
+		if change.PublishAt == nil || !change.PublishAt.After(now) {
+			return ErrInvalidPublishAt
+		}
+		publishAt, unpublishAt = change.PublishAt, change.UnpublishAt
+	case models.NewsPublished:
+		publishAt, unpublishAt = &now, change.UnpublishAt
+	case models.NewsArchived:
+		unpublishAt = &now
+	}
+	if change.Status == models.NewsScheduled || change.Status == models.NewsPublished {
+		if unpublishAt != nil && !unpublishAt.After(*publishAt) {
+			return ErrInvalidUnpublishAt
+		}
+	}
+
+	news.Status, news.PublishAt, news.UnpublishAt = change.Status, publishAt, unpublishAt
+	if change.Status.NeedsReviewer() {
+		news.ReviewerID = reviewerID
+	}
+	return mapNewsError(s.repo.SetStatus(ctx, news))
+}
+
+func (s *newsService) DeleteNews(ctx context.Context, id, version int) error {
+	return mapNewsError(s.repo.Delete(ctx, id, version))
+}
+
+func (s *newsService) PublishDue(ctx context.Context) error {
+	published, archived, err := s.repo.PublishDue(ctx)
+	if err != nil {
+		return err
+	}
+	if published > 0 || archived > 0 {
+		s.logger.Info("Обновлены статусы новостей по расписанию",
+			zap.Int64("published", published), zap.Int64("archived", archived))
+	}
+	return nil
 }
 
-func (s *newsService) DeleteNews(ctx context.Context, id int) error {
-	return s.repo.Delete(ctx, id)
+func mapNewsError(err error) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrNewsNotFound
+	}
+	return mapVersionError(err)
 }
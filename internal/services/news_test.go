@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/internal/models"
+)
+
+func (f *fakeNewsRepo) SetStatus(ctx context.Context, news *models.News) error {
+	news.Version++
+	f.saved = append(f.saved, *news)
+	return nil
+}
+
+func (f *fakeNewsRepo) PublishDue(ctx context.Context) (int64, int64, error) {
+	return f.duePublished, f.dueArchived, f.dueErr
+}
+
+var allNewsStatuses = []models.NewsStatus{
+	models.NewsDraft, models.NewsInReview, models.NewsScheduled, models.NewsPublished, models.NewsArchived,
+}
+
+func TestNewsTransitions(t *testing.T) {
+	// Ожидаемый граф переходов записан явно, а не берётся из newsTransitions
+	allowed := map[models.NewsStatus][]models.NewsStatus{
+		models.NewsDraft:     {models.NewsInReview},
+		models.NewsInReview:  {models.NewsDraft, models.NewsScheduled, models.NewsPublished},
+		models.NewsScheduled: {models.NewsInReview, models.NewsPublished},
+		models.NewsPublished: {models.NewsArchived},
+		models.NewsArchived:  {models.NewsDraft},
+	}
+	future := time.Now().Add(time.Hour)
+
+	for _, from := range allNewsStatuses {
+		for _, to := range allNewsStatuses {
+			repo := &fakeNewsRepo{}
+			s := NewNewsService(repo, zap.NewNop())
+			news := &models.News{ID: 1, Status: from, Version: 1}
+
+			err := s.TransitionNews(context.Background(), news, models.NewsTransition{Status: to, PublishAt: &future}, nil)
+
+			want := slices.Contains(allowed[from], to)
+			if (err == nil) != want {
+				t.Errorf("%s → %s: error = %v, допустим = %v", from, to, err, want)
+			}
+			if !want && (!errors.Is(err, ErrInvalidNewsTransition) || len(repo.saved) != 0) {
+				t.Errorf("%s → %s: недопустимый переход сохранён или ошибка %v", from, to, err)
+			}
+		}
+	}
+}
+
+func TestTransitionNewsSchedule(t *testing.T) {
+	now := time.Now()
+	past, soon, later := now.Add(-time.Minute), now.Add(time.Hour), now.Add(2*time.Hour)
+	reviewer := 7
+
+	tests := []struct {
+		name   string
+		from   models.NewsStatus
+		change models.NewsTransition
+		want   error
+	}{
+		{"планирование без времени", models.NewsInReview, models.NewsTransition{Status: models.NewsScheduled}, ErrInvalidPublishAt},
+		{"планирование в прошлое", models.NewsInReview, models.NewsTransition{Status: models.NewsScheduled, PublishAt: &past}, ErrInvalidPublishAt},
+		{"снятие раньше публикации", models.NewsInReview, models.NewsTransition{Status: models.NewsScheduled, PublishAt: &later, UnpublishAt: &soon}, ErrInvalidUnpublishAt},
+		{"снятие в прошлом при публикации", models.NewsInReview, models.NewsTransition{Status: models.NewsPublished, UnpublishAt: &past}, ErrInvalidUnpublishAt},
+		{"планирование", models.NewsInReview, models.NewsTransition{Status: models.NewsScheduled, PublishAt: &soon, UnpublishAt: &later}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeNewsRepo{}
+			err := NewNewsService(repo, zap.NewNop()).TransitionNews(context.Background(), &models.News{ID: 1, Status: tt.from}, tt.change, &reviewer)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				return
+			}
+			saved := repo.saved[0]
+			if !saved.PublishAt.Equal(soon) || !saved.UnpublishAt.Equal(later) || saved.ReviewerID == nil || *saved.ReviewerID != reviewer {
+				t.Fatalf("сохранено %+v", saved)
+			}
+		})
+	}
+}
+
+func TestTransitionNewsTimes(t *testing.T) {
+	repo := &fakeNewsRepo{}
+	s := NewNewsService(repo, zap.NewNop())
+	reviewer := 7
+	news := &models.News{ID: 1, Status: models.NewsInReview}
+
+	before := time.Now()
+	if err := s.TransitionNews(context.Background(), news, models.NewsTransition{Status: models.NewsPublished}, &reviewer); err != nil {
+		t.Fatal(err)
+	}
+	if news.PublishAt == nil || news.PublishAt.Before(before) || news.ReviewerID == nil {
+		t.Fatalf("публикация: PublishAt = %v, ReviewerID = %v", news.PublishAt, news.ReviewerID)
+	}
+
+	if err := s.TransitionNews(context.Background(), news, models.NewsTransition{Status: models.NewsArchived}, &reviewer); err != nil {
+		t.Fatal(err)
+	}
+	if news.UnpublishAt == nil || news.UnpublishAt.Before(*news.PublishAt) || news.IsPublic(time.Now()) {
+		t.Fatalf("архив: UnpublishAt = %v, новость видна читателям", news.UnpublishAt)
+	}
+
+	// Возврат в черновик сбрасывает расписание: иначе планировщик снова опубликовал бы новость
+	if err := s.TransitionNews(context.Background(), news, models.NewsTransition{Status: models.NewsDraft}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if news.PublishAt != nil || news.UnpublishAt != nil {
+		t.Fatalf("черновик сохранил расписание: %v, %v", news.PublishAt, news.UnpublishAt)
+	}
+}
+
+func TestNewsServicePublishDue(t *testing.T) {
+	repo := &fakeNewsRepo{duePublished: 2, dueArchived: 1}
+	s := NewNewsService(repo, zap.NewNop())
+	if err := s.PublishDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	repo.dueErr = errors.New("db down")
+	if err := s.PublishDue(context.Background()); !errors.Is(err, repo.dueErr) {
+		t.Fatalf("ошибка репозитория не передана планировщику: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+
+	"rcoi/internal/passwordpolicy"
+	"rcoi/internal/repositories"
+)
+
+// newPasswordHash проверяет пароль нового пользователя по политике и возвращает его bcrypt-хеш
+func newPasswordHash(policy *passwordpolicy.Policy, password string) (string, error) {
+	if err := policy.Validate(password); err != nil {
+		return "", err
+	}
+	return hashPassword(password)
+}
+
+// changedPasswordHash проверяет новый пароль пользователя по политике и истории его паролей
+// и возвращает bcrypt-хеш для storePassword
+func changedPasswordHash(ctx context.Context, policy *passwordpolicy.Policy, users repositories.UserRepository, userID int, password string) (string, error) {
+	if err := policy.Validate(password); err != nil {
+		return "", err
+	}
+
+	if size := policy.HistorySize(); size > 0 {
+		history, err := users.GetPasswordHistory(ctx, userID, size)
+		if err != nil {
+			return "", err
+		}
+		for _, hash := range history {
+			if checkPassword(hash, password) {
+				return "", policy.Reused()
+			}
+		}
+	}
+
+	return hashPassword(password)
+}
+
+// storePassword сохраняет новый хеш; в истории остаётся столько прежних паролей, сколько требует политика
+func storePassword(ctx context.Context, policy *passwordpolicy.Policy, users repositories.UserRepository, userID int, hashedPassword string) error {
+	return users.UpdatePassword(ctx, userID, hashedPassword, policy.HistorySize()-1)
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/internal/denylist"
+	"rcoi/internal/loginguard"
+	"rcoi/internal/mailer"
+	"rcoi/internal/passwordpolicy"
+	"rcoi/internal/repositories"
+)
+
+const passwordResetTTL = time.Hour
+
+var ErrInvalidResetToken = errors.New("ссылка для сброса пароля недействительна или устарела")
+
+type PasswordResetService interface {
+	// ForgotPassword отвечает одинаково для любого email, в том числе при сбое отправки письма,
+	// чтобы по ответу нельзя было узнать, зарегистрирован ли адрес
+	// ip — адрес клиента для ограничения частоты писем
+	ForgotPassword(ctx context.Context, email, ip string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+type passwordResetService struct {
+	users    repositories.UserRepository
+	sessions repositories.SessionRepository
+	resets   repositories.OneTimeTokenRepository
+	revoked  denylist.Store
+	mailer   mailer.Mailer
+	throttle *loginguard.Throttle
+	policy   *passwordpolicy.Policy
+	baseURL  string
+	logger   *zap.Logger
+}
+
+func NewPasswordResetService(users repositories.UserRepository, sessions repositories.SessionRepository, resets repositories.OneTimeTokenRepository, revoked denylist.Store, m mailer.Mailer, throttle *loginguard.Throttle, policy *passwordpolicy.Policy, baseURL string, logger *zap.Logger) PasswordResetService {
+	return &passwordResetService{users: users, sessions: sessions, resets: resets, revoked: revoked, mailer: m, throttle: throttle, policy: policy, baseURL: baseURL, logger: logger}
+}
+
+// ForgotPassword отправляет ссылку для сброса пароля.
+// Отсутствие пользователя не считается ошибкой, чтобы не раскрывать, какие email зарегистрированы.
+func (s *passwordResetService) ForgotPassword(ctx context.Context, email, ip string) error {
+	// Лимит проверяется до поиска пользователя, чтобы он одинаково действовал на любые email
+	allowed, err := s.throttle.Allow(ctx, email, ip)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		s.logger.Warn("Запрос сброса пароля отклонён ограничителем", zap.String("email", email), zap.String("ip", ip))
+		return nil
+	}
+
+	user, err := s.users.GetUserByEmail(ctx, email)
+	if errors.Is(err, repositories.ErrNotFound) {
+		s.logger.Info("Запрос сброса пароля для неизвестного email", zap.String("email", email))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !user.IsActive {
+		s.logger.Info("Запрос сброса пароля для отключённой учётной записи", zap.String("email", email))
+		return nil
+	}
+
+	token, hash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.resets.Create(ctx, user.ID, hash, passwordResetTTL); err != nil {
+		return err
+	}
+
+	link := s.baseURL + "/reset-password?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля",
+		Body: fmt.Sprintf("Для сброса пароля перейдите по ссылке:\n\n%s\n\n"+
+			"Ссылка действует %d мин. Если вы не запрашивали сброс, просто проигнорируйте это письмо.",
+			link, int(passwordResetTTL.Minutes())),
+	}
+
+	// Ошибка почты только журналируется: другой ответ выдал бы, что адрес зарегистрирован
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		s.logger.Error("Ошибка отправки письма для сброса пароля", zap.String("email", email), zap.Error(err))
+		return nil
+	}
+
+	s.logger.Info("Отправлена ссылка для сброса пароля", zap.String("email", email))
+	return nil
+}
+
+// ResetPassword устанавливает новый пароль по одноразовому токену и завершает все сессии.
+// Токен гасится только после проверки пароля, чтобы неподходящий пароль не сжигал ссылку из письма.
+func (s *passwordResetService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	tokenHash := hashOpaqueToken(token)
+	userID, err := s.resets.Peek(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	hashedPassword, err := changedPasswordHash(ctx, s.policy, s.users, userID, newPassword)
+	if err != nil {
+		return err
+	}
+
+	// Параллельный запрос мог успеть воспользоваться этим же токеном
+	if _, err := s.resets.Consume(ctx, tokenHash); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := storePassword(ctx, s.policy, s.users, userID, hashedPassword); err != nil {
+		return err
+	}
+
+	accessJTIs, err := s.sessions.RevokeAllForUser(ctx, userID, "")
+	if err != nil {
+		return err
+	}
+	if err := denyAccessTokens(ctx, s.revoked, accessJTIs...); err != nil {
+		return err
+	}
+
+	// Переход по ссылке из письма подтверждает владение адресом
+	if !user.EmailVerified {
+		if err := s.users.SetEmailVerified(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("Пароль сброшен по ссылке из письма", zap.String("email", user.Email))
+	return nil
+}
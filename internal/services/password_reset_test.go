@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/internal/loginguard"
+	"rcoi/internal/mailer"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+type fakeResetRepo struct {
+	repositories.OneTimeTokenRepository
+	created int
+}
+
+func (f *fakeResetRepo) Create(ctx context.Context, userID int, tokenHash string, ttl time.Duration) error {
+	f.created++
+	return nil
+}
+
+type failingMailer struct{}
+
+func (failingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	return errors.New("dial tcp: connection refused")
+}
+
+func TestForgotPasswordHidesMailerFailure(t *testing.T) {
+	users := &fakeUserRepo{byID: map[int]*models.User{
+		1: {ID: 1, Email: "known@example.com", IsActive: true},
+	}}
+	resets := &fakeResetRepo{}
+	throttle := loginguard.NewThrottle(loginguard.NewMemoryStore(), "mail", 0, 0, time.Hour)
+	s := NewPasswordResetService(users, nil, resets, nil, failingMailer{}, throttle, nil, "https://example.org", zap.NewNop())
+
+	for _, email := range []string{"known@example.com", "unknown@example.com"} {
+		if err := s.ForgotPassword(context.Background(), email, "203.0.113.7"); err != nil {
+			t.Errorf("ForgotPassword(%q) = %v, ответ должен быть одинаковым", email, err)
+		}
+	}
+	if resets.created != 1 {
+		t.Fatalf("создано токенов: %d, want 1", resets.created)
+	}
+}
+
+type countingMailer struct {
+	sent int
+}
+
+func (m *countingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.sent++
+	return nil
+}
+
+func TestForgotPasswordThrottled(t *testing.T) {
+	users := &fakeUserRepo{byID: map[int]*models.User{
+		1: {ID: 1, Email: "known@example.com", IsActive: true},
+	}}
+	resets := &fakeResetRepo{}
+	mail := &countingMailer{}
+	throttle := loginguard.NewThrottle(loginguard.NewMemoryStore(), "mail", 2, 5, time.Hour)
+	s := NewPasswordResetService(users, nil, resets, nil, mail, throttle, nil, "https://example.org", zap.NewNop())
+
+	for i := 0; i < 4; i++ {
+		if err := s.ForgotPassword(context.Background(), "known@example.com", "203.0.113.7"); err != nil {
+			t.Fatalf("запрос %d: %v, ответ сверх лимита должен быть тем же", i+1, err)
+		}
+	}
+	if mail.sent != 2 || resets.created != 2 {
+		t.Fatalf("отправлено писем: %d, создано токенов: %d; want 2 и 2", mail.sent, resets.created)
+	}
+
+	// Лимит адреса не обходится сменой IP
+	if err := s.ForgotPassword(context.Background(), "known@example.com", "203.0.113.8"); err != nil {
+		t.Fatal(err)
+	}
+	if mail.sent != 2 {
+		t.Fatalf("отправлено писем: %d сверх лимита адреса", mail.sent)
+	}
+}
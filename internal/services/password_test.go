@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rcoi/config"
+	"rcoi/internal/passwordpolicy"
+	"rcoi/internal/repositories"
+)
+
+type fakeHistoryRepo struct {
+	repositories.UserRepository
+	history []string
+}
+
+func (f *fakeHistoryRepo) GetPasswordHistory(ctx context.Context, userID, limit int) ([]string, error) {
+	return f.history[:min(limit, len(f.history))], nil
+}
+
+func TestChangedPasswordHashRejectsReuse(t *testing.T) {
+	var history []string
+	for _, password := range []string{"Current-pass-1", "Previous-pass-1", "Oldest-pass-1"} {
+		hash, err := hashPassword(password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		history = append(history, hash)
+	}
+	users := &fakeHistoryRepo{history: history}
+	policy, err := passwordpolicy.New(config.PasswordConfig{MinLength: 8, HistorySize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		password string
+		reused   bool
+	}{
+		{"Current-pass-1", true},
+		{"Previous-pass-1", true},
+		// Пароль старше HistorySize снова разрешён
+		{"Oldest-pass-1", false},
+		{"Brand-new-pass-1", false},
+	}
+	for _, tt := range tests {
+		hash, err := changedPasswordHash(context.Background(), policy, users, 1, tt.password)
+		var violation *passwordpolicy.ViolationError
+		if reused := errors.As(err, &violation); reused != tt.reused {
+			t.Errorf("changedPasswordHash(%q) error = %v, reused want %v", tt.password, err, tt.reused)
+		}
+		if !tt.reused && !checkPassword(hash, tt.password) {
+			t.Errorf("хеш для %q не подходит к паролю", tt.password)
+		}
+	}
+}
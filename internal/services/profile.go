@@ -0,0 +1,221 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rcoi/internal/denylist"
+	"rcoi/internal/loginguard"
+	"rcoi/internal/models"
+	"rcoi/internal/passwordpolicy"
+	"rcoi/internal/repositories"
+	"rcoi/internal/storage"
+)
+
+// MaxAvatarSize — предельный размер загружаемого аватара
+const MaxAvatarSize = 2 << 20
+
+const (
+	profileFieldMaxLen = 255
+	// avatarURL — адрес, по которому владелец профиля получает свой аватар
+	avatarURL = "/api/profile/avatar"
+)
+
+// Допустимые форматы аватара и расширения файлов в хранилище
+var avatarTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()\-]{4,30}$`)
+
+var (
+	ErrProfileFieldTooLong = fmt.Errorf("поля профиля не должны быть длиннее %d символов", profileFieldMaxLen)
+	ErrInvalidPhone        = errors.New("некорректный номер телефона")
+	ErrInvalidAvatar       = errors.New("аватар должен быть изображением PNG, JPEG, GIF или WebP")
+	ErrAvatarTooLarge      = fmt.Errorf("аватар не должен превышать %d МБ", MaxAvatarSize>>20)
+	ErrAvatarNotFound      = errors.New("аватар не загружен")
+	ErrWrongPassword       = errors.New("текущий пароль указан неверно")
+)
+
+// ProfileService — профиль текущего пользователя и смена пароля
+type ProfileService interface {
+	GetProfile(ctx context.Context, userID int) (*models.User, error)
+	UpdateProfile(ctx context.Context, userID int, update models.ProfileUpdate) (*models.User, error)
+	SetAvatar(ctx context.Context, userID int, r io.Reader) error
+	OpenAvatar(ctx context.Context, userID int) (io.ReadCloser, *storage.ObjectInfo, error)
+	DeleteAvatar(ctx context.Context, userID int) error
+	ChangePassword(ctx context.Context, userID int, currentSessionID, currentPassword, newPassword string, meta SessionMeta) error
+}
+
+type profileService struct {
+	users    repositories.UserRepository
+	sessions repositories.SessionRepository
+	revoked  denylist.Store
+	guard    *loginguard.Guard
+	policy   *passwordpolicy.Policy
+	files    storage.Backend
+	logger   *zap.Logger
+}
+
+func NewProfileService(users repositories.UserRepository, sessions repositories.SessionRepository, revoked denylist.Store, guard *loginguard.Guard, policy *passwordpolicy.Policy, files storage.Backend, logger *zap.Logger) ProfileService {
+	return &profileService{users: users, sessions: sessions, revoked: revoked, guard: guard, policy: policy, files: files, logger: logger}
+}
+
+func (s *profileService) GetProfile(ctx context.Context, userID int) (*models.User, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+	if user.AvatarKey != "" {
+		user.AvatarURL = avatarURL
+	}
+	return user, nil
+}
+
+func (s *profileService) UpdateProfile(ctx context.Context, userID int, update models.ProfileUpdate) (*models.User, error) {
+	for _, field := range []*string{update.FullName, update.Position, update.OrgUnit, update.Phone} {
+		if field == nil {
+			continue
+		}
+		*field = strings.TrimSpace(*field)
+		if utf8.RuneCountInString(*field) > profileFieldMaxLen {
+			return nil, ErrProfileFieldTooLong
+		}
+	}
+	if update.Phone != nil && *update.Phone != "" && !phonePattern.MatchString(*update.Phone) {
+		return nil, ErrInvalidPhone
+	}
+
+	if err := s.users.UpdateProfile(ctx, userID, update); err != nil {
+		return nil, mapUserError(err)
+	}
+	return s.GetProfile(ctx, userID)
+}
+
+// SetAvatar сохраняет изображение под новым ключом и удаляет прежнее.
+// Формат определяется по содержимому файла, а не по заявленному Content-Type.
+func (s *profileService) SetAvatar(ctx context.Context, userID int, r io.Reader) error {
+	buf := bufio.NewReaderSize(r, 512)
+	head, err := buf.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	contentType := http.DetectContentType(head)
+	ext, ok := avatarTypes[contentType]
+	if !ok {
+		return ErrInvalidAvatar
+	}
+
+	data, err := io.ReadAll(io.LimitReader(buf, MaxAvatarSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > MaxAvatarSize {
+		return ErrAvatarTooLarge
+	}
+
+	key := fmt.Sprintf("avatars/%d_%s%s", userID, uuid.NewString(), ext)
+	if err := s.files.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return err
+	}
+
+	prevKey, err := s.users.SetAvatar(ctx, userID, key)
+	if err != nil {
+		_ = s.files.Delete(ctx, key)
+		return mapUserError(err)
+	}
+	if prevKey != "" && prevKey != key {
+		_ = s.files.Delete(ctx, prevKey)
+	}
+	return nil
+}
+
+func (s *profileService) OpenAvatar(ctx context.Context, userID int) (io.ReadCloser, *storage.ObjectInfo, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, mapUserError(err)
+	}
+	if user.AvatarKey == "" {
+		return nil, nil, ErrAvatarNotFound
+	}
+
+	rc, info, err := s.files.Get(ctx, user.AvatarKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrAvatarNotFound
+	}
+	return rc, info, err
+}
+
+func (s *profileService) DeleteAvatar(ctx context.Context, userID int) error {
+	prevKey, err := s.users.SetAvatar(ctx, userID, "")
+	if err != nil {
+		return mapUserError(err)
+	}
+	if prevKey == "" {
+		return ErrAvatarNotFound
+	}
+	_ = s.files.Delete(ctx, prevKey)
+	return nil
+}
+
+// ChangePassword меняет пароль после проверки текущего и завершает все сессии, кроме текущей.
+// Неверный текущий пароль учитывается ограничителем входа, чтобы украденный access-токен
+// не позволял подбирать пароль.
+func (s *profileService) ChangePassword(ctx context.Context, userID int, currentSessionID, currentPassword, newPassword string, meta SessionMeta) error {
+	profile, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return mapUserError(err)
+	}
+
+	if err := s.guard.Check(ctx, profile.Email, meta.IP); err != nil {
+		return err
+	}
+
+	user, err := s.users.GetUserByEmail(ctx, profile.Email)
+	if err != nil {
+		return mapUserError(err)
+	}
+
+	if !checkPassword(user.Password, currentPassword) {
+		s.logger.Warn("Неверный текущий пароль при смене пароля", zap.Int("user_id", userID))
+		if err := s.guard.Fail(ctx, user.Email, meta.IP); err != nil {
+			s.logger.Error("Ошибка учёта неудачной попытки входа", zap.String("email", user.Email), zap.Error(err))
+		}
+		return ErrWrongPassword
+	}
+	if err := s.guard.Succeed(ctx, user.Email); err != nil {
+		s.logger.Error("Ошибка сброса счётчика попыток входа", zap.String("email", user.Email), zap.Error(err))
+	}
+
+	hashedPassword, err := changedPasswordHash(ctx, s.policy, s.users, userID, newPassword)
+	if err != nil {
+		return err
+	}
+	if err := storePassword(ctx, s.policy, s.users, userID, hashedPassword); err != nil {
+		return err
+	}
+
+	accessJTIs, err := s.sessions.RevokeAllForUser(ctx, userID, currentSessionID)
+	if err != nil {
+		return err
+	}
+	if err := denyAccessTokens(ctx, s.revoked, accessJTIs...); err != nil {
+		return err
+	}
+
+	s.logger.Info("Пользователь сменил пароль", zap.Int("user_id", userID), zap.Int("revoked_sessions", len(accessJTIs)))
+	return nil
+}
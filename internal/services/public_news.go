@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+// excerptLength — максимальная длина анонса новости в символах
+const excerptLength = 200
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// PublicNewsService отдаёт опубликованные новости анонимным читателям публичного сайта
+type PublicNewsService interface {
+	GetPublishedNews(ctx context.Context, q models.ListQuery) (*models.Page[*models.PublicNews], error)
+	GetPublishedNewsBySlug(ctx context.Context, slug string) (*models.PublicNews, error)
+	// LastModified — время последнего изменения опубликованных новостей, включая удаления
+	// и снятие с публикации; нулевое, если изменений не было
+	LastModified(ctx context.Context) (time.Time, error)
+}
+
+type publicNewsService struct {
+	repo repositories.NewsRepository
+}
+
+func NewPublicNewsService(repo repositories.NewsRepository) PublicNewsService {
+	return &publicNewsService{repo: repo}
+}
+
+func (s *publicNewsService) GetPublishedNews(ctx context.Context, q models.ListQuery) (*models.Page[*models.PublicNews], error) {
+	page, err := s.repo.GetPublished(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.Page[*models.PublicNews]{
+		Items:      make([]*models.PublicNews, 0, len(page.Items)),
+		Total:      page.Total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: page.NextCursor,
+	}
+	for _, news := range page.Items {
+		result.Items = append(result.Items, toPublicNews(news, false))
+	}
+	return result, nil
+}
+
+func (s *publicNewsService) GetPublishedNewsBySlug(ctx context.Context, slug string) (*models.PublicNews, error) {
+	news, err := s.repo.GetPublishedBySlug(ctx, slug)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrNewsNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toPublicNews(news, true), nil
+}
+
+func (s *publicNewsService) LastModified(ctx context.Context) (time.Time, error) {
+	return s.repo.LastModified(ctx)
+}
+
+// toPublicNews оставляет только поля для читателей; текст целиком — при withContent
+func toPublicNews(news *models.News, withContent bool) *models.PublicNews {
+	public := &models.PublicNews{
+		ID:        news.ID,
+		Slug:      news.Slug,
+		Title:     news.Title,
+		Excerpt:   excerpt(news.Content, excerptLength),
+		UpdatedAt: news.UpdatedAt,
+	}
+	if news.PublishAt != nil {
+		public.PublishedAt = *news.PublishAt
+	}
+	if withContent {
+		public.Content = news.Content
+	}
+	return public
+}
+
+// excerpt — начало текста без HTML-разметки, обрезанное по границе слова до limit символов
+func excerpt(content string, limit int) string {
+	text := strings.Join(strings.Fields(html.UnescapeString(htmlTag.ReplaceAllString(content, " "))), " ")
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+
+	runes := []rune(text)[:limit]
+	if i := strings.LastIndexByte(string(runes), ' '); i > 0 {
+		return strings.TrimRight(string(runes)[:i], ".,;:—- ") + "…"
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"go.uber.org/zap"
+	"rcoi/internal/denylist"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("роль не найдена")
+	ErrRoleExists        = errors.New("роль уже существует")
+	ErrRoleInUse         = errors.New("роль назначена пользователям")
+	ErrRoleProtected     = errors.New("встроенную роль нельзя удалить")
+	ErrUnknownPermission = errors.New("неизвестное право доступа")
+	ErrRoleLockout       = errors.New("нельзя снять управление ролями и пользователями с роли admin или со своей роли")
+)
+
+// Встроенные роли, без которых сервис не работает
+var protectedRoles = map[string]bool{"admin": true, "user": true}
+
+// Права, без которых администраторы не смогут вернуть себе доступ
+var lockoutPermissions = []string{models.PermRolesManage, models.PermUsersManage}
+
+type RoleService interface {
+	GetAllRoles(ctx context.Context) ([]*models.Role, error)
+	GetAllPermissions(ctx context.Context) ([]*models.Permission, error)
+	CreateRole(ctx context.Context, actor string, role *models.Role) error
+	// SetPermissions заменяет права роли. actorRole — роль того, кто вносит изменение:
+	// снять с неё или с admin права из lockoutPermissions нельзя
+	SetPermissions(ctx context.Context, actor, actorRole, role string, permissions []string) error
+	DeleteRole(ctx context.Context, actor, role string) error
+}
+
+type roleService struct {
+	repo     repositories.RoleRepository
+	sessions repositories.SessionRepository
+	revoked  denylist.Store
+	logger   *zap.Logger
+}
+
+func NewRoleService(repo repositories.RoleRepository, sessions repositories.SessionRepository, revoked denylist.Store, logger *zap.Logger) RoleService {
+	return &roleService{repo: repo, sessions: sessions, revoked: revoked, logger: logger}
+}
+
+func (s *roleService) GetAllRoles(ctx context.Context) ([]*models.Role, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *roleService) GetAllPermissions(ctx context.Context) ([]*models.Permission, error) {
+	return s.repo.GetAllPermissions(ctx)
+}
+
+func (s *roleService) CreateRole(ctx context.Context, actor string, role *models.Role) error {
+	if err := s.repo.Create(ctx, role); err != nil {
+		return mapRoleError(err)
+	}
+
+	s.logger.Info("Администратор создал роль",
+		zap.String("admin", actor), zap.String("role", role.Name), zap.Strings("permissions", role.Permissions))
+	return nil
+}
+
+func (s *roleService) SetPermissions(ctx context.Context, actor, actorRole, role string, permissions []string) error {
+	if role == "admin" || role == actorRole {
+		current, err := s.repo.GetPermissions(ctx, role)
+		if err != nil {
+			return mapRoleError(err)
+		}
+		for _, p := range lockoutPermissions {
+			if slices.Contains(current, p) && !slices.Contains(permissions, p) {
+				return ErrRoleLockout
+			}
+		}
+	}
+
+	if err := s.repo.SetPermissions(ctx, role, permissions); err != nil {
+		return mapRoleError(err)
+	}
+
+	// Права зашиты в access-токены: отзываем их у всех пользователей роли, иначе до истечения
+	// токена снятое право продолжало бы действовать, а выданное — отвечать 403
+	accessJTIs, err := s.sessions.GetAccessJTIsByRole(ctx, role)
+	if err != nil {
+		return err
+	}
+	if err := denyAccessTokens(ctx, s.revoked, accessJTIs...); err != nil {
+		return err
+	}
+
+	s.logger.Info("Администратор изменил права роли",
+		zap.String("admin", actor), zap.String("role", role), zap.Strings("permissions", permissions))
+	return nil
+}
+
+func (s *roleService) DeleteRole(ctx context.Context, actor, role string) error {
+	if protectedRoles[role] {
+		return ErrRoleProtected
+	}
+
+	if err := s.repo.Delete(ctx, role); err != nil {
+		if errors.Is(err, repositories.ErrForeignKey) {
+			return ErrRoleInUse
+		}
+		return mapRoleError(err)
+	}
+
+	s.logger.Info("Администратор удалил роль", zap.String("admin", actor), zap.String("role", role))
+	return nil
+}
+
+func mapRoleError(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return ErrRoleNotFound
+	case errors.Is(err, repositories.ErrConflict):
+		return ErrRoleExists
+	case errors.Is(err, repositories.ErrForeignKey):
+		return ErrUnknownPermission
+	}
+	return err
+}
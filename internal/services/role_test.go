@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"rcoi/internal/denylist"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+type fakeRoleRepo struct {
+	repositories.RoleRepository
+	permissions map[string][]string
+}
+
+func (f *fakeRoleRepo) SetPermissions(ctx context.Context, role string, permissions []string) error {
+	f.permissions[role] = permissions
+	return nil
+}
+
+func (f *fakeRoleRepo) GetPermissions(ctx context.Context, role string) ([]string, error) {
+	return f.permissions[role], nil
+}
+
+type fakeSessionRepo struct {
+	repositories.SessionRepository
+	jtisByRole map[string][]string
+}
+
+func (f *fakeSessionRepo) GetAccessJTIsByRole(ctx context.Context, role string) ([]string, error) {
+	return f.jtisByRole[role], nil
+}
+
+func TestSetPermissionsRevokesRoleTokens(t *testing.T) {
+	ctx := context.Background()
+	roles := &fakeRoleRepo{permissions: make(map[string][]string)}
+	sessions := &fakeSessionRepo{jtisByRole: map[string][]string{
+		"editor": {"editor-1", "editor-2"},
+		"user":   {"user-1"},
+	}}
+	revoked := denylist.NewMemoryStore(time.Minute)
+
+	s := NewRoleService(roles, sessions, revoked, zap.NewNop())
+	if err := s.SetPermissions(ctx, "admin@example.com", "admin", "editor", []string{"news:write"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for jti, want := range map[string]bool{"editor-1": true, "editor-2": true, "user-1": false} {
+		denied, err := revoked.Contains(ctx, jti)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if denied != want {
+			t.Errorf("токен %s отозван = %v, want %v", jti, denied, want)
+		}
+	}
+}
+
+func TestSetPermissionsLockout(t *testing.T) {
+	full := []string{models.PermRolesManage, models.PermUsersManage, models.PermNewsWrite}
+
+	tests := []struct {
+		name        string
+		actorRole   string
+		role        string
+		permissions []string
+		wantErr     error
+	}{
+		{"снять roles:manage с admin", "admin", "admin", []string{models.PermUsersManage}, ErrRoleLockout},
+		{"снять users:manage с admin другой ролью", "staff", "admin", []string{models.PermRolesManage}, ErrRoleLockout},
+		{"снять roles:manage со своей роли", "staff", "staff", []string{models.PermUsersManage}, ErrRoleLockout},
+		{"сузить admin без управляющих прав", "admin", "admin", []string{models.PermRolesManage, models.PermUsersManage}, nil},
+		{"снять права с чужой роли", "admin", "staff", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles := &fakeRoleRepo{permissions: map[string][]string{"admin": full, "staff": full}}
+			s := NewRoleService(roles, &fakeSessionRepo{}, denylist.NewMemoryStore(time.Minute), zap.NewNop())
+
+			err := s.SetPermissions(context.Background(), "admin@example.com", tt.actorRole, tt.role, tt.permissions)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && len(roles.permissions[tt.role]) != len(full) {
+				t.Fatalf("права роли %s изменены несмотря на ошибку", tt.role)
+			}
+		})
+	}
+}
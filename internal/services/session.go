@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rcoi/internal/denylist"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+var ErrSessionNotFound = errors.New("сессия не найдена")
+
+type SessionService interface {
+	GetUserSessions(ctx context.Context, userID int, currentID string) ([]*models.Session, error)
+	RevokeOwnSession(ctx context.Context, userID int, sessionID string) error
+	RevokeSession(ctx context.Context, actor, sessionID string) error
+}
+
+type sessionService struct {
+	repo    repositories.SessionRepository
+	revoked denylist.Store
+	logger  *zap.Logger
+}
+
+func NewSessionService(repo repositories.SessionRepository, revoked denylist.Store, logger *zap.Logger) SessionService {
+	return &sessionService{repo: repo, revoked: revoked, logger: logger}
+}
+
+// GetUserSessions возвращает действующие сессии пользователя, отмечая текущую
+func (s *sessionService) GetUserSessions(ctx context.Context, userID int, currentID string) ([]*models.Session, error) {
+	sessions, err := s.repo.GetActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == currentID
+	}
+	return sessions, nil
+}
+
+// RevokeOwnSession отзывает сессию, только если она принадлежит пользователю
+func (s *sessionService) RevokeOwnSession(ctx context.Context, userID int, sessionID string) error {
+	if uuid.Validate(sessionID) != nil {
+		return ErrSessionNotFound
+	}
+
+	accessJTI, err := s.repo.RevokeForUser(ctx, userID, sessionID)
+	if err != nil {
+		return mapSessionError(err)
+	}
+	if err := denyAccessTokens(ctx, s.revoked, accessJTI); err != nil {
+		return err
+	}
+
+	s.logger.Info("Пользователь завершил сессию", zap.Int("user_id", userID), zap.String("session_id", sessionID))
+	return nil
+}
+
+// RevokeSession отзывает любую сессию; actor — email администратора
+func (s *sessionService) RevokeSession(ctx context.Context, actor, sessionID string) error {
+	if uuid.Validate(sessionID) != nil {
+		return ErrSessionNotFound
+	}
+
+	accessJTI, err := s.repo.Revoke(ctx, sessionID)
+	if err != nil {
+		return mapSessionError(err)
+	}
+	if err := denyAccessTokens(ctx, s.revoked, accessJTI); err != nil {
+		return err
+	}
+
+	s.logger.Info("Администратор завершил сессию", zap.String("admin", actor), zap.String("session_id", sessionID))
+	return nil
+}
+
+// denyAccessTokens вносит access-токены отозванных сессий в denylist,
+// чтобы они перестали приниматься ещё до истечения срока действия
+func denyAccessTokens(ctx context.Context, revoked denylist.Store, jtis ...string) error {
+	for _, jti := range jtis {
+		if jti == "" {
+			continue
+		}
+		if err := revoked.Add(ctx, jti); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mapSessionError(err error) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrSessionNotFound
+	}
+	return err
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"rcoi/config"
+	"rcoi/internal/jwtkeys"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+// SSOStateTTL — сколько у пользователя есть времени на вход у провайдера
+const SSOStateTTL = 10 * time.Minute
+
+// ssoActor — от имени кого в журнале записываются изменения роли по группам провайдера
+const ssoActor = "oidc"
+
+var (
+	ErrInvalidSSOState       = errors.New("состояние входа через SSO недействительно или истекло")
+	ErrSSOEmailNotVerified   = errors.New("провайдер не подтвердил email пользователя")
+	ErrSSOUserNotProvisioned = errors.New("учётная запись для этого пользователя не создана")
+)
+
+// SSOService — вход через OpenID Connect (authorization code + PKCE)
+type SSOService interface {
+	// Begin возвращает адрес провайдера для перенаправления браузера и подписанное состояние для cookie
+	Begin(ctx context.Context) (authURL, stateToken string, err error)
+	// Complete обменивает код провайдера на пользователя rcoi и выполняет вход
+	Complete(ctx context.Context, stateToken, state, code string, meta SessionMeta) (*LoginResult, error)
+}
+
+type ssoService struct {
+	cfg        config.OIDCConfig
+	users      repositories.UserRepository
+	identities repositories.IdentityRepository
+	userAdmin  UserService
+	auth       AuthService
+	keys       *jwtkeys.KeySet
+	logger     *zap.Logger
+
+	// Провайдер загружается при первом входе, чтобы недоступность IdP не мешала запуску сервиса
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// ssoStateClaims — state, nonce и PKCE verifier одной попытки входа
+type ssoStateClaims struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	TokenUse string `json:"token_use"`
+	jwt.RegisteredClaims
+}
+
+// ssoClaims — нужные rcoi данные из ID-токена
+type ssoClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+func NewSSOService(cfg config.OIDCConfig, users repositories.UserRepository, identities repositories.IdentityRepository, userAdmin UserService, auth AuthService, keys *jwtkeys.KeySet, logger *zap.Logger) SSOService {
+	return &ssoService{cfg: cfg, users: users, identities: identities, userAdmin: userAdmin, auth: auth, keys: keys, logger: logger}
+}
+
+func (s *ssoService) Begin(ctx context.Context) (string, string, error) {
+	oauthCfg, _, err := s.client(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, _, err := newOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, _, err := newOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	now := time.Now()
+	stateToken, err := s.keys.Sign(ssoStateClaims{
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+		TokenUse: jwtkeys.UseOIDCState,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.keys.Issuer(),
+			Audience:  jwt.ClaimStrings{s.keys.Audience(jwtkeys.UseOIDCState)},
+			ExpiresAt: jwt.NewNumericDate(now.Add(SSOStateTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL := oauthCfg.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	return authURL, stateToken, nil
+}
+
+func (s *ssoService) Complete(ctx context.Context, stateToken, state, code string, meta SessionMeta) (*LoginResult, error) {
+	claims, err := s.keys.Parse(stateToken, jwtkeys.UseOIDCState)
+	if err != nil {
+		return nil, ErrInvalidSSOState
+	}
+	expectedState, _ := claims["state"].(string)
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+	if expectedState == "" || subtle.ConstantTimeCompare([]byte(expectedState), []byte(state)) != 1 {
+		return nil, ErrInvalidSSOState
+	}
+
+	oauthCfg, idVerifier, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauthCfg.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("обмен кода авторизации: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("провайдер не вернул ID-токен")
+	}
+	idToken, err := idVerifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("проверка ID-токена: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(nonce)) != 1 {
+		return nil, ErrInvalidSSOState
+	}
+
+	var profile ssoClaims
+	if err := idToken.Claims(&profile); err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := idToken.Claims(&raw); err != nil {
+		return nil, err
+	}
+	groups := claimStrings(raw[s.cfg.GroupsClaim])
+
+	user, err := s.resolveUser(ctx, idToken.Issuer, idToken.Subject, profile, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.syncRole(ctx, user, groups); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Вход через SSO", zap.Int("user_id", user.ID), zap.String("subject", idToken.Subject), zap.String("ip", meta.IP))
+	return s.auth.LoginExternal(ctx, user, meta)
+}
+
+// resolveUser находит пользователя по привязанной учётной записи провайдера,
+// привязывает существующего по подтверждённому email или создаёт нового
+func (s *ssoService) resolveUser(ctx context.Context, issuer, subject string, profile ssoClaims, groups []string) (*models.User, error) {
+	email := strings.TrimSpace(profile.Email)
+
+	userID, err := s.identities.Lookup(ctx, issuer, subject, email)
+	if err == nil {
+		user, err := s.users.GetByID(ctx, userID)
+		if err != nil {
+			return nil, mapUserError(err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+
+	// Привязка по email допустима только если провайдер подтвердил владение адресом
+	if email == "" || !profile.EmailVerified {
+		return nil, ErrSSOEmailNotVerified
+	}
+
+	user, err := s.users.GetUserByEmail(ctx, email)
+	switch {
+	case err == nil:
+		if !user.EmailVerified {
+			if err := s.users.SetEmailVerified(ctx, user.ID); err != nil {
+				return nil, err
+			}
+			user.EmailVerified = true
+		}
+		s.logger.Info("Учётная запись провайдера привязана к пользователю", zap.Int("user_id", user.ID), zap.String("subject", subject))
+	case errors.Is(err, repositories.ErrNotFound):
+		if !s.cfg.AutoProvision {
+			return nil, ErrSSOUserNotProvisioned
+		}
+		if user, err = s.provision(ctx, email, groups); err != nil {
+			return nil, err
+		}
+		s.logger.Info("Создан пользователь по входу через SSO", zap.Int("user_id", user.ID), zap.String("subject", subject))
+	default:
+		return nil, err
+	}
+
+	if err := s.identities.Link(ctx, issuer, subject, user.ID, email); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// provision создаёт пользователя без пароля: войти он может только через SSO или после сброса пароля
+func (s *ssoService) provision(ctx context.Context, email string, groups []string) (*models.User, error) {
+	unusable, _, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := hashPassword(unusable)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Email:         email,
+		Password:      hashedPassword,
+		Role:          s.mappedRole(groups),
+		IsActive:      true,
+		EmailVerified: true,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, mapUserError(err)
+	}
+	return user, nil
+}
+
+// syncRole приводит роль пользователя в соответствие с группами провайдера, если настроено сопоставление
+func (s *ssoService) syncRole(ctx context.Context, user *models.User, groups []string) error {
+	if len(s.cfg.RoleMapping) == 0 {
+		return nil
+	}
+
+	role := s.mappedRole(groups)
+	if role == user.Role {
+		return nil
+	}
+
+	if err := s.userAdmin.ChangeRole(ctx, ssoActor, user.ID, role); err != nil {
+		return err
+	}
+	user.Role = role
+	return nil
+}
+
+// mappedRole возвращает роль первой подходящей группы из RoleMapping или DefaultRole
+func (s *ssoService) mappedRole(groups []string) string {
+	for _, m := range s.cfg.RoleMapping {
+		if slices.Contains(groups, m.Group) {
+			return m.Role
+		}
+	}
+	return s.cfg.DefaultRole
+}
+
+// client возвращает настройки OAuth2 и проверку ID-токенов, загружая discovery-документ провайдера
+func (s *ssoService) client(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider == nil {
+		// Провайдер использует контекст и для последующей загрузки ключей, поэтому он не должен отменяться вместе с запросом
+		provider, err := oidc.NewProvider(context.WithoutCancel(ctx), s.cfg.IssuerURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("загрузка настроек OIDC-провайдера: %w", err)
+		}
+		s.provider = provider
+	}
+
+	scopes := s.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	} else if !slices.Contains(scopes, oidc.ScopeOpenID) {
+		scopes = append([]string{oidc.ScopeOpenID}, scopes...)
+	}
+
+	oauthCfg := &oauth2.Config{
+		ClientID:     s.cfg.ClientID,
+		ClientSecret: s.cfg.ClientSecret,
+		RedirectURL:  s.cfg.RedirectURL,
+		Endpoint:     s.provider.Endpoint(),
+		Scopes:       scopes,
+	}
+	return oauthCfg, s.provider.Verifier(&oidc.Config{ClientID: s.cfg.ClientID}), nil
+}
+
+// claimStrings читает claim со списком групп: массив строк или одна строка
+func claimStrings(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var values []string
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,477 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"rcoi/config"
+	"rcoi/internal/jwtkeys"
+	"rcoi/internal/models"
+	"rcoi/internal/repositories"
+)
+
+const testClientID = "rcoi-test"
+
+// mockOIDCProvider — локальный OpenID Connect провайдер: discovery, JWKS и token endpoint.
+// Код авторизации выдаётся тестом через authorize, token endpoint проверяет PKCE verifier.
+type mockOIDCProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]mockGrant
+}
+
+type mockGrant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &mockOIDCProvider{t: t, key: key, grants: make(map[string]mockGrant)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.jwks)
+	mux.HandleFunc("/token", p.token)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *mockOIDCProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                p.server.URL,
+		"authorization_endpoint":                p.server.URL + "/authorize",
+		"token_endpoint":                        p.server.URL + "/token",
+		"jwks_uri":                              p.server.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (p *mockOIDCProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "test",
+		"alg": "RS256",
+		"use": "sig",
+		"n":   b64(p.key.N.Bytes()),
+		"e":   b64(big.NewInt(int64(p.key.E)).Bytes()),
+	}}})
+}
+
+func (p *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	grant, ok := p.grants[r.PostForm.Get("code")]
+	delete(p.grants, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, grant.claims)
+	token.Header["kid"] = "test"
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		p.t.Error(err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "provider-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// authorize имитирует вход пользователя у провайдера: запоминает PKCE challenge и nonce из authURL
+// и выдаёт код, по которому token endpoint вернёт ID-токен с claims. Возвращает state из authURL.
+func (p *mockOIDCProvider) authorize(authURL, code string, claims jwt.MapClaims) string {
+	p.t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		p.t.Fatalf("в authURL нет PKCE S256: %s", authURL)
+	}
+
+	now := time.Now()
+	idClaims := jwt.MapClaims{
+		"iss":   p.server.URL,
+		"aud":   testClientID,
+		"sub":   "subject-1",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": query.Get("nonce"),
+	}
+	for k, v := range claims {
+		idClaims[k] = v
+	}
+
+	p.mu.Lock()
+	p.grants[code] = mockGrant{challenge: query.Get("code_challenge"), claims: idClaims}
+	p.mu.Unlock()
+	return query.Get("state")
+}
+
+type fakeUserRepo struct {
+	repositories.UserRepository
+	byID     map[int]*models.User
+	verified []int
+}
+
+func (r *fakeUserRepo) GetByID(_ context.Context, id int) (*models.User, error) {
+	if u, ok := r.byID[id]; ok {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r *fakeUserRepo) GetUserByEmail(_ context.Context, email string) (*models.User, error) {
+	for _, u := range r.byID {
+		if strings.EqualFold(u.Email, email) {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r *fakeUserRepo) Create(_ context.Context, user *models.User) error {
+	user.ID = len(r.byID) + 100
+	copied := *user
+	r.byID[user.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepo) SetEmailVerified(_ context.Context, id int) error {
+	r.verified = append(r.verified, id)
+	r.byID[id].EmailVerified = true
+	return nil
+}
+
+type fakeIdentityRepo struct {
+	links map[string]int
+}
+
+func (r *fakeIdentityRepo) Lookup(_ context.Context, issuer, subject, _ string) (int, error) {
+	if id, ok := r.links[issuer+"|"+subject]; ok {
+		return id, nil
+	}
+	return 0, repositories.ErrNotFound
+}
+
+func (r *fakeIdentityRepo) Link(_ context.Context, issuer, subject string, userID int, _ string) error {
+	r.links[issuer+"|"+subject] = userID
+	return nil
+}
+
+type roleChange struct {
+	actor string
+	id    int
+	role  string
+}
+
+type fakeUserAdmin struct {
+	UserService
+	changes []roleChange
+}
+
+func (s *fakeUserAdmin) ChangeRole(_ context.Context, actor string, id int, role string) error {
+	s.changes = append(s.changes, roleChange{actor, id, role})
+	return nil
+}
+
+type fakeAuth struct {
+	AuthService
+	loggedIn []*models.User
+}
+
+func (s *fakeAuth) LoginExternal(_ context.Context, user *models.User, _ SessionMeta) (*LoginResult, error) {
+	s.loggedIn = append(s.loggedIn, user)
+	return &LoginResult{AccessToken: "access", RefreshToken: "refresh"}, nil
+}
+
+type ssoFixture struct {
+	provider   *mockOIDCProvider
+	service    SSOService
+	users      *fakeUserRepo
+	identities *fakeIdentityRepo
+	userAdmin  *fakeUserAdmin
+	auth       *fakeAuth
+}
+
+func newSSOFixture(t *testing.T, configure func(cfg *config.OIDCConfig)) *ssoFixture {
+	t.Helper()
+	provider := newMockOIDCProvider(t)
+	keys, err := jwtkeys.Load(config.AuthConfig{JWTSecret: "test-secret", TokenIssuer: "rcoi", TokenAudience: "rcoi-api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.OIDCConfig{
+		IssuerURL:     provider.server.URL,
+		ClientID:      testClientID,
+		ClientSecret:  "secret",
+		RedirectURL:   "http://localhost:8080/oidc/callback",
+		GroupsClaim:   "groups",
+		DefaultRole:   "user",
+		AutoProvision: true,
+	}
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	f := &ssoFixture{
+		provider:   provider,
+		users:      &fakeUserRepo{byID: make(map[int]*models.User)},
+		identities: &fakeIdentityRepo{links: make(map[string]int)},
+		userAdmin:  &fakeUserAdmin{},
+		auth:       &fakeAuth{},
+	}
+	f.service = NewSSOService(cfg, f.users, f.identities, f.userAdmin, f.auth, keys, zap.NewNop())
+	return f
+}
+
+// login проходит вход целиком: Begin, вход у провайдера с claims и Complete
+func (f *ssoFixture) login(t *testing.T, claims jwt.MapClaims) (*LoginResult, error) {
+	t.Helper()
+	authURL, stateToken, err := f.service.Begin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := f.provider.authorize(authURL, "code-1", claims)
+	return f.service.Complete(context.Background(), stateToken, state, "code-1", SessionMeta{IP: "127.0.0.1"})
+}
+
+func verifiedEmail(email string, extra jwt.MapClaims) jwt.MapClaims {
+	claims := jwt.MapClaims{"email": email, "email_verified": true}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	return claims
+}
+
+func TestSSOStateMismatch(t *testing.T) {
+	f := newSSOFixture(t, nil)
+	authURL, stateToken, err := f.service.Begin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.provider.authorize(authURL, "code-1", verifiedEmail("a@example.com", nil))
+
+	_, err = f.service.Complete(context.Background(), stateToken, "forged-state", "code-1", SessionMeta{})
+	if !errors.Is(err, ErrInvalidSSOState) {
+		t.Fatalf("Complete() = %v, want ErrInvalidSSOState", err)
+	}
+
+	_, err = f.service.Complete(context.Background(), "not-a-token", "forged-state", "code-1", SessionMeta{})
+	if !errors.Is(err, ErrInvalidSSOState) {
+		t.Fatalf("Complete() с подделанной cookie = %v, want ErrInvalidSSOState", err)
+	}
+}
+
+func TestSSONonceMismatch(t *testing.T) {
+	f := newSSOFixture(t, nil)
+	_, err := f.login(t, verifiedEmail("a@example.com", jwt.MapClaims{"nonce": "other-nonce"}))
+	if !errors.Is(err, ErrInvalidSSOState) {
+		t.Fatalf("Complete() = %v, want ErrInvalidSSOState", err)
+	}
+	if len(f.auth.loggedIn) != 0 {
+		t.Fatal("вход выполнен при неверном nonce")
+	}
+}
+
+func TestSSOPKCEVerifier(t *testing.T) {
+	f := newSSOFixture(t, nil)
+	if _, err := f.login(t, verifiedEmail("a@example.com", nil)); err != nil {
+		t.Fatalf("вход с верным PKCE verifier: %v", err)
+	}
+
+	// Состояние другой попытки входа несёт другой verifier: провайдер должен отказать в обмене кода
+	authURL, _, err := f.service.Begin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherStateToken, err := f.service.Begin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.provider.authorize(authURL, "code-2", verifiedEmail("a@example.com", nil))
+	otherState := stateFromToken(t, otherStateToken)
+	if _, err := f.service.Complete(context.Background(), otherStateToken, otherState, "code-2", SessionMeta{}); err == nil {
+		t.Fatal("обмен кода с чужим PKCE verifier прошёл")
+	}
+}
+
+// stateFromToken возвращает state из cookie состояния, чтобы подставить его в callback
+func stateFromToken(t *testing.T, stateToken string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(stateToken, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, _ := parsed.Claims.(jwt.MapClaims)["state"].(string)
+	return state
+}
+
+func TestSSOEmailNotVerified(t *testing.T) {
+	f := newSSOFixture(t, nil)
+	f.users.byID[1] = &models.User{ID: 1, Email: "a@example.com", Role: "user", IsActive: true}
+
+	_, err := f.login(t, jwt.MapClaims{"email": "a@example.com", "email_verified": false})
+	if !errors.Is(err, ErrSSOEmailNotVerified) {
+		t.Fatalf("Complete() = %v, want ErrSSOEmailNotVerified", err)
+	}
+	if len(f.identities.links) != 0 {
+		t.Fatal("учётная запись привязана по неподтверждённому email")
+	}
+}
+
+func TestSSOLinkByEmail(t *testing.T) {
+	f := newSSOFixture(t, nil)
+	f.users.byID[1] = &models.User{ID: 1, Email: "a@example.com", Role: "user", IsActive: true}
+
+	if _, err := f.login(t, verifiedEmail("A@example.com", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := f.identities.links[f.provider.server.URL+"|subject-1"]; got != 1 {
+		t.Fatalf("учётная запись провайдера привязана к %d, want 1", got)
+	}
+	if len(f.users.verified) != 1 || f.users.verified[0] != 1 {
+		t.Fatalf("email пользователя не отмечен подтверждённым: %v", f.users.verified)
+	}
+	if len(f.auth.loggedIn) != 1 || f.auth.loggedIn[0].ID != 1 {
+		t.Fatal("вход выполнен не для привязанного пользователя")
+	}
+
+	// Повторный вход находит пользователя по привязке, даже если email у провайдера изменился
+	if _, err := f.login(t, verifiedEmail("new@example.com", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if f.auth.loggedIn[1].ID != 1 {
+		t.Fatalf("повторный вход для пользователя %d, want 1", f.auth.loggedIn[1].ID)
+	}
+}
+
+func TestSSOAutoProvision(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		f := newSSOFixture(t, func(cfg *config.OIDCConfig) {
+			cfg.RoleMapping = []config.GroupRole{{Group: "staff-editors", Role: "editor"}}
+		})
+
+		if _, err := f.login(t, verifiedEmail("new@example.com", jwt.MapClaims{"groups": []string{"staff-editors"}})); err != nil {
+			t.Fatal(err)
+		}
+
+		created, err := f.users.GetUserByEmail(context.Background(), "new@example.com")
+		if err != nil {
+			t.Fatal("пользователь не создан")
+		}
+		if created.Role != "editor" || !created.EmailVerified || !created.IsActive {
+			t.Fatalf("создан пользователь %+v", created)
+		}
+		if len(f.userAdmin.changes) != 0 {
+			t.Fatalf("роль нового пользователя изменена повторно: %v", f.userAdmin.changes)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		f := newSSOFixture(t, func(cfg *config.OIDCConfig) { cfg.AutoProvision = false })
+
+		_, err := f.login(t, verifiedEmail("new@example.com", nil))
+		if !errors.Is(err, ErrSSOUserNotProvisioned) {
+			t.Fatalf("Complete() = %v, want ErrSSOUserNotProvisioned", err)
+		}
+		if len(f.users.byID) != 0 {
+			t.Fatal("пользователь создан при выключенном AUTO_PROVISION")
+		}
+	})
+}
+
+func TestSSOSyncRole(t *testing.T) {
+	mapping := []config.GroupRole{
+		{Group: "staff-admins", Role: "admin"},
+		{Group: "staff-editors", Role: "editor"},
+	}
+
+	tests := []struct {
+		name     string
+		mapping  []config.GroupRole
+		current  string
+		groups   any
+		wantRole string // пусто — роль не меняется
+	}{
+		{"first matching group wins", mapping, "user", []string{"staff-editors", "staff-admins"}, "admin"},
+		{"single group as string", mapping, "user", "staff-editors", "editor"},
+		{"no groups falls back to default", mapping, "editor", nil, "user"},
+		{"role already matches", mapping, "editor", []string{"staff-editors"}, ""},
+		{"mapping not configured", nil, "admin", []string{"staff-editors"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSSOFixture(t, func(cfg *config.OIDCConfig) { cfg.RoleMapping = tt.mapping })
+			f.users.byID[1] = &models.User{ID: 1, Email: "a@example.com", Role: tt.current, IsActive: true, EmailVerified: true}
+
+			claims := verifiedEmail("a@example.com", nil)
+			if tt.groups != nil {
+				claims["groups"] = tt.groups
+			}
+			if _, err := f.login(t, claims); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantRole == "" {
+				if len(f.userAdmin.changes) != 0 {
+					t.Fatalf("роль изменена: %v", f.userAdmin.changes)
+				}
+				return
+			}
+			want := roleChange{actor: ssoActor, id: 1, role: tt.wantRole}
+			if len(f.userAdmin.changes) != 1 || f.userAdmin.changes[0] != want {
+				t.Fatalf("изменения роли = %v, want %v", f.userAdmin.changes, want)
+			}
+			if f.auth.loggedIn[0].Role != tt.wantRole {
+				t.Fatalf("вход с ролью %q, want %q", f.auth.loggedIn[0].Role, tt.wantRole)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+	"rcoi/internal/denylist"
+	"rcoi/internal/loginguard"
+	"rcoi/internal/models"
+	"rcoi/internal/passwordpolicy"
+	"rcoi/internal/repositories"
+)
+
+var (
+	ErrUserNotFound   = errors.New("пользователь не найден")
+	ErrUserExists     = errors.New("email уже используется")
+	ErrInvalidRole    = errors.New("неизвестная роль")
+	ErrSelfModeration = errors.New("нельзя выполнить это действие над своей учётной записью")
+)
+
+// UserService — управление пользователями администраторами.
+// actor — email администратора, выполняющего действие; используется в журнале.
+type UserService interface {
+	GetAllUsers(ctx context.Context, q models.ListQuery) (*models.Page[*models.User], error)
+	GetUserByID(ctx context.Context, id int) (*models.User, error)
+	CreateUser(ctx context.Context, actor, email, password, role string) (*models.User, error)
+	ChangeRole(ctx context.Context, actor string, id int, role string) error
+	SetActive(ctx context.Context, actor string, id int, active bool) error
+	ForceLogout(ctx context.Context, actor string, id int) error
+	Unlock(ctx context.Context, actor string, id int) error
+	DeleteUser(ctx context.Context, actor string, id int) error
+}
+
+type userService struct {
+	repo     repositories.UserRepository
+	roles    repositories.RoleRepository
+	sessions repositories.SessionRepository
+	revoked  denylist.Store
+	guard    *loginguard.Guard
+	policy   *passwordpolicy.Policy
+	logger   *zap.Logger
+}
+
+func NewUserService(repo repositories.UserRepository, roles repositories.RoleRepository, sessions repositories.SessionRepository, revoked denylist.Store, guard *loginguard.Guard, policy *passwordpolicy.Policy, logger *zap.Logger) UserService {
+	return &userService{repo: repo, roles: roles, sessions: sessions, revoked: revoked, guard: guard, policy: policy, logger: logger}
+}
+
+func (s *userService) GetAllUsers(ctx context.Context, q models.ListQuery) (*models.Page[*models.User], error) {
+	return s.repo.GetAll(ctx, q)
+}
+
+func (s *userService) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+	return user, nil
+}
+
+func (s *userService) CreateUser(ctx context.Context, actor, email, password, role string) (*models.User, error) {
+	if !validEmail(email) {
+		return nil, ErrInvalidEmail
+	}
+	if role == "" {
+		role = "user"
+	}
+	if err := s.checkRole(ctx, role); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := newPasswordHash(s.policy, password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Адрес, заведённый администратором, считается подтверждённым
+	user := &models.User{Email: email, Password: hashedPassword, Role: role, IsActive: true, EmailVerified: true}
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, mapUserError(err)
+	}
+
+	s.logger.Info("Администратор создал пользователя",
+		zap.String("admin", actor), zap.Int("user_id", user.ID), zap.String("email", email), zap.String("role", role))
+	return user, nil
+}
+
+func (s *userService) ChangeRole(ctx context.Context, actor string, id int, role string) error {
+	if err := s.checkRole(ctx, role); err != nil {
+		return err
+	}
+
+	user, err := s.targetUser(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateRole(ctx, id, role); err != nil {
+		return mapUserError(err)
+	}
+
+	// Права зашиты в access-токены: отзываем их, чтобы клиент получил новые через /refresh
+	accessJTIs, err := s.sessions.GetAccessJTIs(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := denyAccessTokens(ctx, s.revoked, accessJTIs...); err != nil {
+		return err
+	}
+
+	s.logger.Info("Администратор изменил роль пользователя",
+		zap.String("admin", actor), zap.Int("user_id", id), zap.String("old_role", user.Role), zap.String("role", role))
+	return nil
+}
+
+func (s *userService) SetActive(ctx context.Context, actor string, id int, active bool) error {
+	if _, err := s.targetUser(ctx, actor, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.SetActive(ctx, id, active); err != nil {
+		return mapUserError(err)
+	}
+
+	// Отключённый пользователь не должен продлевать сессии
+	if !active {
+		if err := s.revokeSessions(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("Администратор изменил статус пользователя",
+		zap.String("admin", actor), zap.Int("user_id", id), zap.Bool("is_active", active))
+	return nil
+}
+
+func (s *userService) ForceLogout(ctx context.Context, actor string, id int) error {
+	if _, err := s.GetUserByID(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.revokeSessions(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.Info("Администратор завершил сессии пользователя",
+		zap.String("admin", actor), zap.Int("user_id", id))
+	return nil
+}
+
+// Unlock снимает блокировку входа после неудачных попыток
+func (s *userService) Unlock(ctx context.Context, actor string, id int) error {
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.guard.Unlock(ctx, user.Email); err != nil {
+		return err
+	}
+
+	s.logger.Info("Администратор снял блокировку входа",
+		zap.String("admin", actor), zap.Int("user_id", id), zap.String("email", user.Email))
+	return nil
+}
+
+func (s *userService) DeleteUser(ctx context.Context, actor string, id int) error {
+	if _, err := s.targetUser(ctx, actor, id); err != nil {
+		return err
+	}
+
+	// Сессии удаляются каскадно вместе с пользователем, поэтому jti собираем заранее
+	accessJTIs, err := s.sessions.GetAccessJTIs(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return mapUserError(err)
+	}
+
+	if err := denyAccessTokens(ctx, s.revoked, accessJTIs...); err != nil {
+		return err
+	}
+
+	s.logger.Info("Администратор удалил пользователя",
+		zap.String("admin", actor), zap.Int("user_id", id))
+	return nil
+}
+
+// revokeSessions завершает все сессии пользователя вместе с их access-токенами
+func (s *userService) revokeSessions(ctx context.Context, id int) error {
+	accessJTIs, err := s.sessions.RevokeAllForUser(ctx, id, "")
+	if err != nil {
+		return err
+	}
+	return denyAccessTokens(ctx, s.revoked, accessJTIs...)
+}
+
+// checkRole проверяет, что роль существует
+func (s *userService) checkRole(ctx context.Context, role string) error {
+	if _, err := s.roles.GetByName(ctx, role); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrInvalidRole
+		}
+		return err
+	}
+	return nil
+}
+
+// targetUser загружает пользователя и запрещает администратору менять самого себя
+func (s *userService) targetUser(ctx context.Context, actor string, id int) (*models.User, error) {
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(user.Email, actor) {
+		return nil, ErrSelfModeration
+	}
+	return user, nil
+}
+
+func mapUserError(err error) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrUserNotFound
+	}
+	if errors.Is(err, repositories.ErrConflict) {
+		return ErrUserExists
+	}
+	return err
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+	"rcoi/internal/models"
+)
+
+func TestCreateUserRejectsInvalidEmail(t *testing.T) {
+	users := &fakeUserRepo{byID: make(map[int]*models.User)}
+	s := NewUserService(users, nil, nil, nil, nil, nil, zap.NewNop())
+
+	for _, email := range []string{"not-an-email", "Иван <ivan@example.com>", " ivan@example.com", "ivan@"} {
+		_, err := s.CreateUser(context.Background(), "admin@example.com", email, "Secret-password-1", "user")
+		if !errors.Is(err, ErrInvalidEmail) {
+			t.Errorf("CreateUser(%q) error = %v, want ErrInvalidEmail", email, err)
+		}
+	}
+	if len(users.byID) != 0 {
+		t.Fatalf("создано пользователей: %d", len(users.byID))
+	}
+}
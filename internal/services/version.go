@@ -0,0 +1,18 @@
+package services
+
+import (
+	"errors"
+
+	"rcoi/internal/repositories"
+)
+
+// ErrVersionMismatch — запись изменили после того, как клиент её получил (If-Match не совпал)
+var ErrVersionMismatch = errors.New("запись уже изменена другим пользователем, загрузите её заново")
+
+// mapVersionError переводит конфликт версий репозитория в ошибку сервиса
+func mapVersionError(err error) error {
+	if errors.Is(err, repositories.ErrVersionMismatch) {
+		return ErrVersionMismatch
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+// Package slug строит человекочитаемые идентификаторы для адресов страниц
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxLength — максимальная длина slug в символах
+const MaxLength = 100
+
+// translit — транслитерация русских букв латиницей
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// Make переводит строку в slug: латиница в нижнем регистре, цифры и дефисы между словами.
+// Если в строке нет ни букв, ни цифр, возвращается fallback.
+func Make(s, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		var part string
+		switch {
+		case r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			part = string(r)
+		case translit[r] != "":
+			part = translit[r]
+		case r == 'ъ' || r == 'ь':
+			continue
+		default:
+			dash = b.Len() > 0
+			continue
+		}
+
+		if b.Len()+len(part)+1 > MaxLength {
+			break
+		}
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		b.WriteString(part)
+	}
+
+	if b.Len() == 0 {
+		return fallback
+	}
+	return b.String()
+}
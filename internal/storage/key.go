@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// UniqueKey возвращает ключ нового объекта: случайный идентификатор и исходное имя файла в конце.
+// Реплики пишут в одно хранилище, поэтому ключ не должен зависеть от времени или имени:
+// иначе одновременные загрузки одноимённых файлов перезаписали бы друг друга.
+func UniqueKey(name string) string {
+	return uuid.NewString() + "_" + filepath.Base(name)
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type localBackend struct {
+	root string
+}
+
+// NewLocalBackend создаёт хранилище в каталоге root. Путь приводится к
+// абсолютному при запуске, поэтому смена рабочего каталога ему не мешает.
+func NewLocalBackend(root string) (Backend, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return nil, err
+	}
+	return &localBackend{root: abs}, nil
+}
+
+func (b *localBackend) path(key string) (string, string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	return cleaned, filepath.Join(b.root, filepath.FromSlash(cleaned)), nil
+}
+
+func (b *localBackend) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, fullPath, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return err
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы читатели не видели недописанный файл
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), fullPath)
+}
+
+func (b *localBackend) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	cleaned, fullPath, err := b.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return nil, nil, mapLocalError(err)
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	return f, localInfo(cleaned, stat), nil
+}
+
+func (b *localBackend) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	cleaned, fullPath, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, mapLocalError(err)
+	}
+
+	return localInfo(cleaned, stat), nil
+}
+
+func (b *localBackend) Delete(ctx context.Context, key string) error {
+	_, fullPath, err := b.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (b *localBackend) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+
+	err := filepath.WalkDir(b.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(b.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		stat, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, *localInfo(key, stat))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+func localInfo(key string, stat fs.FileInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Key:         key,
+		Size:        stat.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+		ModTime:     stat.ModTime(),
+	}
+}
+
+func mapLocalError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
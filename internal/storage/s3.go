@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"rcoi/config"
+)
+
+type s3Backend struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Backend создаёт хранилище в S3-совместимом сервисе (AWS S3, MinIO и т.п.)
+func NewS3Backend(ctx context.Context, cfg config.StorageConfig) (Backend, error) {
+	if cfg.S3Endpoint == "" || cfg.S3Bucket == "" {
+		return nil, errors.New("для драйвера s3 необходимо указать S3_ENDPOINT и S3_BUCKET")
+	}
+
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure: cfg.S3UseSSL,
+		Region: cfg.S3Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания клиента S3: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.S3Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки бакета %s: %w", cfg.S3Bucket, err)
+	}
+	if !exists {
+		if !cfg.S3CreateBucket {
+			return nil, fmt.Errorf("бакет %s не существует", cfg.S3Bucket)
+		}
+		if err := client.MakeBucket(ctx, cfg.S3Bucket, minio.MakeBucketOptions{Region: cfg.S3Region}); err != nil {
+			return nil, fmt.Errorf("ошибка создания бакета %s: %w", cfg.S3Bucket, err)
+		}
+	}
+
+	return &s3Backend{client: client, bucket: cfg.S3Bucket}, nil
+}
+
+func (b *s3Backend) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.client.PutObject(ctx, b.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (b *s3Backend) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	obj, err := b.client.GetObject(ctx, b.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, mapS3Error(err)
+	}
+
+	// GetObject ленивый: ошибка отсутствия объекта появляется только при Stat/Read
+	stat, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, nil, mapS3Error(err)
+	}
+
+	return obj, s3Info(stat), nil
+}
+
+func (b *s3Backend) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := b.client.StatObject(ctx, b.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, mapS3Error(err)
+	}
+
+	return s3Info(stat), nil
+}
+
+func (b *s3Backend) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	return b.client.RemoveObject(ctx, b.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (b *s3Backend) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+
+	for obj := range b.client.ListObjects(ctx, b.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		objects = append(objects, *s3Info(obj))
+	}
+
+	return objects, nil
+}
+
+func s3Info(obj minio.ObjectInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Key:         obj.Key,
+		Size:        obj.Size,
+		ContentType: obj.ContentType,
+		ModTime:     obj.LastModified,
+	}
+}
+
+func mapS3Error(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
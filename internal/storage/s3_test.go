@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"rcoi/config"
+)
+
+// fakeS3 — минимальный S3-совместимый сервер в памяти (path-style), заменяющий MinIO в тестах
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string]fakeObject
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+}
+
+type fakeListResult struct {
+	XMLName  xml.Name `xml:"ListBucketResult"`
+	Name     string   `xml:"Name"`
+	Prefix   string   `xml:"Prefix"`
+	KeyCount int      `xml:"KeyCount"`
+	Contents []struct {
+		Key          string `xml:"Key"`
+		Size         int64  `xml:"Size"`
+		LastModified string `xml:"LastModified"`
+		ETag         string `xml:"ETag"`
+	} `xml:"Contents"`
+}
+
+func newFakeS3(t *testing.T) *httptest.Server {
+	t.Helper()
+	fake := &fakeS3{buckets: make(map[string]map[string]fakeObject)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	bucket, exists := f.buckets[bucketName]
+
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case key == "" && r.Method == http.MethodPut:
+		f.buckets[bucketName] = make(map[string]fakeObject)
+	case !exists:
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w, bucketName, bucket, r.URL.Query().Get("prefix"))
+	case r.Method == http.MethodPut:
+		data, err := readS3Body(r)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		bucket[key] = fakeObject{data: data, contentType: r.Header.Get("Content-Type"), modTime: time.Now()}
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, ok := bucket[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.Header().Set("Last-Modified", obj.modTime.UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", `"etag"`)
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(bucket, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, name string, bucket map[string]fakeObject, prefix string) {
+	result := fakeListResult{Name: name, Prefix: prefix}
+	for key, obj := range bucket {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		result.Contents = append(result.Contents, struct {
+			Key          string `xml:"Key"`
+			Size         int64  `xml:"Size"`
+			LastModified string `xml:"LastModified"`
+			ETag         string `xml:"ETag"`
+		}{key, int64(len(obj.data)), obj.modTime.UTC().Format(time.RFC3339), `"etag"`})
+	}
+	result.KeyCount = len(result.Contents)
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+// readS3Body читает тело PUT; клиент может прислать его в формате aws-chunked
+func readS3Body(r *http.Request) ([]byte, error) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil || !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return data, err
+	}
+
+	var out []byte
+	rest := string(data)
+	for {
+		header, body, ok := strings.Cut(rest, "\r\n")
+		if !ok {
+			return out, nil
+		}
+		sizeHex, _, _ := strings.Cut(header, ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil || size == 0 {
+			return out, nil
+		}
+		out = append(out, body[:size]...)
+		rest = strings.TrimPrefix(body[size:], "\r\n")
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	io.WriteString(w, "<Error><Code>"+code+"</Code><Message>"+code+"</Message></Error>")
+}
+
+func newTestS3Backend(t *testing.T, createBucket bool) (Backend, error) {
+	t.Helper()
+	server := newFakeS3(t)
+	return NewS3Backend(context.Background(), config.StorageConfig{
+		Driver:         "s3",
+		S3Endpoint:     strings.TrimPrefix(server.URL, "http://"),
+		S3Region:       "us-east-1",
+		S3Bucket:       "rcoi",
+		S3AccessKey:    "minio",
+		S3SecretKey:    "minio-secret",
+		S3UseSSL:       false,
+		S3CreateBucket: createBucket,
+	})
+}
+
+func TestS3Backend(t *testing.T) {
+	b, err := newTestS3Backend(t, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBackendRoundTrip(t, b)
+}
+
+func TestS3BackendMissingBucket(t *testing.T) {
+	if _, err := newTestS3Backend(t, false); err == nil {
+		t.Fatal("создано хранилище для несуществующего бакета без S3_CREATE_BUCKET")
+	}
+}
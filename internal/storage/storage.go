@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"rcoi/config"
+)
+
+// ErrNotFound возвращается, если объект отсутствует в хранилище
+var ErrNotFound = errors.New("объект не найден в хранилище")
+
+// ObjectInfo содержит метаданные сохранённого объекта
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Backend — общее хранилище файлов документов и приложений.
+// Ключи имеют вид "dir/name" и не зависят от рабочего каталога процесса.
+type Backend interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// New создаёт хранилище в соответствии с конфигурацией
+func New(ctx context.Context, cfg config.StorageConfig) (Backend, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalBackend(cfg.LocalPath)
+	case "s3":
+		return NewS3Backend(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер хранилища: %s", cfg.Driver)
+	}
+}
+
+// cleanKey нормализует ключ и запрещает выход за пределы хранилища
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + strings.ReplaceAll(key, "\\", "/"))
+	cleaned = strings.TrimPrefix(cleaned, "/")
+	if cleaned == "" || cleaned == "." {
+		return "", fmt.Errorf("некорректный ключ объекта: %q", key)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// testBackendRoundTrip проверяет общий контракт Backend на любой реализации
+func testBackendRoundTrip(t *testing.T, b Backend) {
+	t.Helper()
+	ctx := context.Background()
+	content := []byte("содержимое документа")
+
+	if err := b.Put(ctx, "documents/report.pdf", bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	rc, info, err := b.Get(ctx, "documents/report.pdf")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatalf("чтение объекта: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatalf("Get вернул %q, want %q", got, content)
+	}
+	if info.Key != "documents/report.pdf" || info.Size != int64(len(content)) || info.ContentType != "application/pdf" {
+		t.Fatalf("ObjectInfo = %+v", info)
+	}
+
+	stat, err := b.Stat(ctx, "documents/report.pdf")
+	if err != nil || stat.Size != int64(len(content)) {
+		t.Fatalf("Stat = %+v, %v", stat, err)
+	}
+
+	objects, err := b.List(ctx, "documents/")
+	if err != nil || len(objects) != 1 || objects[0].Key != "documents/report.pdf" {
+		t.Fatalf("List = %+v, %v", objects, err)
+	}
+
+	if err := b.Delete(ctx, "documents/report.pdf"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, _, err := b.Get(ctx, "documents/report.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get после Delete = %v, want ErrNotFound", err)
+	}
+	if _, err := b.Stat(ctx, "documents/missing.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Stat отсутствующего объекта = %v, want ErrNotFound", err)
+	}
+	if err := b.Delete(ctx, "documents/missing.pdf"); err != nil {
+		t.Fatalf("Delete отсутствующего объекта: %v", err)
+	}
+}
+
+func TestLocalBackend(t *testing.T) {
+	b, err := NewLocalBackend(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBackendRoundTrip(t, b)
+}
+
+func TestLocalBackendKeepsKeysInsideRoot(t *testing.T) {
+	root := t.TempDir()
+	b, err := NewLocalBackend(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte("x")
+	if err := b.Put(context.Background(), "../../escape.txt", bytes.NewReader(content), 1, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Stat(context.Background(), "escape.txt"); err != nil {
+		t.Fatalf("ключ с .. сохранён вне хранилища: %v", err)
+	}
+	if _, err := b.Stat(context.Background(), ""); err == nil {
+		t.Fatal("пустой ключ принят")
+	}
+}
+
+func TestUniqueKey(t *testing.T) {
+	first, second := UniqueKey("../отчёт.pdf"), UniqueKey("../отчёт.pdf")
+	if first == second {
+		t.Fatalf("одинаковые ключи для одновременных загрузок: %q", first)
+	}
+	for _, key := range []string{first, second} {
+		if !strings.HasSuffix(key, "_отчёт.pdf") || strings.Contains(key, "..") {
+			t.Fatalf("ключ %q должен заканчиваться исходным именем без пути", key)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"rcoi/config"
+	"rcoi/internal/apperrors"
+)
+
+// Tag — тег поля DTO с правилами через запятую, например `validate:"required,max=255"`.
+//
+// Поддерживаются правила:
+//   - required — непустое значение (пробелы не считаются);
+//   - min=N, max=N — длина в символах;
+//   - url — абсолютная ссылка с разрешённой схемой и хостом;
+//   - oneof=a b c — одно из перечисленных значений.
+//
+// Правила применяются к полям string и *string; nil-указатель означает «поле не передано»
+// и проверяется только правилом required. Пустая строка проверяется только правилом required.
+const Tag = "validate"
+
+// Validator проверяет входные DTO до вызова сервиса
+type Validator struct {
+	urlSchemes []string
+	urlHosts   []string
+}
+
+func New(cfg config.ValidationConfig) *Validator {
+	lower := func(values []string) []string {
+		out := make([]string, len(values))
+		for i, v := range values {
+			out[i] = strings.ToLower(v)
+		}
+		return out
+	}
+	return &Validator{urlSchemes: lower(cfg.URLSchemes), urlHosts: lower(cfg.URLHosts)}
+}
+
+// Validate проверяет все поля dto (указатель на структуру или структура) и возвращает
+// *apperrors.Error с кодом validation_failed и перечнем всех ошибок полей либо nil.
+// extra — ошибки проверок, которые нельзя выразить тегом (например, отсутствие файла в форме).
+func (v *Validator) Validate(dto any, extra ...apperrors.FieldError) error {
+	value := reflect.Indirect(reflect.ValueOf(dto))
+	if value.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: ожидается структура, получен %T", dto))
+	}
+
+	var fields []apperrors.FieldError
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		rules, ok := field.Tag.Lookup(Tag)
+		if !ok || rules == "" || !field.IsExported() {
+			continue
+		}
+
+		str, present := stringValue(value.Field(i), field)
+		for _, rule := range strings.Split(rules, ",") {
+			if msg := v.check(rule, str, present); msg != "" {
+				fields = append(fields, apperrors.FieldError{Field: fieldName(field), Message: msg})
+				// Одного сообщения на поле достаточно: следующие правила обычно повторяют первое
+				break
+			}
+		}
+	}
+
+	fields = append(fields, extra...)
+	if len(fields) > 0 {
+		return apperrors.Validation("Данные не прошли проверку", fields...)
+	}
+	return nil
+}
+
+// check возвращает текст ошибки или пустую строку, если значение удовлетворяет правилу
+func (v *Validator) check(rule, value string, present bool) string {
+	name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+	if name == "required" {
+		if !present || strings.TrimSpace(value) == "" {
+			return "Обязательное поле"
+		}
+		return ""
+	}
+	if !present || value == "" {
+		return ""
+	}
+
+	switch name {
+	case "min":
+		if n := mustAtoi(rule, arg); utf8.RuneCountInString(value) < n {
+			return fmt.Sprintf("Не короче %d символов", n)
+		}
+	case "max":
+		if n := mustAtoi(rule, arg); utf8.RuneCountInString(value) > n {
+			return fmt.Sprintf("Не длиннее %d символов", n)
+		}
+	case "oneof":
+		allowed := strings.Fields(arg)
+		if !slices.Contains(allowed, value) {
+			return "Допустимые значения: " + strings.Join(allowed, ", ")
+		}
+	case "url":
+		return v.checkURL(value)
+	default:
+		panic(fmt.Sprintf("validation: неизвестное правило %q", rule))
+	}
+	return ""
+}
+
+func (v *Validator) checkURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || u.User != nil {
+		return "Некорректная ссылка"
+	}
+	if !slices.Contains(v.urlSchemes, strings.ToLower(u.Scheme)) {
+		return "Допустимые схемы ссылки: " + strings.Join(v.urlSchemes, ", ")
+	}
+	if len(v.urlHosts) == 0 {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range v.urlHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return ""
+		}
+	}
+	return "Ссылка на этот сайт не разрешена"
+}
+
+// stringValue возвращает значение поля string или *string; present = false для nil-указателя
+func stringValue(value reflect.Value, field reflect.StructField) (string, bool) {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return "", false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.String {
+		panic(fmt.Sprintf("validation: поле %s должно быть string или *string", field.Name))
+	}
+	return value.String(), true
+}
+
+// fieldName — имя поля в запросе: из тега json, а для форм — из тега form
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(key), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func mustAtoi(rule, arg string) int {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		panic(fmt.Sprintf("validation: некорректное правило %q", rule))
+	}
+	return n
+}
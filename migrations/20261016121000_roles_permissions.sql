@@ -0,0 +1,56 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS roles (
+                                     name VARCHAR(50) PRIMARY KEY,
+                                     description TEXT NOT NULL DEFAULT '',
+                                     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+                                           name VARCHAR(100) PRIMARY KEY,
+                                           description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+                                                role VARCHAR(50) NOT NULL REFERENCES roles (name) ON DELETE CASCADE ON UPDATE CASCADE,
+                                                permission VARCHAR(100) NOT NULL REFERENCES permissions (name) ON DELETE CASCADE,
+                                                PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Администратор'),
+    ('editor', 'Редактор'),
+    ('user', 'Пользователь (только чтение)')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('news:write', 'Создание и изменение новостей'),
+    ('news:delete', 'Удаление новостей'),
+    ('documents:write', 'Загрузка документов'),
+    ('documents:delete', 'Удаление документов'),
+    ('applications:write', 'Создание и изменение приложений'),
+    ('applications:delete', 'Удаление приложений'),
+    ('users:manage', 'Управление пользователями'),
+    ('roles:manage', 'Управление ролями и правами')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name FROM permissions
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('editor', 'news:write'),
+    ('editor', 'news:delete'),
+    ('editor', 'documents:write'),
+    ('editor', 'applications:write')
+ON CONFLICT DO NOTHING;
+
+UPDATE users SET role = 'user' WHERE role IS NULL OR role NOT IN (SELECT name FROM roles);
+ALTER TABLE users ALTER COLUMN role SET NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles (name) ON UPDATE CASCADE;
+
+-- +goose Down
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+ALTER TABLE users ALTER COLUMN role DROP NOT NULL;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
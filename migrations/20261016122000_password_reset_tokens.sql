@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+                                                     id SERIAL PRIMARY KEY,
+                                                     user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                                                     token_hash CHAR(64) UNIQUE NOT NULL,
+                                                     expires_at TIMESTAMP NOT NULL,
+                                                     used_at TIMESTAMP,
+                                                     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS password_reset_tokens_user_id_idx ON password_reset_tokens (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS password_reset_tokens;
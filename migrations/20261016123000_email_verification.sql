@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+-- Учётные записи, созданные до появления подтверждения, считаем подтверждёнными
+UPDATE users SET email_verified = TRUE;
+
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+                                                         id SERIAL PRIMARY KEY,
+                                                         user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                                                         token_hash CHAR(64) UNIQUE NOT NULL,
+                                                         expires_at TIMESTAMP NOT NULL,
+                                                         used_at TIMESTAMP,
+                                                         created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS email_verification_tokens_user_id_idx ON email_verification_tokens (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS email_verification_tokens;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS sessions (
+                                        id UUID PRIMARY KEY,
+                                        user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                                        token_hash CHAR(64) NOT NULL,
+                                        user_agent TEXT NOT NULL DEFAULT '',
+                                        ip VARCHAR(64) NOT NULL DEFAULT '',
+                                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                        last_used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                        expires_at TIMESTAMP NOT NULL,
+                                        revoked_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+
+ALTER TABLE users DROP COLUMN IF EXISTS refresh_token;
+
+-- +goose Down
+ALTER TABLE users ADD COLUMN IF NOT EXISTS refresh_token VARCHAR(500);
+DROP TABLE IF EXISTS sessions;
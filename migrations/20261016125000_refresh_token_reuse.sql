@@ -0,0 +1,12 @@
+-- +goose Up
+-- Израсходованные refresh-токены: повторное предъявление любого из них отзывает всю сессию
+CREATE TABLE IF NOT EXISTS consumed_refresh_tokens (
+                                                       jti UUID PRIMARY KEY,
+                                                       session_id UUID NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+                                                       consumed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                                       expires_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS consumed_refresh_tokens_expires_at_idx ON consumed_refresh_tokens (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS consumed_refresh_tokens;
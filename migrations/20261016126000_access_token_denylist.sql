@@ -0,0 +1,13 @@
+-- +goose Up
+-- jti последнего access-токена сессии: при отзыве сессии он попадает в denylist
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS access_jti UUID;
+
+CREATE TABLE IF NOT EXISTS revoked_access_tokens (
+                                                     jti UUID PRIMARY KEY,
+                                                     expires_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS revoked_access_tokens_expires_at_idx ON revoked_access_tokens (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS revoked_access_tokens;
+ALTER TABLE sessions DROP COLUMN IF EXISTS access_jti;
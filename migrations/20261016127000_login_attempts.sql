@@ -0,0 +1,12 @@
+-- +goose Up
+-- Счётчики неудачных попыток входа; key — "account:<email>" или "ip:<адрес>"
+CREATE TABLE IF NOT EXISTS login_attempts (
+                                              key VARCHAR(320) PRIMARY KEY,
+                                              failures INTEGER NOT NULL DEFAULT 0,
+                                              last_failure_at TIMESTAMPTZ NOT NULL,
+                                              locked_until TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS login_attempts_last_failure_at_idx ON login_attempts (last_failure_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_attempts;
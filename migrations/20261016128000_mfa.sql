@@ -0,0 +1,21 @@
+-- +goose Up
+-- totp_secret заполняется при начале подключения, mfa_enabled — после подтверждения кодом.
+-- totp_last_step — последний принятый временной шаг: повторно тот же код не принимается.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+                                                  id SERIAL PRIMARY KEY,
+                                                  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                                                  code_hash CHAR(64) NOT NULL,
+                                                  used_at TIMESTAMP,
+                                                  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS mfa_recovery_codes_user_id_idx ON mfa_recovery_codes (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS mfa_recovery_codes;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_step;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
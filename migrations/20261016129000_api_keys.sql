@@ -0,0 +1,32 @@
+-- +goose Up
+-- В key_hash хранится SHA-256 ключа; сам ключ показывается администратору один раз при выпуске.
+CREATE TABLE IF NOT EXISTS api_keys (
+                                        id SERIAL PRIMARY KEY,
+                                        name VARCHAR(255) NOT NULL,
+                                        prefix VARCHAR(16) NOT NULL,
+                                        key_hash CHAR(64) NOT NULL UNIQUE,
+                                        created_by VARCHAR(255) NOT NULL DEFAULT '',
+                                        created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                        expires_at TIMESTAMPTZ,
+                                        last_used_at TIMESTAMPTZ,
+                                        revoked_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS api_key_scopes (
+                                              api_key_id INTEGER NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+                                              permission VARCHAR(100) NOT NULL REFERENCES permissions (name) ON DELETE CASCADE,
+                                              PRIMARY KEY (api_key_id, permission)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('api_keys:manage', 'Выпуск и отзыв API-ключей')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'api_keys:manage')
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS api_key_scopes;
+DROP TABLE IF EXISTS api_keys;
+DELETE FROM permissions WHERE name = 'api_keys:manage';
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Учётные записи внешних провайдеров (OIDC), привязанные к пользователям; пара issuer + subject уникальна у провайдера
+CREATE TABLE IF NOT EXISTS user_identities (
+                                               issuer TEXT NOT NULL,
+                                               subject TEXT NOT NULL,
+                                               user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                                               email VARCHAR(255) NOT NULL DEFAULT '',
+                                               created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                               last_login_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                               PRIMARY KEY (issuer, subject)
+);
+CREATE INDEX IF NOT EXISTS user_identities_user_id_idx ON user_identities (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_identities;
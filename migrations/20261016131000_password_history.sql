@@ -0,0 +1,12 @@
+-- +goose Up
+-- Прежние bcrypt-хеши паролей пользователя; текущий пароль хранится в users.password
+CREATE TABLE IF NOT EXISTS password_history (
+                                                id SERIAL PRIMARY KEY,
+                                                user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                                                password_hash VARCHAR(255) NOT NULL,
+                                                created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS password_history_user_id_idx ON password_history (user_id, id DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS password_history;
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS full_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS position VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS org_unit VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone VARCHAR(32) NOT NULL DEFAULT '';
+-- avatar_key — ключ изображения в файловом хранилище; пустая строка — аватар не загружен
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key VARCHAR(255) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_key;
+ALTER TABLE users DROP COLUMN IF EXISTS phone;
+ALTER TABLE users DROP COLUMN IF EXISTS org_unit;
+ALTER TABLE users DROP COLUMN IF EXISTS position;
+ALTER TABLE users DROP COLUMN IF EXISTS full_name;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- version увеличивается при каждом изменении записи и отдаётся клиенту как ETag
+ALTER TABLE news ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE applications DROP COLUMN IF EXISTS version;
+ALTER TABLE news DROP COLUMN IF EXISTS version;
@@ -0,0 +1,41 @@
+-- +goose Up
+-- Уже созданные новости опубликованы; publish_at для них — время создания.
+-- Новые новости создаются черновиками.
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published'
+        CHECK (status IN ('draft', 'in_review', 'scheduled', 'published', 'archived')),
+    ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS unpublish_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS author_id INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS reviewer_id INTEGER REFERENCES users (id) ON DELETE SET NULL;
+
+UPDATE news SET publish_at = created_at WHERE publish_at IS NULL;
+ALTER TABLE news ALTER COLUMN status SET DEFAULT 'draft';
+
+-- Планировщик ищет новости, которые пора опубликовать или снять с публикации
+CREATE INDEX IF NOT EXISTS news_scheduled_idx ON news (publish_at) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS news_unpublish_idx ON news (unpublish_at) WHERE status = 'published';
+
+INSERT INTO permissions (name, description) VALUES
+    ('news:publish', 'Рецензирование, публикация и снятие новостей с публикации')
+ON CONFLICT (name) DO NOTHING;
+
+-- Публикует редактор: он уже ведёт и удаляет новости, а отдельной роли рецензента нет.
+-- Без этого права у редактора публиковать смог бы только администратор.
+-- Если понадобится разделить авторов и рецензентов, право снимается с editor
+-- и выдаётся новой роли через /roles без изменения кода.
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'news:publish'),
+    ('editor', 'news:publish')
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'news:publish';
+DROP INDEX IF EXISTS news_unpublish_idx;
+DROP INDEX IF EXISTS news_scheduled_idx;
+ALTER TABLE news
+    DROP COLUMN IF EXISTS reviewer_id,
+    DROP COLUMN IF EXISTS author_id,
+    DROP COLUMN IF EXISTS unpublish_at,
+    DROP COLUMN IF EXISTS publish_at,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- slug — адрес новости на публичном сайте; для уже созданных новостей — news-<id>
+ALTER TABLE news ADD COLUMN IF NOT EXISTS slug VARCHAR(120);
+UPDATE news SET slug = 'news-' || id WHERE slug IS NULL;
+ALTER TABLE news ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS news_slug_key ON news (slug);
+
+-- Публичный список сортируется по времени публикации
+CREATE INDEX IF NOT EXISTS news_published_idx ON news (publish_at DESC, id DESC) WHERE status = 'published';
+
+-- +goose Down
+DROP INDEX IF EXISTS news_published_idx;
+DROP INDEX IF EXISTS news_slug_key;
+ALTER TABLE news DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Удалённая новость не оставляет updated_at, поэтому время удаления хранится отдельно:
+-- без него Last-Modified публичного списка не сдвигается и клиенты получают устаревший 304.
+-- В таблице ровно одна строка.
+CREATE TABLE IF NOT EXISTS news_changes (
+                                            id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+                                            last_deleted_at TIMESTAMPTZ
+);
+
+INSERT INTO news_changes (id) VALUES (TRUE) ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS news_changes;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Ключам больше нельзя выдавать api_keys:manage: снимаем это право с уже выпущенных
+DELETE FROM api_key_scopes WHERE permission = 'api_keys:manage';
+
+-- +goose Down
+-- Снятые права не восстанавливаются
+SELECT 1;
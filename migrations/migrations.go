@@ -0,0 +1,131 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"go.uber.org/zap"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// Runner применяет встроенные в бинарник миграции.
+// Каждая операция выполняется под advisory-блокировкой Postgres,
+// поэтому одновременно стартующие реплики не мешают друг другу.
+type Runner struct {
+	provider *goose.Provider
+	logger   *zap.Logger
+}
+
+func NewRunner(pool *pgxpool.Pool, logger *zap.Logger) (*Runner, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания блокировки миграций: %w", err)
+	}
+
+	provider, err := goose.NewProvider(
+		goose.DialectPostgres,
+		stdlib.OpenDBFromPool(pool),
+		FS,
+		goose.WithSessionLocker(locker),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации миграций: %w", err)
+	}
+
+	return &Runner{provider: provider, logger: logger}, nil
+}
+
+// Run выполняет команду up, down, status или redo
+func (m *Runner) Run(ctx context.Context, command string, out io.Writer) error {
+	switch command {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx)
+	case "redo":
+		return m.Redo(ctx)
+	case "status":
+		return m.Status(ctx, out)
+	default:
+		return fmt.Errorf("неизвестная команда миграций: %s", command)
+	}
+}
+
+func (m *Runner) Up(ctx context.Context) error {
+	results, err := m.provider.Up(ctx)
+	for _, res := range results {
+		m.logResult(res)
+	}
+	return err
+}
+
+func (m *Runner) Down(ctx context.Context) error {
+	res, err := m.provider.Down(ctx)
+	if errors.Is(err, goose.ErrNoNextVersion) {
+		m.logger.Info("Нет применённых миграций для отката")
+		return nil
+	}
+	m.logResult(res)
+	return err
+}
+
+func (m *Runner) Redo(ctx context.Context) error {
+	res, err := m.provider.Down(ctx)
+	m.logResult(res)
+	if err != nil {
+		return err
+	}
+
+	res, err = m.provider.UpByOne(ctx)
+	m.logResult(res)
+	return err
+}
+
+func (m *Runner) Status(ctx context.Context, out io.Writer) error {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Применена\tМиграция")
+	for _, st := range statuses {
+		appliedAt := "ожидает"
+		if st.State == goose.StateApplied {
+			appliedAt = st.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", appliedAt, st.Source.Path)
+	}
+	return tw.Flush()
+}
+
+func (m *Runner) Close() error {
+	return m.provider.Close()
+}
+
+func (m *Runner) logResult(res *goose.MigrationResult) {
+	if res == nil || res.Source == nil {
+		return
+	}
+	if res.Error != nil {
+		m.logger.Error("Ошибка миграции",
+			zap.String("migration", res.Source.Path),
+			zap.String("direction", res.Direction),
+			zap.Error(res.Error))
+		return
+	}
+	m.logger.Info("Миграция выполнена",
+		zap.String("migration", res.Source.Path),
+		zap.String("direction", res.Direction),
+		zap.Duration("duration", res.Duration))
+}